@@ -0,0 +1,593 @@
+package app_backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/app_db/schema_version"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// archive layout: magic | version (1 byte) | salt (16 bytes) | iterations (uint32) | encrypted zip
+
+var MAGIC = []byte("G45WBAK")
+
+const (
+	VERSION         = 1
+	SALT_SIZE       = 16
+	KEY_ITERATIONS  = 100000
+	MAX_ITERATIONS  = 10000000 // the header is read before the password is checked, don't let it hang the app
+	FILE_EXTENSION  = ".g45wbak"
+	MANIFEST_NAME   = "manifest.json"
+	WALLETS_ZIP_DIR = "wallets"
+)
+
+var headerSize = len(MAGIC) + 1 + SALT_SIZE + 4
+
+var ErrInvalidArchive = fmt.Errorf("invalid backup archive")
+var ErrInvalidPassword = fmt.Errorf("invalid password")
+var ErrWrongNetwork = fmt.Errorf("backup was created for another network")
+
+type Manifest struct {
+	Version              int                       `json:"version"`
+	Timestamp            int64                     `json:"timestamp"`
+	AppVersion           string                    `json:"app_version"`
+	Testnet              bool                      `json:"testnet"`
+	Wallets              []app_db.WalletInfo       `json:"wallets"`
+	WalletGroups         []app_db.WalletGroup      `json:"wallet_groups"`
+	Nodes                []app_db.NodeConnection   `json:"nodes"`
+	IPFSGateways         []app_db.IPFSGateway      `json:"ipfs_gateways"`
+	AppSchemaVersions    map[string]int            `json:"app_schema_versions"`
+	WalletSchemaVersions map[string]map[string]int `json:"wallet_schema_versions"`
+}
+
+type ImportResult struct {
+	Wallets        int
+	SkippedWallets int
+	Nodes          int
+	IPFSGateways   int
+}
+
+func deriveKey(password string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(password), salt, iterations, 32, sha256.New)
+}
+
+func readDataSchemaVersions(dbPath string) (map[string]int, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return schema_version.GetVersions(db)
+}
+
+// the opened wallet can save its file at any time, hold its lock so we don't read a partial write
+func readWalletFile(addr string, filePath string) ([]byte, error) {
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil && wallet.Info.Addr == addr {
+		err := wallet.Memory.Save_Wallet()
+		if err != nil {
+			return nil, err
+		}
+
+		wallet.Memory.Lock()
+		defer wallet.Memory.Unlock()
+	}
+
+	return os.ReadFile(filePath)
+}
+
+// VACUUM INTO writes a consistent copy of the database even if the wallet is opened and writing to it
+func readDataDB(filePath string) ([]byte, error) {
+	tmpDir := filepath.Join(settings.CacheDir, "backup")
+	err := os.MkdirAll(tmpDir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := filepath.Join(tmpDir, fmt.Sprintf("data_%d.db", time.Now().UnixNano()))
+	defer os.Remove(tmpPath)
+
+	db, err := sql.Open("sqlite", filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("VACUUM INTO ?;", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(tmpPath)
+}
+
+func addWalletFiles(zipWriter *zip.Writer, addr string) error {
+	folderPath := filepath.Join(settings.WalletsDir, addr)
+	return filepath.WalkDir(folderPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// the backup file is recreated on the next wallet save
+		// and the sqlite journal files are already part of the data.db snapshot
+		name := d.Name()
+		if d.IsDir() || name == "wallet.db.bak" ||
+			strings.HasSuffix(name, "-wal") || strings.HasSuffix(name, "-shm") || strings.HasSuffix(name, "-journal") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(folderPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch relPath {
+		case "wallet.db":
+			data, err = readWalletFile(addr, filePath)
+		case "data.db":
+			data, err = readDataDB(filePath)
+		default:
+			data, err = os.ReadFile(filePath)
+		}
+		if err != nil {
+			return err
+		}
+
+		zipName := path.Join(WALLETS_ZIP_DIR, addr, filepath.ToSlash(relPath))
+		w, err := zipWriter.Create(zipName)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+		return err
+	})
+}
+
+// Export creates a password encrypted archive with the selected wallets (wallet file, data.db and settings),
+// the node connections and the ipfs gateways.
+func Export(addrs []string, password string) ([]byte, error) {
+	manifest := Manifest{
+		Version:              VERSION,
+		Timestamp:            time.Now().Unix(),
+		AppVersion:           settings.Version,
+		Testnet:              settings.App.Testnet,
+		WalletSchemaVersions: make(map[string]map[string]int),
+	}
+
+	var err error
+	manifest.AppSchemaVersions, err = schema_version.GetVersions(app_db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Nodes, err = app_db.GetNodeConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.IPFSGateways, err = app_db.GetIPFSGateways(app_db.GetIPFSGatewaysParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := app_db.GetWalletGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	usedGroups := make(map[int64]bool)
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+
+	for _, addr := range addrs {
+		walletInfo, err := app_db.GetWalletInfo(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		dbPath := filepath.Join(settings.WalletsDir, addr, "data.db")
+		versions, err := readDataSchemaVersions(dbPath)
+		if err != nil {
+			return nil, err
+		}
+
+		err = addWalletFiles(zipWriter, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// the password encrypted with the viewing PIN never leaves the device
+		walletInfo.ReadOnly = false
+		walletInfo.ViewingData = ""
+
+		if walletInfo.GroupId.Valid {
+			usedGroups[walletInfo.GroupId.Int64] = true
+		}
+
+		manifest.Wallets = append(manifest.Wallets, walletInfo)
+		manifest.WalletSchemaVersions[addr] = versions
+	}
+
+	// the group ids are local to the device, the names are used to match them on import
+	for _, group := range groups {
+		if usedGroups[group.ID] {
+			manifest.WalletGroups = append(manifest.WalletGroups, group)
+		}
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := zipWriter.Create(MANIFEST_NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, SALT_SIZE)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	key := deriveKey(password, salt, KEY_ITERATIONS)
+	encrypted, err := walletapi.EncryptWithKey(key, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, headerSize+len(encrypted))
+	data = append(data, MAGIC...)
+	data = append(data, VERSION)
+	data = append(data, salt...)
+	data = binary.BigEndian.AppendUint32(data, KEY_ITERATIONS)
+	data = append(data, encrypted...)
+	return data, nil
+}
+
+func decrypt(data []byte, password string) (*zip.Reader, error) {
+	if len(data) < headerSize || !bytes.Equal(data[:len(MAGIC)], MAGIC) {
+		return nil, ErrInvalidArchive
+	}
+
+	version := data[len(MAGIC)]
+	if version > VERSION {
+		return nil, fmt.Errorf("backup version %d is not supported", version)
+	}
+
+	offset := len(MAGIC) + 1
+	salt := data[offset : offset+SALT_SIZE]
+	offset += SALT_SIZE
+	iterations := binary.BigEndian.Uint32(data[offset : offset+4])
+	offset += 4
+
+	if iterations == 0 || iterations > MAX_ITERATIONS {
+		return nil, ErrInvalidArchive
+	}
+
+	key := deriveKey(password, salt, int(iterations))
+	zipData, err := walletapi.DecryptWithKey(key, data[offset:])
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	return zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// ReadManifest decrypts the archive and returns its manifest without importing anything.
+func ReadManifest(data []byte, password string) (*Manifest, error) {
+	zipReader, err := decrypt(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return readManifest(zipReader)
+}
+
+func readManifest(zipReader *zip.Reader) (*Manifest, error) {
+	for _, file := range zipReader.File {
+		if file.Name == MANIFEST_NAME {
+			data, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			var manifest Manifest
+			err = json.Unmarshal(data, &manifest)
+			if err != nil {
+				return nil, err
+			}
+
+			return &manifest, nil
+		}
+	}
+
+	return nil, ErrInvalidArchive
+}
+
+// a backup made by a newer version of the app can have tables we don't know how to read
+func checkSchemaVersions(versions map[string]int, supported map[string]int) error {
+	for schema, version := range versions {
+		if version > supported[schema] {
+			return fmt.Errorf("schema [%s] version %d is newer than supported version %d, update the app first", schema, version, supported[schema])
+		}
+	}
+
+	return nil
+}
+
+func importWalletFiles(zipReader *zip.Reader, addr string) error {
+	prefix := path.Join(WALLETS_ZIP_DIR, addr) + "/"
+	folderPath := filepath.Join(settings.WalletsDir, addr)
+
+	for _, file := range zipReader.File {
+		if !strings.HasPrefix(file.Name, prefix) {
+			continue
+		}
+
+		relPath := path.Clean(strings.TrimPrefix(file.Name, prefix))
+		if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") || path.IsAbs(relPath) {
+			return ErrInvalidArchive
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(folderPath, filepath.FromSlash(relPath))
+		err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filePath, data, 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importWalletGroups returns the local group id of each group id of the archive.
+// A group with the same name is reused, otherwise the group is created.
+func importWalletGroups(groups []app_db.WalletGroup) (map[int64]int64, error) {
+	localGroups, err := app_db.GetWalletGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groupIds := make(map[string]int64)
+	for _, group := range localGroups {
+		groupIds[group.Name] = group.ID
+	}
+
+	groupIdMap := make(map[int64]int64)
+	for _, group := range groups {
+		id, ok := groupIds[strings.TrimSpace(group.Name)]
+		if !ok {
+			id, err = app_db.InsertWalletGroup(app_db.WalletGroup{Name: group.Name, Collapsed: group.Collapsed})
+			if err != nil {
+				return nil, err
+			}
+
+			groupIds[strings.TrimSpace(group.Name)] = id
+		}
+
+		groupIdMap[group.ID] = id
+	}
+
+	return groupIdMap, nil
+}
+
+func walletExists(addr string) (bool, error) {
+	_, err := app_db.GetWalletInfo(addr)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Import merges the archive into the current app data.
+// Wallets that already exist are skipped and nodes/gateways are added only if the endpoint is unknown.
+// Older data.db schemas are migrated when the wallet is opened.
+func Import(data []byte, password string) (result ImportResult, err error) {
+	zipReader, err := decrypt(data, password)
+	if err != nil {
+		return
+	}
+
+	manifest, err := readManifest(zipReader)
+	if err != nil {
+		return
+	}
+
+	if manifest.Testnet != settings.App.Testnet {
+		err = ErrWrongNetwork
+		return
+	}
+
+	appVersions, err := schema_version.GetVersions(app_db.DB)
+	if err != nil {
+		return
+	}
+
+	err = checkSchemaVersions(manifest.AppSchemaVersions, appVersions)
+	if err != nil {
+		return
+	}
+
+	dataVersions, err := wallet_manager.DataSchemaVersions()
+	if err != nil {
+		return
+	}
+
+	for _, walletInfo := range manifest.Wallets {
+		// the address is used as the wallet folder name
+		_, err = rpc.NewAddress(walletInfo.Addr)
+		if err != nil {
+			return
+		}
+
+		err = checkSchemaVersions(manifest.WalletSchemaVersions[walletInfo.Addr], dataVersions)
+		if err != nil {
+			return
+		}
+	}
+
+	archiveAddrs := make(map[string]bool)
+	for _, walletInfo := range manifest.Wallets {
+		archiveAddrs[walletInfo.Addr] = true
+	}
+
+	var groupIdMap map[int64]int64
+	for _, walletInfo := range manifest.Wallets {
+		var exists bool
+		exists, err = walletExists(walletInfo.Addr)
+		if err != nil {
+			return
+		}
+
+		if exists {
+			result.SkippedWallets++
+			continue
+		}
+
+		if groupIdMap == nil {
+			groupIdMap, err = importWalletGroups(manifest.WalletGroups)
+			if err != nil {
+				return
+			}
+		}
+
+		// the read-only mode needs the viewing data that is not exported
+		walletInfo.ReadOnly = false
+		walletInfo.ViewingData = ""
+
+		groupId, ok := groupIdMap[walletInfo.GroupId.Int64]
+		walletInfo.GroupId = sql.NullInt64{Int64: groupId, Valid: walletInfo.GroupId.Valid && ok}
+
+		// an account without its master wallet on this device is imported as a regular wallet
+		if walletInfo.ParentAddr != "" && !archiveAddrs[walletInfo.ParentAddr] {
+			exists, err = walletExists(walletInfo.ParentAddr)
+			if err != nil {
+				return
+			}
+
+			if !exists {
+				walletInfo.ParentAddr = ""
+				walletInfo.DerivationIndex = 0
+			}
+		}
+
+		// don't overwrite the files of a folder we didn't create
+		folderPath := filepath.Join(settings.WalletsDir, walletInfo.Addr)
+		_, err = os.Stat(folderPath)
+		if err == nil {
+			err = fmt.Errorf("the wallet folder [%s] already exists", walletInfo.Addr)
+			return
+		}
+
+		err = importWalletFiles(zipReader, walletInfo.Addr)
+		if err == nil {
+			err = app_db.InsertWalletInfo(walletInfo)
+		}
+
+		if err != nil {
+			os.RemoveAll(folderPath)
+			return
+		}
+
+		result.Wallets++
+	}
+
+	for _, node := range manifest.Nodes {
+		var currentNode app_db.NodeConnection
+		currentNode, err = app_db.GetNodeConnectionByEndpoint(node.Endpoint)
+		if err != nil {
+			return
+		}
+
+		if currentNode.Endpoint != "" {
+			continue
+		}
+
+		err = app_db.InsertNodeConnection(node)
+		if err != nil {
+			return
+		}
+
+		result.Nodes++
+	}
+
+	gateways, err := app_db.GetIPFSGateways(app_db.GetIPFSGatewaysParams{})
+	if err != nil {
+		return
+	}
+
+	gatewayEndpoints := make(map[string]bool)
+	for _, gateway := range gateways {
+		gatewayEndpoints[gateway.Endpoint] = true
+	}
+
+	for _, gateway := range manifest.IPFSGateways {
+		if gatewayEndpoints[gateway.Endpoint] {
+			continue
+		}
+
+		err = app_db.InsertIPFSGateway(gateway)
+		if err != nil {
+			return
+		}
+
+		gatewayEndpoints[gateway.Endpoint] = true
+		result.IPFSGateways++
+	}
+
+	return
+}
@@ -46,6 +46,11 @@ func Load() error {
 		return err
 	}
 
+	err = initTableWalletGroups()
+	if err != nil {
+		return err
+	}
+
 	err = initTableWallets()
 	if err != nil {
 		return err
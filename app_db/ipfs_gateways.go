@@ -12,6 +12,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/g45t345rt/g45w/app_db/order_column"
 	"github.com/g45t345rt/g45w/app_db/schema_version"
+	"github.com/g45t345rt/g45w/proxy_manager"
 )
 
 type IPFSGateway struct {
@@ -298,7 +299,7 @@ func DelIPFSGateway(id int64) error {
 func (i IPFSGateway) Fetch(cId string, timeout time.Duration) (*http.Response, error) {
 	endpoint := strings.Replace(i.Endpoint, "{cid}", cId, -1)
 
-	client := new(http.Client)
+	client := proxy_manager.NewHttpClient()
 
 	req, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
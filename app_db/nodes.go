@@ -17,6 +17,47 @@ type NodeConnection struct {
 	Name        string
 	Integrated  bool
 	OrderNumber int
+	Pool        bool
+
+	// results of the last benchmark
+	Status      sql.NullString
+	ConnectTime sql.NullInt64 // ms
+	Latency     sql.NullInt64 // ms
+	Height      sql.NullInt64
+	BestHeight  sql.NullInt64
+	Version     sql.NullString
+	CheckedAt   sql.NullInt64
+}
+
+var (
+	NODE_STATUS_OK            = "ok"
+	NODE_STATUS_STALE         = "stale"
+	NODE_STATUS_AHEAD         = "ahead"
+	NODE_STATUS_WRONG_NETWORK = "wrong_network"
+	NODE_STATUS_UNREACHABLE   = "unreachable"
+)
+
+// HealthScore returns a score based on the last benchmark (higher is better).
+// Nodes that were never checked are placed before flagged nodes.
+func (n NodeConnection) HealthScore() int64 {
+	if !n.Status.Valid {
+		return 0
+	}
+
+	if n.Status.String != NODE_STATUS_OK {
+		return -1
+	}
+
+	score := int64(10000) - n.Latency.Int64 - n.ConnectTime.Int64
+	if n.BestHeight.Int64 > n.Height.Int64 {
+		score -= (n.BestHeight.Int64 - n.Height.Int64) * 100
+	}
+
+	if score < 1 {
+		score = 1
+	}
+
+	return score
 }
 
 func GetIntegratedNode() NodeConnection {
@@ -64,6 +105,8 @@ func initTableNodes() error {
 		return err
 	}
 
+	reOrder := false
+
 	if version == 0 {
 		_, err := DB.Exec(`
 			CREATE TABLE IF NOT EXISTS nodes (
@@ -83,18 +126,58 @@ func initTableNodes() error {
 			return err
 		}
 
-		err = ReOrderNodes(DB)
+		reOrder = true
+		version = 1
+		err = schema_version.StoreVersion(DB, "nodes", version)
 		if err != nil {
 			return err
 		}
+	}
 
-		version = 1
+	if version == 1 {
+		_, err := DB.Exec(`
+			ALTER TABLE nodes ADD COLUMN pool BOOL NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 2
 		err = schema_version.StoreVersion(DB, "nodes", version)
 		if err != nil {
 			return err
 		}
 	}
 
+	if version == 2 {
+		_, err := DB.Exec(`
+			ALTER TABLE nodes ADD COLUMN status VARCHAR;
+			ALTER TABLE nodes ADD COLUMN connect_time INTEGER;
+			ALTER TABLE nodes ADD COLUMN latency INTEGER;
+			ALTER TABLE nodes ADD COLUMN height BIGINT;
+			ALTER TABLE nodes ADD COLUMN best_height BIGINT;
+			ALTER TABLE nodes ADD COLUMN version VARCHAR;
+			ALTER TABLE nodes ADD COLUMN checked_at BIGINT;
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 3
+		err = schema_version.StoreVersion(DB, "nodes", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	// reorder after all migrations or the scan would miss columns
+	if reOrder {
+		err = ReOrderNodes(DB)
+		if err != nil {
+			return err
+		}
+	}
+
 	return err
 }
 
@@ -141,6 +224,14 @@ func GetNodeConnections() ([]NodeConnection, error) {
 			&node.Endpoint,
 			&node.Name,
 			&node.OrderNumber,
+			&node.Pool,
+			&node.Status,
+			&node.ConnectTime,
+			&node.Latency,
+			&node.Height,
+			&node.BestHeight,
+			&node.Version,
+			&node.CheckedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -163,6 +254,14 @@ func GetNodeConnection(id int64) (node NodeConnection, err error) {
 		&node.Endpoint,
 		&node.Name,
 		&node.OrderNumber,
+		&node.Pool,
+		&node.Status,
+		&node.ConnectTime,
+		&node.Latency,
+		&node.Height,
+		&node.BestHeight,
+		&node.Version,
+		&node.CheckedAt,
 	)
 	if err != nil {
 		return
@@ -181,6 +280,14 @@ func GetNodeConnectionByEndpoint(endpoint string) (node NodeConnection, err erro
 		&node.Endpoint,
 		&node.Name,
 		&node.OrderNumber,
+		&node.Pool,
+		&node.Status,
+		&node.ConnectTime,
+		&node.Latency,
+		&node.Height,
+		&node.BestHeight,
+		&node.Version,
+		&node.CheckedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -207,9 +314,9 @@ func InsertNodeConnection(node NodeConnection) error {
 	}
 
 	_, err = tx.Exec(`
-		INSERT INTO nodes (endpoint,name,order_number)
-		VALUES (?,?,?);
-	`, node.Endpoint, node.Name, node.OrderNumber)
+		INSERT INTO nodes (endpoint,name,order_number,pool)
+		VALUES (?,?,?,?);
+	`, node.Endpoint, node.Name, node.OrderNumber, node.Pool)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -237,9 +344,9 @@ func UpdateNodeConnection(node NodeConnection) error {
 
 	_, err = tx.Exec(`
 		UPDATE nodes
-		SET name = ?, endpoint = ?, order_number = ?
+		SET name = ?, endpoint = ?, order_number = ?, pool = ?
 		WHERE id = ?;
-	`, node.Name, node.Endpoint, node.OrderNumber, node.ID)
+	`, node.Name, node.Endpoint, node.OrderNumber, node.Pool, node.ID)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -248,6 +355,15 @@ func UpdateNodeConnection(node NodeConnection) error {
 	return tx.Commit()
 }
 
+func UpdateNodeBenchmark(node NodeConnection) error {
+	_, err := DB.Exec(`
+		UPDATE nodes
+		SET status = ?, connect_time = ?, latency = ?, height = ?, best_height = ?, version = ?, checked_at = ?
+		WHERE id = ?;
+	`, node.Status, node.ConnectTime, node.Latency, node.Height, node.BestHeight, node.Version, node.CheckedAt, node.ID)
+	return err
+}
+
 func DelNodeConnection(id int64) error {
 	tx, err := DB.Begin()
 	if err != nil {
@@ -35,3 +35,27 @@ func StoreVersion(db *sql.DB, schema string, version int) error {
 	`, schema, version)
 	return err
 }
+
+func GetVersions(db *sql.DB) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT schema, version FROM schema_version;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int)
+	for rows.Next() {
+		var schema string
+		var version int
+		err = rows.Scan(&schema, &version)
+		if err != nil {
+			return nil, err
+		}
+
+		versions[schema] = version
+	}
+
+	return versions, rows.Err()
+}
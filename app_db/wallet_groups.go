@@ -0,0 +1,249 @@
+package app_db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/g45t345rt/g45w/app_db/order_column"
+	"github.com/g45t345rt/g45w/app_db/schema_version"
+)
+
+type WalletGroup struct {
+	ID          int64
+	Name        string
+	OrderNumber int
+	Collapsed   bool
+}
+
+var walletGroupOrderer = order_column.Orderer{
+	TableName:  "wallet_groups",
+	ColumnName: "order_number",
+}
+
+// wallets are ordered inside their group
+func walletOrdererByGroup(groupId sql.NullInt64) order_column.Orderer {
+	orderer := walletOrderer
+	if groupId.Valid {
+		orderer.FilterQuery = fmt.Sprintf("group_id = %d", groupId.Int64)
+	} else {
+		orderer.FilterQuery = "group_id IS NULL"
+	}
+
+	return orderer
+}
+
+func initTableWalletGroups() error {
+	version, err := schema_version.GetVersion(DB, "wallet_groups")
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		_, err := DB.Exec(`
+			CREATE TABLE IF NOT EXISTS wallet_groups (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name VARCHAR NOT NULL,
+				order_number INT NOT NULL,
+				collapsed BOOL NOT NULL DEFAULT 0
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 1
+		err = schema_version.StoreVersion(DB, "wallet_groups", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func GetWalletGroups() ([]WalletGroup, error) {
+	query := sq.Select("*").From("wallet_groups").OrderBy("order_number ASC")
+
+	rows, err := query.RunWith(DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []WalletGroup
+	for rows.Next() {
+		var group WalletGroup
+		err = rows.Scan(
+			&group.ID,
+			&group.Name,
+			&group.OrderNumber,
+			&group.Collapsed,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func GetWalletGroup(id int64) (WalletGroup, error) {
+	query := sq.Select("*").From("wallet_groups").Where(sq.Eq{"id": id})
+
+	var group WalletGroup
+	row := query.RunWith(DB).QueryRow()
+	err := row.Scan(
+		&group.ID,
+		&group.Name,
+		&group.OrderNumber,
+		&group.Collapsed,
+	)
+	return group, err
+}
+
+func validateWalletGroupName(id int64, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("enter group name")
+	}
+
+	var count int
+	row := DB.QueryRow(`
+		SELECT COUNT(*) FROM wallet_groups
+		WHERE name = ? AND id != ?;
+	`, name, id)
+	err := row.Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("the group [%s] already exists", name)
+	}
+
+	return nil
+}
+
+func InsertWalletGroup(group WalletGroup) (int64, error) {
+	group.Name = strings.TrimSpace(group.Name)
+	err := validateWalletGroupName(0, group.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	group.OrderNumber, err = walletGroupOrderer.GetNewOrderNumber(tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO wallet_groups (name,order_number,collapsed)
+		VALUES (?,?,?);
+	`, group.Name, group.OrderNumber, group.Collapsed)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func UpdateWalletGroup(group WalletGroup) error {
+	group.Name = strings.TrimSpace(group.Name)
+	err := validateWalletGroupName(group.ID, group.Name)
+	if err != nil {
+		return err
+	}
+
+	currentGroup, err := GetWalletGroup(group.ID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = walletGroupOrderer.Update(tx, currentGroup.OrderNumber, group.OrderNumber)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE wallet_groups
+		SET name = ?,
+				order_number = ?,
+				collapsed = ?
+		WHERE id = ?;
+	`, group.Name, group.OrderNumber, group.Collapsed, group.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DelWalletGroup deletes the group and moves its wallets at the end of the ungrouped wallets
+func DelWalletGroup(id int64) error {
+	group, err := GetWalletGroup(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	orderOffset, err := walletOrdererByGroup(sql.NullInt64{}).GetNewOrderNumber(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE wallets
+		SET group_id = NULL,
+				order_number = order_number + ?
+		WHERE group_id = ?;
+	`, orderOffset, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = walletGroupOrderer.Delete(tx, group.OrderNumber)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM wallet_groups
+		WHERE id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,6 +1,7 @@
 package app_db
 
 import (
+	"database/sql"
 	"encoding/json"
 	"io/fs"
 	"os"
@@ -19,6 +20,11 @@ type WalletInfo struct {
 	RegistrationTxHex string
 	Timestamp         int64
 	OrderNumber       int
+	ReadOnly          bool   // opened with the viewing PIN
+	ViewingData       string // wallet password encrypted with the viewing PIN
+	GroupId           sql.NullInt64
+	ParentAddr        string // master wallet of a derived account
+	DerivationIndex   int
 }
 
 var walletOrderer = order_column.Orderer{
@@ -32,6 +38,8 @@ func initTableWallets() error {
 		return err
 	}
 
+	migrateJson := false
+
 	if version == 0 {
 		_, err := DB.Exec(`
 			CREATE TABLE IF NOT EXISTS wallets (
@@ -46,18 +54,69 @@ func initTableWallets() error {
 			return err
 		}
 
-		err = migrateJsonWalletsInfo()
+		migrateJson = true
+		version = 1
+		err = schema_version.StoreVersion(DB, "wallets", version)
 		if err != nil {
 			return err
 		}
+	}
 
-		version = 1
+	if version == 1 {
+		_, err := DB.Exec(`
+			ALTER TABLE wallets ADD COLUMN read_only BOOL NOT NULL DEFAULT 0;
+			ALTER TABLE wallets ADD COLUMN viewing_data VARCHAR NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 2
 		err = schema_version.StoreVersion(DB, "wallets", version)
 		if err != nil {
 			return err
 		}
 	}
 
+	if version == 2 {
+		_, err := DB.Exec(`
+			ALTER TABLE wallets ADD COLUMN group_id INTEGER;
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 3
+		err = schema_version.StoreVersion(DB, "wallets", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if version == 3 {
+		_, err := DB.Exec(`
+			ALTER TABLE wallets ADD COLUMN parent_addr VARCHAR NOT NULL DEFAULT '';
+			ALTER TABLE wallets ADD COLUMN derivation_index INT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 4
+		err = schema_version.StoreVersion(DB, "wallets", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	// migrate after the columns are added or the insert would fail
+	if migrateJson {
+		err = migrateJsonWalletsInfo()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -162,6 +221,11 @@ func GetWallets() ([]WalletInfo, error) {
 			&wallet.RegistrationTxHex,
 			&wallet.Timestamp,
 			&wallet.OrderNumber,
+			&wallet.ReadOnly,
+			&wallet.ViewingData,
+			&wallet.GroupId,
+			&wallet.ParentAddr,
+			&wallet.DerivationIndex,
 		)
 		if err != nil {
 			return nil, err
@@ -184,6 +248,11 @@ func GetWalletInfo(addr string) (WalletInfo, error) {
 		&walletInfo.RegistrationTxHex,
 		&walletInfo.Timestamp,
 		&walletInfo.OrderNumber,
+		&walletInfo.ReadOnly,
+		&walletInfo.ViewingData,
+		&walletInfo.GroupId,
+		&walletInfo.ParentAddr,
+		&walletInfo.DerivationIndex,
 	)
 	return walletInfo, err
 }
@@ -194,8 +263,9 @@ func InsertWalletInfo(walletInfo WalletInfo) error {
 		return err
 	}
 
-	walletInfo.OrderNumber, err = walletOrderer.GetNewOrderNumber(tx)
+	walletInfo.OrderNumber, err = walletOrdererByGroup(walletInfo.GroupId).GetNewOrderNumber(tx)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
@@ -208,9 +278,10 @@ func InsertWalletInfo(walletInfo WalletInfo) error {
 	*/
 
 	_, err = tx.Exec(`
-		INSERT INTO wallets (addr,name,registration_tx_hex,timestamp,order_number)
-		VALUES (?,?,?,?,?);
-	`, walletInfo.Addr, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.Timestamp, walletInfo.OrderNumber)
+		INSERT INTO wallets (addr,name,registration_tx_hex,timestamp,order_number,read_only,viewing_data,group_id,parent_addr,derivation_index)
+		VALUES (?,?,?,?,?,?,?,?,?,?);
+	`, walletInfo.Addr, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.Timestamp, walletInfo.OrderNumber,
+		walletInfo.ReadOnly, walletInfo.ViewingData, walletInfo.GroupId, walletInfo.ParentAddr, walletInfo.DerivationIndex)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -219,6 +290,8 @@ func InsertWalletInfo(walletInfo WalletInfo) error {
 	return tx.Commit()
 }
 
+// UpdateWalletInfo also moves the wallet to another group if GroupId changed.
+// The wallet is placed at OrderNumber in the new group or at the end if OrderNumber is out of range (e.g -1).
 func UpdateWalletInfo(walletInfo WalletInfo) error {
 	tx, err := DB.Begin()
 	if err != nil {
@@ -227,22 +300,52 @@ func UpdateWalletInfo(walletInfo WalletInfo) error {
 
 	currentWalletInfo, err := GetWalletInfo(walletInfo.Addr)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	err = walletOrderer.Update(tx, currentWalletInfo.OrderNumber, walletInfo.OrderNumber)
-	if err != nil {
-		tx.Rollback()
-		return err
+	if currentWalletInfo.GroupId == walletInfo.GroupId {
+		err = walletOrdererByGroup(walletInfo.GroupId).Update(tx, currentWalletInfo.OrderNumber, walletInfo.OrderNumber)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else {
+		err = walletOrdererByGroup(currentWalletInfo.GroupId).Delete(tx, currentWalletInfo.OrderNumber)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		newGroupOrderer := walletOrdererByGroup(walletInfo.GroupId)
+		lastOrderNumber, err := newGroupOrderer.GetNewOrderNumber(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if walletInfo.OrderNumber < 0 || walletInfo.OrderNumber > lastOrderNumber {
+			walletInfo.OrderNumber = lastOrderNumber
+		}
+
+		err = newGroupOrderer.Insert(tx, walletInfo.OrderNumber)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	_, err = tx.Exec(`
 		UPDATE wallets
 		SET name = ?,
 				registration_tx_hex = ?,
-				order_number = ?
+				order_number = ?,
+				read_only = ?,
+				viewing_data = ?,
+				group_id = ?
 		WHERE addr = ?;
-	`, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.OrderNumber, walletInfo.Addr)
+	`, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.OrderNumber,
+		walletInfo.ReadOnly, walletInfo.ViewingData, walletInfo.GroupId, walletInfo.Addr)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -251,6 +354,21 @@ func UpdateWalletInfo(walletInfo WalletInfo) error {
 	return tx.Commit()
 }
 
+// GetNextDerivationIndex returns the next account index of a master wallet (the master is index 0)
+func GetNextDerivationIndex(parentAddr string) (int, error) {
+	var index sql.NullInt64
+	row := DB.QueryRow(`
+		SELECT MAX(derivation_index) FROM wallets
+		WHERE parent_addr = ?;
+	`, parentAddr)
+	err := row.Scan(&index)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(index.Int64) + 1, nil
+}
+
 func DelWalletInfo(addr string) error {
 	tx, err := DB.Begin()
 	if err != nil {
@@ -259,10 +377,11 @@ func DelWalletInfo(addr string) error {
 
 	walletInfo, err := GetWalletInfo(addr)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	err = walletOrderer.Delete(tx, walletInfo.OrderNumber)
+	err = walletOrdererByGroup(walletInfo.GroupId).Delete(tx, walletInfo.OrderNumber)
 	if err != nil {
 		tx.Rollback()
 		return err
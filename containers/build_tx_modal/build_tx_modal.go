@@ -1,7 +1,6 @@
 package build_tx_modal
 
 import (
-	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -43,6 +42,7 @@ var (
 	Default   LoadStatus = ""
 	FetchAddr LoadStatus = "fetch_addr"
 	LoadFees  LoadStatus = "load_fees"
+	Preparing LoadStatus = "preparing"
 	Building  LoadStatus = "building"
 	Sending   LoadStatus = "sending"
 )
@@ -162,53 +162,9 @@ func LoadInstance() {
 		DrawIndex: 2,
 		Layout:    Instance.layout,
 	})
-}
 
-func FormatSCInvoke(p rpc.SC_Invoke_Params, randomAddr string) (t rpc.Transfer_Params) {
-	if p.SC_DERO_Deposit > 0 {
-		t.Transfers = append(t.Transfers, rpc.Transfer{Destination: randomAddr, Amount: 0, Burn: p.SC_DERO_Deposit})
-	}
-	if p.SC_TOKEN_Deposit > 0 {
-		scid := crypto.HashHexToHash(p.SC_ID)
-		t.Transfers = append(t.Transfers, rpc.Transfer{SCID: scid, Amount: 0, Burn: p.SC_TOKEN_Deposit})
-	}
-	t.SC_RPC = p.SC_RPC
-	t.SC_ID = p.SC_ID
-	t.Ringsize = p.Ringsize
-	return
-}
-
-func FormatTransfer(p rpc.Transfer_Params) (rpc.Transfer_Params, error) {
-	for _, t := range p.Transfers {
-		_, err := t.Payload_RPC.CheckPack(transaction.PAYLOAD0_LIMIT)
-		if err != nil {
-			return p, err
-		}
-	}
-
-	if len(p.SC_Code) >= 1 {
-		sc, err := base64.StdEncoding.DecodeString(p.SC_Code)
-		if err != nil {
-			return p, err
-		}
-
-		p.SC_Code = string(sc)
-	}
-
-	if p.SC_Code != "" && p.SC_ID == "" {
-		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_INSTALL)})
-		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: p.SC_Code})
-	}
-
-	if p.SC_ID != "" {
-		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)})
-		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCID, DataType: rpc.DataHash, Value: crypto.HashHexToHash(p.SC_ID)})
-		if p.SC_Code != "" {
-			p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: p.SC_Code})
-		}
-	}
-
-	return p, nil
+	// let the user continue a send in warn mode when the balances cross-check fails
+	wallet_manager.ConfirmCrossCheck = ConfirmCrossCheck
 }
 
 func (b *BuildTxModal) OpenWithRandomAddr(scId crypto.Hash, onLoad func(addr string) TxPayload) {
@@ -241,26 +197,13 @@ func (b *BuildTxModal) Open(txPayload TxPayload) {
 
 	// func to format transfer and calculate fees
 	load := func() (err error) {
-		txPayload.Transfer, err = FormatTransfer(txPayload.Transfer)
+		txPayload.Transfer, err = wallet_manager.FormatTransfer(txPayload.Transfer)
 		if err != nil {
 			return
 		}
 
-		txType := transaction.NORMAL
-		if len(txPayload.Transfer.SC_RPC) > 0 {
-			txType = transaction.SC_TX
-			txPayload.Transfer.Signer = wallet.Memory.GetAddress().String()
-
-			var gasFees uint64
-			gasFees, err = wallet.Memory.EstimateGasFees(txPayload.Transfer)
-			if err != nil {
-				return
-			}
-			b.gasFees = gasFees
-		}
-
-		b.txFees = wallet.Memory.EstimateTxFees(len(txPayload.Transfer.Transfers), int(txPayload.Transfer.Ringsize), txPayload.Transfer.SC_RPC, txType)
-		return nil
+		b.txFees, b.gasFees, err = wallet.EstimateFees(&txPayload.Transfer)
+		return
 	}
 
 	b.SetLoadStatus(LoadFees)
@@ -311,23 +254,23 @@ func (b *BuildTxModal) buildAndSendTx() {
 	wallet := wallet_manager.OpenedWallet
 
 	buildAndSend := func() (tx *transaction.Transaction, err error) {
-		b.SetLoadStatus(Building)
-		tx, err = wallet.Memory.TransferFeesPrecomputed(b.txPayload.Transfer.Transfers, b.txPayload.Transfer.Ringsize, false, b.txPayload.Transfer.SC_RPC, b.gasFees, b.txFees, false)
-		if err != nil {
-			return
-		}
+		wallet.LockTransfer()
+		defer wallet.UnlockTransfer()
 
-		b.SetLoadStatus(Sending)
-		err = wallet.Memory.SendTransaction(tx)
+		b.SetLoadStatus(Preparing)
+		unsignedTx, err := wallet.PrepareTx(wallet.Memory.GetAddress().String(), b.txPayload.Transfer, b.txFees, b.gasFees)
 		if err != nil {
 			return
 		}
 
-		err = wallet.InsertOutgoingTx(tx, b.txPayload.Note)
+		b.SetLoadStatus(Building)
+		tx, err = wallet.SignTx(unsignedTx)
 		if err != nil {
 			return
 		}
 
+		b.SetLoadStatus(Sending)
+		err = wallet.SendTx(tx, &b.txPayload.Transfer, b.txPayload.Note)
 		return
 	}
 
@@ -403,6 +346,8 @@ func (b *BuildTxModal) layout(gtx layout.Context, th *material.Theme) {
 							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 								txt := ""
 								switch b.loadStatus {
+								case Preparing:
+									txt = lang.Translate("Preparing transaction...")
 								case Building:
 									txt = lang.Translate("Building transaction...")
 								case Sending:
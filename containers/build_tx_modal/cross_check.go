@@ -0,0 +1,32 @@
+package build_tx_modal
+
+import (
+	"fmt"
+
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+// ConfirmCrossCheck handles the error of a paranoid mode cross-check.
+// The user can still continue in warn mode but the error is returned in block mode.
+func ConfirmCrossCheck(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if settings.App.ParanoidMode != settings.ParanoidModeWarn {
+		return err
+	}
+
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Title:  lang.Translate("Cross-check failed"),
+		Prompt: fmt.Sprintf("%s\n\n%s", err.Error(), lang.Translate("The nodes don't agree. Do you want to continue anyway?")),
+	})
+
+	if !<-yesChan {
+		return fmt.Errorf("cancel transfer")
+	}
+
+	return nil
+}
@@ -42,6 +42,8 @@ type NodeStatusBar struct {
 
 var Instance *NodeStatusBar
 
+var SWITCH_EVENT_DISPLAY_DURATION = 30 * time.Second
+
 func LoadInstance() *NodeStatusBar {
 	nodeStatusBar := &NodeStatusBar{
 		clickable: new(widget.Clickable),
@@ -52,14 +54,14 @@ func LoadInstance() *NodeStatusBar {
 	}
 
 	nodeStatusBar.integratedNodeLoop = utils.NewForceActiveLoop(1*time.Second, func() {
-		if integrated_node.Running {
+		if integrated_node.IsRunning() {
 			nodeStatusBar.IntegratedNodeStatus = integrated_node.GetStatus()
 			app_instance.Window.Invalidate()
 		}
 	})
 
 	nodeStatusBar.remoteNodeLoop = utils.NewForceActiveLoop(3*time.Second, func() {
-		if integrated_node.Running {
+		if integrated_node.IsRunning() {
 			return
 		}
 
@@ -110,6 +112,18 @@ func (n *NodeStatusBar) Layout(gtx layout.Context, th *material.Theme) layout.Di
 
 			nodeName = lang.Translate("Integrated Node")
 			status = fmt.Sprintf("%d / %d", walletHeight, daemonHeight)
+
+			switch integrated_node.GetState() {
+			case integrated_node.StateStarting:
+				statusDotColor = theme.Current.NodeStatusDotYellowColor
+				status = lang.Translate("Starting...")
+			case integrated_node.StateStopping:
+				statusDotColor = theme.Current.NodeStatusDotYellowColor
+				status = lang.Translate("Stopping...")
+			case integrated_node.StateStopped:
+				statusDotColor = theme.Current.NodeStatusDotRedColor
+				status = lang.Translate("Stopped")
+			}
 		} else {
 			n.remoteNodeLoop.SetActive()
 			walletHeight := wallet.Memory.Get_Height()
@@ -128,6 +142,13 @@ func (n *NodeStatusBar) Layout(gtx layout.Context, th *material.Theme) layout.Di
 			} else {
 				status = lang.Translate("Disconnected")
 			}
+
+			// let the user know that the failover monitor replaced the node
+			lastSwitch := node_manager.LastSwitchEvent()
+			if lastSwitch != nil && time.Since(lastSwitch.Timestamp) < SWITCH_EVENT_DISPLAY_DURATION {
+				statusDotColor = theme.Current.NodeStatusDotYellowColor
+				status = fmt.Sprintf(lang.Translate("Switched from %s"), lastSwitch.From.Name)
+			}
 		}
 	}
 
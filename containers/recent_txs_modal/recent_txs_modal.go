@@ -219,11 +219,13 @@ func (r *RecentTxsModal) layout(gtx layout.Context, th *material.Theme) {
 }
 
 type TxItem struct {
-	tx             wallet_manager.OutgoingTx
-	buttonOpen     *components.Button
-	buttonRemove   *components.Button
-	listItemSelect *prefabs.ListItemSelect
-	clickable      *widget.Clickable
+	tx                wallet_manager.OutgoingTx
+	buttonOpen        *components.Button
+	buttonRemove      *components.Button
+	buttonRebroadcast *components.Button
+	buttonRebuild     *components.Button
+	listItemSelect    *prefabs.ListItemSelect
+	clickable         *widget.Clickable
 }
 
 func NewTxItem(tx wallet_manager.OutgoingTx) *TxItem {
@@ -246,12 +248,32 @@ func NewTxItem(tx wallet_manager.OutgoingTx) *TxItem {
 		Animation: components.NewButtonAnimationDefault(),
 	})
 
+	rebroadcastIcon, _ := widget.NewIcon(icons.AVRepeat)
+	buttonRebroadcast := components.NewButton(components.ButtonStyle{
+		Icon:      rebroadcastIcon,
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(5)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+
+	rebuildIcon, _ := widget.NewIcon(icons.ActionBuild)
+	buttonRebuild := components.NewButton(components.ButtonStyle{
+		Icon:      rebuildIcon,
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(5)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+
 	return &TxItem{
-		tx:             tx,
-		buttonOpen:     buttonOpen,
-		buttonRemove:   buttonRemove,
-		listItemSelect: prefabs.NewListItemSelect(),
-		clickable:      &widget.Clickable{},
+		tx:                tx,
+		buttonOpen:        buttonOpen,
+		buttonRemove:      buttonRemove,
+		buttonRebroadcast: buttonRebroadcast,
+		buttonRebuild:     buttonRebuild,
+		listItemSelect:    prefabs.NewListItemSelect(),
+		clickable:         &widget.Clickable{},
 	}
 }
 
@@ -275,6 +297,8 @@ func (item *TxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimens
 		status = strings.Replace(value, "{}", fmt.Sprint(confirmations), -1)
 	case "invalid":
 		status = lang.Translate("Invalid transaction")
+	case "replaced":
+		status = lang.Translate("Replaced by a rebuilt transaction")
 	default:
 		status = lang.Translate("Checking transaction...")
 	}
@@ -316,6 +340,61 @@ func (item *TxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimens
 		}
 	}
 
+	if item.buttonRebroadcast.Clicked(gtx) {
+		go func() {
+			wallet := wallet_manager.OpenedWallet
+			err := wallet.RebroadcastOutgoingTx(txId)
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Transaction rebroadcasted."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+				Instance.LoadOutgoingTxs()
+			}
+		}()
+	}
+
+	if item.buttonRebuild.Clicked(gtx) {
+		go func() {
+			yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+				Prompt: lang.Translate("Build and send this transaction again with a new ring? Make sure the previous one was not mined."),
+			})
+
+			if !<-yesChan {
+				return
+			}
+
+			item.buttonRebuild.SetLoading(true)
+			wallet := wallet_manager.OpenedWallet
+			_, err := wallet.RebuildOutgoingTx(txId)
+			item.buttonRebuild.SetLoading(false)
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Transaction rebuilt and sent."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+				Instance.LoadOutgoingTxs()
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
 	if item.clickable.Clicked(gtx) {
 		item.listItemSelect.Toggle()
 	}
@@ -371,9 +450,22 @@ func (item *TxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimens
 
 			c.Add(gtx.Ops)
 
-			item.buttonOpen.Style.Colors = theme.Current.ButtonPrimaryColors
-			item.buttonRemove.Style.Colors = theme.Current.ButtonPrimaryColors
-			item.listItemSelect.Layout(gtx, th, []*components.Button{item.buttonOpen, item.buttonRemove})
+			buttons := []*components.Button{item.buttonOpen}
+			switch item.tx.Status.String {
+			case "pending":
+				buttons = append(buttons, item.buttonRebroadcast)
+			case "invalid":
+				buttons = append(buttons, item.buttonRebroadcast)
+				if item.tx.TransferParams.Valid {
+					buttons = append(buttons, item.buttonRebuild)
+				}
+			}
+			buttons = append(buttons, item.buttonRemove)
+
+			for _, button := range buttons {
+				button.Style.Colors = theme.Current.ButtonPrimaryColors
+			}
+			item.listItemSelect.Layout(gtx, th, buttons)
 
 			return dims
 		})
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/integrated_node"
+	"github.com/g45t345rt/g45w/node_manager"
+	"github.com/g45t345rt/g45w/wallet_manager"
+)
+
+type Args struct {
+	Headless           bool
+	WalletAddr         string
+	WalletPasswordFile string
+	NodeEndpoint       string
+	RPCServer          bool
+}
+
+func parseArgs() (args Args) {
+	// use ContinueOnError because some platforms can launch the app with their own args
+	flags := flag.NewFlagSet("g45w", flag.ContinueOnError)
+	flags.BoolVar(&args.Headless, "headless", false, "run the wallet without a window")
+	flags.StringVar(&args.WalletAddr, "wallet-addr", "", "address of the wallet to open in headless mode (or G45W_WALLET_ADDR)")
+	flags.StringVar(&args.WalletPasswordFile, "wallet-password-file", "", "file containing the wallet password (or G45W_WALLET_PASSWORD_FILE / G45W_WALLET_PASSWORD)")
+	flags.StringVar(&args.NodeEndpoint, "node", "", "node endpoint to use instead of the selected node e.g ws://127.0.0.1:10102/ws (or G45W_NODE)")
+	flags.BoolVar(&args.RPCServer, "rpc-server", false, "start the wallet RPC server with the address and credentials from the wallet settings")
+	flags.Parse(os.Args[1:])
+
+	if args.WalletAddr == "" {
+		args.WalletAddr = os.Getenv("G45W_WALLET_ADDR")
+	}
+
+	if args.WalletPasswordFile == "" {
+		args.WalletPasswordFile = os.Getenv("G45W_WALLET_PASSWORD_FILE")
+	}
+
+	if args.NodeEndpoint == "" {
+		args.NodeEndpoint = os.Getenv("G45W_NODE")
+	}
+
+	return
+}
+
+func (a Args) walletPassword() (string, error) {
+	if a.WalletPasswordFile != "" {
+		data, err := os.ReadFile(a.WalletPasswordFile)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	password, ok := os.LookupEnv("G45W_WALLET_PASSWORD")
+	if !ok {
+		return "", fmt.Errorf("wallet password is missing")
+	}
+
+	return password, nil
+}
+
+// runHeadless loads the app state without creating a window (no Gio rendering)
+// and keeps the wallet synced until the process receives an interrupt signal.
+func runHeadless(args Args) error {
+	err := loadAppState(func(status string) {
+		log.Println(status)
+	})
+	if err != nil {
+		return err
+	}
+
+	if args.NodeEndpoint != "" {
+		log.Printf("Connecting to node %s", args.NodeEndpoint)
+		err = node_manager.Set(&app_db.NodeConnection{
+			Endpoint: args.NodeEndpoint,
+			Name:     "Headless",
+		}, false)
+		if err != nil {
+			return err
+		}
+	} else if node_manager.CurrentNode == nil {
+		log.Println("No node selected. Use --node or select a node from the app.")
+	}
+
+	if args.WalletAddr == "" {
+		return fmt.Errorf("wallet address is missing")
+	}
+
+	password, err := args.walletPassword()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Opening wallet %s", args.WalletAddr)
+	err = wallet_manager.OpenWallet(args.WalletAddr, password)
+	if err != nil {
+		return err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	log.Printf("Wallet [%s] opened", wallet.Info.Name)
+
+	if args.RPCServer {
+		err = wallet.OpenRPCServer()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("RPC server listening on %s", wallet.ServerRPC.Addr)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	// same interval as the recent txs modal
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			updated, err := wallet.UpdatePendingOutgoingTxs()
+			if err != nil {
+				log.Println(err)
+			}
+
+			if updated > 0 {
+				log.Printf("%d outgoing txs updated", updated)
+			}
+		case <-quit:
+			log.Println("Closing wallet")
+			// don't use wallet_manager.CloseOpenedWallet() it closes the wallet in a goroutine
+			// and we need the wallet to be saved before the process exits
+			wallet.CloseRPCServer()
+			close(wallet.Memory.Quit)
+			wallet.Memory.Close_Encrypted_Wallet()
+			wallet.DB.Close()
+			wallet_manager.OpenedWallet = nil
+
+			return integrated_node.Stop()
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package integrated_node
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/deroproject/derohe/blockchain"
+	"github.com/deroproject/derohe/globals"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+// the node needs at least 50 blocks after the pruned topoheight
+const MIN_PRUNE_HISTORY = 100
+
+// pruning rewrites the balance tree so we only do it again once enough blocks were added
+const PRUNE_INTERVAL = 10000
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %s", addr)
+	}
+
+	_, err = strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %s", addr)
+	}
+
+	return nil
+}
+
+func ValidateSettings(config settings.IntegratedNodeSettings) error {
+	for _, addr := range append(config.PriorityNodes, config.ExclusiveNodes...) {
+		err := validateAddr(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.MinPeers < 0 || config.MaxPeers < 0 {
+		return fmt.Errorf("peer limits can't be negative")
+	}
+
+	if config.MaxPeers > 0 && config.MinPeers > config.MaxPeers {
+		return fmt.Errorf("min peers can't be greater than max peers")
+	}
+
+	if config.PruneHistory != 0 && config.PruneHistory < MIN_PRUNE_HISTORY {
+		return fmt.Errorf("prune history must keep at least %d blocks", MIN_PRUNE_HISTORY)
+	}
+
+	// derohe exits the process if the proxy is invalid
+	if config.SocksProxy != "" {
+		err := validateAddr(config.SocksProxy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.P2PBind != "" {
+		err := validateAddr(config.P2PBind)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func optionalArg(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+func optionalIntArg(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+
+	return strconv.Itoa(value)
+}
+
+func applySettings(config settings.IntegratedNodeSettings) {
+	globals.Arguments["--add-priority-node"] = append(make([]string, 0), config.PriorityNodes...)
+	globals.Arguments["--add-exclusive-node"] = append(make([]string, 0), config.ExclusiveNodes...)
+	globals.Arguments["--min-peers"] = optionalIntArg(config.MinPeers)
+	globals.Arguments["--max-peers"] = optionalIntArg(config.MaxPeers)
+	globals.Arguments["--socks-proxy"] = optionalArg(config.SocksProxy)
+	globals.Arguments["--p2p-bind"] = optionalArg(config.P2PBind)
+
+	if config.RPCLan {
+		globals.Arguments["--rpc-bind"] = fmt.Sprintf("0.0.0.0:%d", globals.Config.RPC_Default_Port)
+	} else {
+		globals.Arguments["--rpc-bind"] = nil
+	}
+}
+
+// the blockchain is not loaded yet so we use the size of the topo file to get the topoheight
+func storedTopoHeight() int64 {
+	stat, err := os.Stat(filepath.Join(globals.GetDataDirectory(), "topo.map"))
+	if err != nil {
+		return 0
+	}
+
+	return stat.Size() / blockchain.TOPORECORD_SIZE
+}
+
+// pruneHistory removes the old blocks and balance history before the blockchain starts
+func pruneHistory() error {
+	config := settings.App.IntegratedNode
+	if config.PruneHistory == 0 {
+		return nil
+	}
+
+	pruneTopoHeight := storedTopoHeight() - config.PruneHistory
+	if pruneTopoHeight-config.PrunedTopoHeight < PRUNE_INTERVAL {
+		return nil
+	}
+
+	err := blockchain.Prune_Blockchain(pruneTopoHeight)
+	if err != nil {
+		return err
+	}
+
+	settings.App.IntegratedNode.PrunedTopoHeight = pruneTopoHeight
+	return settings.Save()
+}
@@ -0,0 +1,120 @@
+package integrated_node
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/p2p"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/utils"
+)
+
+type PeerInfo struct {
+	Address    string
+	PeerID     uint64
+	Height     int64
+	TopoHeight int64
+	Latency    time.Duration
+	Incoming   bool
+	Tag        string
+	Version    string
+	Connected  time.Duration
+	BytesIn    uint64
+	BytesOut   uint64
+}
+
+// GetPeers returns the connected peers sorted by address (duplicate connections to the same peer are removed)
+func GetPeers() (peers []PeerInfo) {
+	if Chain == nil {
+		return
+	}
+
+	for _, conn := range p2p.UniqueConnections() {
+		peers = append(peers, PeerInfo{
+			Address:    p2p.Address(conn),
+			PeerID:     conn.Peer_ID,
+			Height:     atomic.LoadInt64(&conn.Height),
+			TopoHeight: atomic.LoadInt64(&conn.TopoHeight),
+			Latency:    time.Duration(atomic.LoadInt64(&conn.Latency)).Round(time.Millisecond),
+			Incoming:   conn.Incoming,
+			Tag:        conn.Tag,
+			Version:    conn.DaemonVersion,
+			Connected:  time.Since(conn.Created).Round(time.Second),
+			BytesIn:    atomic.LoadUint64(&conn.BytesIn),
+			BytesOut:   atomic.LoadUint64(&conn.BytesOut),
+		})
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Address < peers[j].Address
+	})
+
+	return
+}
+
+// BanPeer bans the address (or subnet) and the connection is dropped on the next clean up of the node
+func BanPeer(address string, duration time.Duration) error {
+	return p2p.Ban_Address(address, uint64(duration.Seconds()))
+}
+
+type PoolTx struct {
+	TXID crypto.Hash
+	Size uint64
+	Fees uint64
+}
+
+// GetMempoolTxs returns the mempool txs sorted by fees per byte (highest first)
+func GetMempoolTxs() (txs []PoolTx) {
+	if Chain == nil {
+		return
+	}
+
+	for _, info := range Chain.Mempool.Mempool_List_TX_SortedInfo() {
+		poolTx := PoolTx{TXID: info.Hash, Size: info.Size}
+
+		tx := Chain.Mempool.Mempool_Get_TX(info.Hash)
+		if tx != nil {
+			poolTx.Fees = tx.Fees()
+		}
+
+		txs = append(txs, poolTx)
+	}
+
+	return
+}
+
+// GetRegpoolTxs returns the registration txs waiting to be mined (they don't have fees)
+func GetRegpoolTxs() (txs []PoolTx) {
+	if Chain == nil {
+		return
+	}
+
+	for _, txId := range Chain.Regpool.Regpool_List_TX() {
+		tx := Chain.Regpool.Regpool_Get_TX(txId)
+		if tx == nil {
+			continue
+		}
+
+		txs = append(txs, PoolTx{
+			TXID: txId,
+			Size: uint64(len(tx.Serialize())),
+		})
+	}
+
+	return
+}
+
+func FlushRegpool() {
+	if Chain == nil {
+		return
+	}
+
+	Chain.Regpool.Regpool_flush()
+}
+
+// GetNodeSize returns the disk space used by the blockchain data
+func GetNodeSize() (int64, error) {
+	return utils.GetFolderSize(settings.IntegratedNodeDir)
+}
@@ -1,10 +1,12 @@
 package integrated_node
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"io"
 	"os"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/deroproject/derohe/block"
@@ -14,20 +16,50 @@ import (
 	"github.com/deroproject/derohe/metrics"
 	"github.com/deroproject/derohe/p2p"
 	"github.com/g45t345rt/g45w/settings"
-	"github.com/g45t345rt/g45w/utils"
+	"github.com/robfig/cron/v3"
 )
 
 var Chain *blockchain.Blockchain
 var RPCServer *derodrpc.RPCServer
-var Running bool
 
-func Start() error {
-	if Running {
+// the peer connections use udp and can't go through the app proxy
+var ErrPrivacyMode = errors.New("the integrated node is not available in privacy mode")
+
+// serialize Start and Stop
+var lifecycleLock sync.Mutex
+var initLogOnce sync.Once
+var p2pStarted bool
+
+// Start runs the integrated node. Canceling the context aborts the start and releases what was already started.
+func Start(ctx context.Context) (err error) {
+	lifecycleLock.Lock()
+	defer lifecycleLock.Unlock()
+
+	if GetState() == StateRunning {
 		return nil
 	}
 
+	setState(StateStarting, nil)
+	defer func() {
+		if err != nil {
+			shutdown()
+			setState(StateStopped, err)
+		}
+	}()
+
 	runtime.MemProfileRate = 0
 
+	config := settings.App.IntegratedNode
+	err = ValidateSettings(config)
+	if err != nil {
+		return
+	}
+
+	if settings.App.Proxy.PrivacyMode {
+		err = ErrPrivacyMode
+		return
+	}
+
 	nodeDir := settings.IntegratedNodeDir
 	globals.Arguments["--timeisinsync"] = false
 	globals.Arguments["--p2p-bind"] = nil
@@ -46,31 +78,60 @@ func Start() error {
 	globals.Arguments["--getwork-bind"] = nil
 	globals.Arguments["--prune-history"] = nil
 
-	globals.InitializeLog(os.Stdout, io.Discard)
+	// overwrite the defaults with the user settings (the proxy must be set before Initialize)
+	applySettings(config)
+
+	// the log sinks are global and would be duplicated on every start
+	initLogOnce.Do(func() {
+		globals.InitializeLog(os.Stdout, io.Discard)
+	})
 	globals.Initialize()
 
-	rpcPort := globals.Config.RPC_Default_Port
-	rpcAddr := fmt.Sprintf("127.0.0.1:%d", rpcPort)
-	_, err := utils.IsTcpAddrInUse(rpcAddr)
+	err = checkSnapshot()
+	if err != nil {
+		return
+	}
+
+	// the previous run might still hold the rpc port
+	err = waitPortRelease(ctx, rpcBindAddr())
+	if err != nil {
+		return
+	}
+
+	err = pruneHistory()
 	if err != nil {
-		return err
+		return
+	}
+
+	if err = ctx.Err(); err != nil {
+		return
 	}
 
+	// the cron jobs and the p2p exit signal of the previous run must not be reused
+	globals.Cron = cron.New(cron.WithChain(cron.Recover(globals.Logger)))
+	p2p.Exit_Event = make(chan bool)
+
 	params := make(map[string]interface{})
 
 	chain, err := blockchain.Blockchain_Start(params)
 	if err != nil {
-		return err
+		return
 	}
 
 	Chain = chain
 	params["chain"] = chain
 
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	err = p2p.P2P_Init(params)
 	if err != nil {
-		return err
+		return
 	}
 
+	p2pStarted = true
+
 	chain.P2P_Block_Relayer = func(cbl *block.Complete_Block, peerid uint64) {
 		p2p.Broadcast_Block(cbl, peerid)
 	}
@@ -79,23 +140,82 @@ func Start() error {
 		p2p.Broadcast_MiniBlock(mbl, peerid)
 	}
 
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	RPCServer, err = derodrpc.RPCServer_Start(params)
 	if err != nil {
-		return err
+		return
 	}
 
 	globals.Cron.Start()
-	Running = true
+	setState(StateRunning, nil)
 	return nil
 }
 
-func Stop() {
-	RPCServer.RPCServer_Stop()
-	p2p.P2P_Shutdown() // does not close process_outgoing_connection? :(
-	Chain.Shutdown()
-	globals.Cron.Stop()
+// closeConnections closes the peer connections because P2P_Shutdown leaves them open
+func closeConnections() {
+	for _, conn := range p2p.UniqueConnections() {
+		if conn.Client != nil {
+			conn.Client.Close()
+		}
+
+		if conn.ConnTls != nil {
+			conn.ConnTls.Close()
+		}
+
+		if conn.Conn != nil {
+			conn.Conn.Close()
+		}
+
+		p2p.Connection_Delete(conn)
+	}
+}
+
+// shutdown stops every part of the node that was started
+func shutdown() {
+	if RPCServer != nil {
+		RPCServer.RPCServer_Stop()
+		RPCServer = nil
+	}
+
+	if p2pStarted {
+		closeConnections()
+		// stops the p2p loops and the listener
+		close(p2p.Exit_Event)
+		p2p.P2P_Shutdown()
+		p2pStarted = false
+	}
+
+	// wait for the running jobs to finish before closing the chain
+	<-globals.Cron.Stop().Done()
+
+	if Chain != nil {
+		Chain.Shutdown()
+		Chain.Store.Balance_store.Close()
+		Chain = nil
+	}
+
 	metrics.Set.UnregisterAllMetrics()
-	Running = false
+}
+
+// Stop shuts down the integrated node and waits for the rpc port to be released
+func Stop() error {
+	lifecycleLock.Lock()
+	defer lifecycleLock.Unlock()
+
+	if GetState() != StateRunning {
+		return nil
+	}
+
+	setState(StateStopping, nil)
+	addr := rpcBindAddr()
+	shutdown()
+
+	err := waitPortRelease(context.Background(), addr)
+	setState(StateStopped, err)
+	return err
 }
 
 type NodeStatus struct {
@@ -114,18 +234,20 @@ type NodeStatus struct {
 }
 
 func GetStatus() (n NodeStatus) {
-	if Chain == nil {
+	// the node can be stopped at any time
+	chain := Chain
+	if chain == nil {
 		return
 	}
 
-	n.Height = Chain.Get_Height()
+	n.Height = chain.Get_Height()
 	bestHeight, _ := p2p.Best_Peer_Height()
-	n.StableHeight = Chain.Get_Stable_Height()
+	n.StableHeight = chain.Get_Stable_Height()
 	n.BestHeight = bestHeight
 	//topo_height := chain.Load_TOPO_HEIGHT()
 
-	n.MemCount = len(Chain.Mempool.Mempool_List_TX())
-	n.RegCount = len(Chain.Regpool.Regpool_List_TX())
+	n.MemCount = len(chain.Mempool.Mempool_List_TX())
+	n.RegCount = len(chain.Regpool.Regpool_List_TX())
 
 	//p2p.PeerList_Print()
 	//n.PeerCount = p2p.Peer_Count()
@@ -133,7 +255,7 @@ func GetStatus() (n NodeStatus) {
 	n.PeerInCount = in
 	n.PeerOutCount = out
 
-	n.NetworkHashRate = Chain.Get_Network_HashRate()
+	n.NetworkHashRate = chain.Get_Network_HashRate()
 
 	n.TimeOffset = globals.GetOffset().Round(time.Millisecond)
 	n.TimeOffsetNTP = globals.GetOffsetNTP().Round(time.Millisecond)
@@ -191,41 +313,4 @@ func (n *NodeStatus) Update() {
 	n.TimeOffsetNTP = globals.GetOffsetNTP().Round(time.Millisecond)
 	n.TimeOffsetP2P = globals.GetOffsetP2P().Round(time.Millisecond)
 }
-
-type NodeSize struct {
-	Size int64
-
-	isActive bool
-}
-
-func NewNodeSize(d time.Duration) *NodeSize {
-	ticker := time.NewTicker(d)
-	window := app_instance.Window
-	nodedSize := &NodeSize{
-		Size:     0,
-		isActive: false,
-	}
-
-	go func() {
-		for range ticker.C {
-			if nodedSize.isActive {
-				nodedSize.update()
-				window.Invalidate()
-				nodedSize.isActive = false
-			}
-		}
-	}()
-
-	return nodedSize
-}
-
-func (n *NodeSize) SetActive() {
-	n.isActive = true
-}
-
-func (n *NodeSize) update() {
-	nodeDir := settings.IntegratedNodeDir
-	size, _ := utils.GetFolderSize(nodeDir)
-	n.Size = size
-}
 */
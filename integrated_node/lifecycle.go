@@ -0,0 +1,130 @@
+package integrated_node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deroproject/derohe/globals"
+	"github.com/g45t345rt/g45w/utils"
+)
+
+type State string
+
+var (
+	StateStopped  State = "stopped"
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateStopping State = "stopping"
+)
+
+type StateEvent struct {
+	State     State
+	Err       error
+	Timestamp time.Time
+}
+
+// the rpc port can take a moment to be released by the os after the server is closed
+var PORT_RELEASE_TIMEOUT = 10 * time.Second
+
+var state = StateStopped
+var stateErr error
+var stateLock sync.RWMutex
+var subscribers = make(map[chan StateEvent]bool)
+
+// canceled when the node stops
+var runCtx, runCancel = context.WithCancel(context.Background())
+
+func init() {
+	runCancel()
+}
+
+func GetState() State {
+	stateLock.RLock()
+	defer stateLock.RUnlock()
+	return state
+}
+
+// LastError returns the error that stopped the node or made the start fail
+func LastError() error {
+	stateLock.RLock()
+	defer stateLock.RUnlock()
+	return stateErr
+}
+
+func IsRunning() bool {
+	return GetState() == StateRunning
+}
+
+// Context is done when the node stops. Use it for background work that depends on the node.
+func Context() context.Context {
+	stateLock.RLock()
+	defer stateLock.RUnlock()
+	return runCtx
+}
+
+// Subscribe returns a channel receiving the state changes. Slow subscribers miss events instead of blocking the node.
+func Subscribe() chan StateEvent {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	ch := make(chan StateEvent, 10)
+	subscribers[ch] = true
+	return ch
+}
+
+func Unsubscribe(ch chan StateEvent) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	delete(subscribers, ch)
+}
+
+func setState(newState State, err error) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	state = newState
+	stateErr = err
+
+	switch newState {
+	case StateRunning:
+		runCtx, runCancel = context.WithCancel(context.Background())
+	case StateStopping, StateStopped:
+		runCancel()
+	}
+
+	event := StateEvent{State: newState, Err: err, Timestamp: time.Now()}
+	for ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func rpcBindAddr() string {
+	if addr, ok := globals.Arguments["--rpc-bind"].(string); ok {
+		return addr
+	}
+
+	return fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
+}
+
+func waitPortRelease(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithTimeout(ctx, PORT_RELEASE_TIMEOUT)
+	defer cancel()
+
+	for {
+		inUse, _ := utils.IsTcpAddrInUse(addr)
+		if !inUse {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the address %s is still in use", addr)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
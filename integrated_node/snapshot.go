@@ -0,0 +1,457 @@
+package integrated_node
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deroproject/derohe/blockchain"
+	"github.com/deroproject/derohe/globals"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+const SNAPSHOT_VERSION = 1
+const SNAPSHOT_FILE_EXTENSION = ".g45wnode"
+
+// the manifest is the first entry of the archive and is kept in the network folder with the imported data
+const SNAPSHOT_MANIFEST_NAME = "snapshot.json"
+
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+var ErrNodeRunning = errors.New("the integrated node must be stopped")
+var ErrSnapshotMismatch = errors.New("the imported snapshot does not match the network")
+
+type SnapshotManifest struct {
+	Version      int       `json:"version"`
+	Timestamp    time.Time `json:"timestamp"`
+	Testnet      bool      `json:"testnet"`
+	TopoHeight   int64     `json:"topoheight"`
+	Height       int64     `json:"height"`
+	TopBlockHash string    `json:"top_block_hash"`
+	Verified     bool      `json:"verified"`
+	Mismatch     bool      `json:"mismatch"`
+}
+
+type SnapshotStatus string
+
+var (
+	SnapshotNone     SnapshotStatus = ""
+	SnapshotPending  SnapshotStatus = "pending"
+	SnapshotVerified SnapshotStatus = "verified"
+	SnapshotMismatch SnapshotStatus = "mismatch"
+)
+
+var snapshotStatus SnapshotStatus
+var snapshotStatusLock sync.RWMutex
+
+func GetSnapshotStatus() SnapshotStatus {
+	snapshotStatusLock.RLock()
+	defer snapshotStatusLock.RUnlock()
+	return snapshotStatus
+}
+
+func setSnapshotStatus(status SnapshotStatus) {
+	snapshotStatusLock.Lock()
+	defer snapshotStatusLock.Unlock()
+	snapshotStatus = status
+}
+
+// network folder created by derohe inside the node folder
+func networkDirName() string {
+	if globals.IsMainnet() {
+		return "mainnet"
+	}
+
+	return "testnet"
+}
+
+// networkDataDir is the blockchain data folder of the current network
+func networkDataDir() string {
+	return filepath.Join(settings.IntegratedNodeDir, networkDirName())
+}
+
+// readTopBlock reads the last topo record directly from the topo file (the blockchain does not have to be loaded)
+func readTopBlock(dataDir string) (topoHeight int64, height int64, hash string, err error) {
+	file, err := os.Open(filepath.Join(dataDir, "topo.map"))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return
+	}
+
+	var zeroId [32]byte
+	buf := make([]byte, blockchain.TOPORECORD_SIZE)
+	for index := stat.Size()/blockchain.TOPORECORD_SIZE - 1; index >= 0; index-- {
+		_, err = file.ReadAt(buf, index*blockchain.TOPORECORD_SIZE)
+		if err != nil {
+			return
+		}
+
+		var blockId [32]byte
+		copy(blockId[:], buf[:32])
+		stateVersion := binary.LittleEndian.Uint64(buf[32:])
+		if blockId == zeroId && stateVersion == 0 {
+			// clean record
+			continue
+		}
+
+		topoHeight = index
+		height = int64(binary.LittleEndian.Uint64(buf[40:]))
+		hash = hex.EncodeToString(blockId[:])
+		return
+	}
+
+	err = fmt.Errorf("the node does not have any block")
+	return
+}
+
+// ExportSnapshot packages the blockchain data of the integrated node in a gzipped tar archive
+func ExportSnapshot(w io.Writer) error {
+	if GetState() != StateStopped {
+		return ErrNodeRunning
+	}
+
+	dataDir := networkDataDir()
+	topoHeight, height, hash, err := readTopBlock(dataDir)
+	if err != nil {
+		return err
+	}
+
+	manifest := SnapshotManifest{
+		Version:      SNAPSHOT_VERSION,
+		Timestamp:    time.Now(),
+		Testnet:      !globals.IsMainnet(),
+		TopoHeight:   topoHeight,
+		Height:       height,
+		TopBlockHash: hash,
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	gzipWriter, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	err = tarWriter.WriteHeader(&tar.Header{
+		Name:    SNAPSHOT_MANIFEST_NAME,
+		Mode:    0600,
+		Size:    int64(len(manifestData)),
+		ModTime: manifest.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(manifestData)
+	if err != nil {
+		return err
+	}
+
+	err = filepath.Walk(dataDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		// the manifest of a previous import is not part of the chain data
+		if filePath == filepath.Join(dataDir, SNAPSHOT_MANIFEST_NAME) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(settings.IntegratedNodeDir, filePath)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		header.Name = filepath.ToSlash(relPath)
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func extractSnapshot(r io.Reader, destDir string) (manifest SnapshotManifest, err error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		err = ErrInvalidSnapshot
+		return
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	header, err := tarReader.Next()
+	if err != nil || header.Name != SNAPSHOT_MANIFEST_NAME {
+		err = ErrInvalidSnapshot
+		return
+	}
+
+	manifestData, err := io.ReadAll(tarReader)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		err = ErrInvalidSnapshot
+		return
+	}
+
+	if manifest.Version > SNAPSHOT_VERSION {
+		err = fmt.Errorf("the snapshot was created with a newer version of the app")
+		return
+	}
+
+	if manifest.Testnet == globals.IsMainnet() {
+		err = fmt.Errorf("the snapshot is not from the same network")
+		return
+	}
+
+	prefix := networkDirName() + "/"
+	for {
+		header, err = tarReader.Next()
+		if err == io.EOF {
+			err = nil
+			break
+		}
+
+		if err != nil {
+			return
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		relPath := path.Clean(header.Name)
+		if !strings.HasPrefix(relPath, prefix) || strings.Contains(relPath, "..") {
+			err = ErrInvalidSnapshot
+			return
+		}
+
+		filePath := filepath.Join(destDir, filepath.FromSlash(relPath))
+		err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+		if err != nil {
+			return
+		}
+
+		var file *os.File
+		file, err = os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return
+		}
+
+		_, err = io.Copy(file, tarReader)
+		file.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	// make sure the archive top block is the one declared in the manifest
+	topoHeight, _, hash, err := readTopBlock(filepath.Join(destDir, networkDirName()))
+	if err != nil {
+		return
+	}
+
+	if topoHeight != manifest.TopoHeight || hash != manifest.TopBlockHash {
+		err = ErrInvalidSnapshot
+		return
+	}
+
+	return
+}
+
+// ImportSnapshot replaces the blockchain data of the current network with the snapshot.
+// The data of the other network is not touched and the current data is only removed once the snapshot is fully extracted.
+func ImportSnapshot(r io.Reader) (manifest SnapshotManifest, err error) {
+	if GetState() != StateStopped {
+		err = ErrNodeRunning
+		return
+	}
+
+	nodeDir := settings.IntegratedNodeDir
+	importDir := nodeDir + ".import"
+	os.RemoveAll(importDir)
+	defer os.RemoveAll(importDir)
+
+	manifest, err = extractSnapshot(r, importDir)
+	if err != nil {
+		return
+	}
+
+	importDataDir := filepath.Join(importDir, networkDirName())
+	manifest.Verified = false
+	manifest.Mismatch = false
+	err = writeSnapshotManifest(importDataDir, manifest)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(nodeDir, os.ModePerm)
+	if err != nil {
+		return
+	}
+
+	dataDir := networkDataDir()
+	oldDir := dataDir + ".old"
+	os.RemoveAll(oldDir)
+
+	_, err = os.Stat(dataDir)
+	if err == nil {
+		err = os.Rename(dataDir, oldDir)
+		if err != nil {
+			return
+		}
+	}
+
+	err = os.Rename(importDataDir, dataDir)
+	if err != nil {
+		// put back the previous data
+		os.Rename(oldDir, dataDir)
+		return
+	}
+
+	setSnapshotStatus(SnapshotPending)
+
+	// the pruned topoheight was the one of the previous data
+	settings.App.IntegratedNode.PrunedTopoHeight = 0
+	err = settings.Save()
+	if err != nil {
+		return
+	}
+
+	err = os.RemoveAll(oldDir)
+	return
+}
+
+func writeSnapshotManifest(dir string, manifest SnapshotManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, SNAPSHOT_MANIFEST_NAME), data, 0600)
+}
+
+// GetImportedSnapshot returns the manifest of the last imported snapshot if any
+func GetImportedSnapshot() (*SnapshotManifest, error) {
+	data, err := os.ReadFile(filepath.Join(networkDataDir(), SNAPSHOT_MANIFEST_NAME))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var manifest SnapshotManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// VerifySnapshot compares the top block of the imported snapshot with the block returned by the network at the same topoheight.
+// Nothing is done if the snapshot was already verified.
+func VerifySnapshot(getBlockHash func(topoHeight int64) (string, error)) error {
+	manifest, err := GetImportedSnapshot()
+	if err != nil || manifest == nil {
+		return err
+	}
+
+	if manifest.Verified {
+		setSnapshotStatus(SnapshotVerified)
+		return nil
+	}
+
+	if manifest.Mismatch {
+		setSnapshotStatus(SnapshotMismatch)
+		return ErrSnapshotMismatch
+	}
+
+	setSnapshotStatus(SnapshotPending)
+	hash, err := getBlockHash(manifest.TopoHeight)
+	if err != nil {
+		return err
+	}
+
+	if hash != manifest.TopBlockHash {
+		// keep the mismatch in the manifest so the node refuses to start on this data
+		manifest.Mismatch = true
+		setSnapshotStatus(SnapshotMismatch)
+		err = writeSnapshotManifest(networkDataDir(), *manifest)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("the snapshot top block %s does not match the network block %s", manifest.TopBlockHash, hash)
+	}
+
+	manifest.Verified = true
+	setSnapshotStatus(SnapshotVerified)
+	return writeSnapshotManifest(networkDataDir(), *manifest)
+}
+
+// checkSnapshot refuses to start the node on a snapshot that did not match the network
+func checkSnapshot() error {
+	manifest, err := GetImportedSnapshot()
+	if err != nil || manifest == nil {
+		return err
+	}
+
+	if manifest.Mismatch {
+		setSnapshotStatus(SnapshotMismatch)
+		return ErrSnapshotMismatch
+	}
+
+	return nil
+}
@@ -33,6 +33,7 @@ import (
 	page_settings "github.com/g45t345rt/g45w/pages/settings"
 	page_wallet "github.com/g45t345rt/g45w/pages/wallet"
 	page_wallet_select "github.com/g45t345rt/g45w/pages/wallet_select"
+	"github.com/g45t345rt/g45w/proxy_manager"
 	"github.com/g45t345rt/g45w/router"
 	"github.com/g45t345rt/g45w/settings"
 	"github.com/g45t345rt/g45w/theme"
@@ -134,6 +135,60 @@ func loadDeroGlobals(useTestnet bool) {
 	globals.InitNetwork() // this func assign mainnet/testnet config depending on globals.Arguments["--testnet"] value
 }
 
+// loadAppState runs the startup sequence (lang, settings, lookup table, app data, node)
+// It does not depend on app_instance.Window so it can also be used by the headless mode.
+func loadAppState(setStatus func(status string)) error {
+	setStatus("Initiating") // don't use lang.Translate - lang is not loaded
+
+	err := lang.Load()
+	if err != nil {
+		return err
+	}
+
+	setStatus(lang.Translate("Loading settings"))
+	err = settings.Load()
+	if err != nil {
+		return err
+	}
+
+	proxy_manager.Load()
+
+	if android_background_service.IsAvailable() {
+		if settings.App.MobileBackgroundService {
+			err = android_background_service.Start()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	loadDeroGlobals(settings.App.Testnet)
+
+	setStatus(lang.Translate("Loading lookup table"))
+	//walletapi.Initialize_LookupTable(1, 1<<21)
+	err = lookup_table.Load()
+	if err != nil {
+		return err
+	}
+
+	setStatus(lang.Translate("Loading app data"))
+	err = app_db.Load()
+	if err != nil {
+		return err
+	}
+
+	/*
+		setStatus(lang.Translate("Loading wallets"))
+		err = wallet_manager.Load()
+		if err != nil {
+			return err
+		}*/
+
+	node_manager.Load() // don't check for error (e.g if current node connected successfully) and continue loading the app
+	node_manager.StartFailoverMonitor()
+	return nil
+}
+
 func runApp() error {
 	var ops op.Ops
 	app_instance.Load()
@@ -148,58 +203,15 @@ func runApp() error {
 
 	go func() {
 		loadState.logoSplash.animation.Start()
-		loadState.SetStatus("Initiating", nil) // don't use lang.Translate - lang is not loaded
 
-		err := lang.Load()
-		if err != nil {
-			loadState.SetStatus("", err)
-			return
-		}
-
-		loadState.SetStatus(lang.Translate("Loading settings"), nil)
-		err = settings.Load()
-		if err != nil {
-			loadState.SetStatus("", err)
-			return
-		}
-
-		if android_background_service.IsAvailable() {
-			if settings.App.MobileBackgroundService {
-				err = android_background_service.Start()
-				if err != nil {
-					loadState.SetStatus("", err)
-					return
-				}
-			}
-		}
-
-		loadDeroGlobals(settings.App.Testnet)
-
-		loadState.SetStatus(lang.Translate("Loading lookup table"), nil)
-		//walletapi.Initialize_LookupTable(1, 1<<21)
-		err = lookup_table.Load()
-		if err != nil {
-			loadState.SetStatus("", err)
-			return
-		}
-
-		loadState.SetStatus(lang.Translate("Loading app data"), nil)
-		err = app_db.Load()
+		err := loadAppState(func(status string) {
+			loadState.SetStatus(status, nil)
+		})
 		if err != nil {
 			loadState.SetStatus("", err)
 			return
 		}
 
-		/*
-			loadState.SetStatus(lang.Translate("Loading wallets"), nil)
-			err = wallet_manager.Load()
-			if err != nil {
-				loadState.SetStatus("", err)
-				return
-			}*/
-
-		node_manager.Load() // don't check for error (e.g if current node connected successfully) and continue loading the app
-
 		loadState.SetStatus(lang.Translate("Loading pages"), nil)
 		containers.Load()
 		loadPages(router)
@@ -245,6 +257,15 @@ func runApp() error {
 }
 
 func main() {
+	args := parseArgs()
+	if args.Headless {
+		err := runHeadless(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
 	go func() {
 		err := runApp()
 		if err != nil {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/proxy_manager"
 )
 
 func Fetch(url string) (*http.Response, error) {
@@ -23,7 +24,7 @@ func Fetch(url string) (*http.Response, error) {
 }
 
 func HttpFetch(url string, timeout time.Duration) (*http.Response, error) {
-	client := new(http.Client)
+	client := proxy_manager.NewHttpClient()
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
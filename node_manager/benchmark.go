@@ -0,0 +1,109 @@
+package node_manager
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/g45t345rt/g45w/app_db"
+)
+
+// a node is flagged if its height is too far from the other nodes
+const STALE_HEIGHT_LAG = 10
+
+// the median is used as the best known height so a single node reporting a fake height can't flag all the others
+func medianHeight(health []NodeHealth) int64 {
+	var heights []int64
+	for _, h := range health {
+		if h.Err == nil && !h.WrongNetwork {
+			heights = append(heights, h.Info.Height)
+		}
+	}
+
+	if len(heights) == 0 {
+		return 0
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights[len(heights)/2]
+}
+
+func nodeStatus(h NodeHealth, bestHeight int64) string {
+	switch {
+	case h.Err != nil:
+		return app_db.NODE_STATUS_UNREACHABLE
+	case h.WrongNetwork:
+		return app_db.NODE_STATUS_WRONG_NETWORK
+	case h.Info.Height+STALE_HEIGHT_LAG < bestHeight:
+		return app_db.NODE_STATUS_STALE
+	case h.Info.Height > bestHeight+STALE_HEIGHT_LAG:
+		return app_db.NODE_STATUS_AHEAD
+	}
+
+	return app_db.NODE_STATUS_OK
+}
+
+// BenchmarkNodes probes the saved nodes and the trusted nodes concurrently and stores the results of the saved nodes.
+// The trusted nodes that are not saved are only used to find the best known height.
+func BenchmarkNodes() error {
+	nodes, err := app_db.GetNodeConnections()
+	if err != nil {
+		return err
+	}
+
+	endpoints := make(map[string]bool)
+	for _, node := range nodes {
+		endpoints[node.Endpoint] = true
+	}
+
+	probeNodes := append([]app_db.NodeConnection{}, nodes...)
+	for _, node := range app_db.GetTrustedRemoveNodes() {
+		if !endpoints[node.Endpoint] {
+			probeNodes = append(probeNodes, node)
+		}
+	}
+
+	health := make([]NodeHealth, len(probeNodes))
+	var wg sync.WaitGroup
+	for i, node := range probeNodes {
+		wg.Add(1)
+		go func(i int, node app_db.NodeConnection) {
+			defer wg.Done()
+			health[i] = ProbeNode(node, NODE_INFO_TIMEOUT)
+		}(i, node)
+	}
+	wg.Wait()
+
+	bestHeight := medianHeight(health)
+	checkedAt := time.Now().Unix()
+
+	for _, h := range health {
+		if h.Node.ID <= 0 {
+			continue
+		}
+
+		node := h.Node
+		node.Status = sql.NullString{String: nodeStatus(h, bestHeight), Valid: true}
+		node.ConnectTime = sql.NullInt64{Int64: h.ConnectTime.Milliseconds(), Valid: true}
+		node.CheckedAt = sql.NullInt64{Int64: checkedAt, Valid: true}
+		node.BestHeight = sql.NullInt64{Int64: bestHeight, Valid: true}
+
+		if h.Err == nil {
+			node.Latency = sql.NullInt64{Int64: h.Latency.Milliseconds(), Valid: true}
+			node.Height = sql.NullInt64{Int64: h.Info.Height, Valid: true}
+			node.Version = sql.NullString{String: h.Info.Version, Valid: true}
+		} else {
+			node.Latency = sql.NullInt64{}
+			node.Height = sql.NullInt64{}
+			node.Version = sql.NullString{}
+		}
+
+		err = app_db.UpdateNodeBenchmark(node)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
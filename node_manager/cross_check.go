@@ -0,0 +1,207 @@
+package node_manager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+type DivergenceError struct {
+	Method     string
+	TopoHeight int64
+	Endpoints  []string // nodes that returned a different answer than the current node
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("%s returned different data at topoheight %d on %s", e.Method, e.TopoHeight, strings.Join(e.Endpoints, ", "))
+}
+
+// IsParanoid returns true if critical reads must be cross-checked
+func IsParanoid() bool {
+	return settings.App.ParanoidMode != settings.ParanoidModeOff
+}
+
+// crossCheckNodes returns the nodes used to verify the current node (best health first, flagged nodes excluded)
+func crossCheckNodes(count int) ([]app_db.NodeConnection, error) {
+	nodes, err := app_db.GetNodeConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].HealthScore() > nodes[j].HealthScore()
+	})
+
+	nodes = append(nodes, app_db.GetTrustedRemoveNodes()...)
+
+	endpoints := make(map[string]bool)
+	if CurrentNode != nil {
+		endpoints[CurrentNode.Endpoint] = true
+	}
+
+	var checkNodes []app_db.NodeConnection
+	for _, node := range nodes {
+		if len(checkNodes) >= count {
+			break
+		}
+
+		if endpoints[node.Endpoint] || node.HealthScore() < 0 {
+			continue
+		}
+
+		endpoints[node.Endpoint] = true
+		checkNodes = append(checkNodes, node)
+	}
+
+	return checkNodes, nil
+}
+
+type crossCheckAnswer[T any] struct {
+	endpoint string
+	result   T
+	err      error
+}
+
+func callAll[T any](endpoints []string, method string, params interface{}) []crossCheckAnswer[T] {
+	answers := make([]crossCheckAnswer[T], len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			var result T
+			err := CallNode(endpoint, method, params, &result, NODE_INFO_TIMEOUT)
+			answers[i] = crossCheckAnswer[T]{endpoint: endpoint, result: result, err: err}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	return answers
+}
+
+// ErrNotEnoughNodes is returned when no other node could answer the cross-check
+var ErrNotEnoughNodes = errors.New("not enough nodes available to cross-check")
+
+// crossCheckEndpoints returns the endpoint of the current node followed by the nodes used to verify it
+func crossCheckEndpoints() ([]string, error) {
+	if CurrentNode == nil {
+		return nil, fmt.Errorf("node client is not connected")
+	}
+
+	count := settings.App.ParanoidNodes - 1
+	if count < 1 {
+		count = 1
+	}
+
+	nodes, err := crossCheckNodes(count)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []string{CurrentNode.Endpoint}
+	for _, node := range nodes {
+		endpoints = append(endpoints, node.Endpoint)
+	}
+
+	return endpoints, nil
+}
+
+// CrossCheck runs the same call on the current node and on other nodes at the same topoheight.
+// The answers are compared with the key func and a *DivergenceError is returned (with the result of the current node) if they don't match.
+// Nodes that don't answer are ignored but at least one other node must answer.
+func CrossCheck[T any](method string, params func(topoHeight int64) interface{}, key func(result T) (string, error)) (result T, err error) {
+	endpoints, err := crossCheckEndpoints()
+	if err != nil {
+		return
+	}
+
+	// use the lowest topoheight so every node can answer for the same state
+	infos := callAll[rpc.GetInfo_Result](endpoints, "DERO.GetInfo", nil)
+	if infos[0].err != nil {
+		err = infos[0].err
+		return
+	}
+
+	topoHeight := infos[0].result.TopoHeight
+	var validEndpoints []string
+	for _, info := range infos {
+		if info.err != nil {
+			continue
+		}
+
+		if info.result.TopoHeight < topoHeight {
+			topoHeight = info.result.TopoHeight
+		}
+
+		validEndpoints = append(validEndpoints, info.endpoint)
+	}
+
+	return crossCheck(validEndpoints, method, topoHeight, params(topoHeight), key)
+}
+
+// CrossCheckAt is the same as CrossCheck but at a given topoheight. The nodes that are behind are ignored.
+func CrossCheckAt[T any](method string, topoHeight int64, params interface{}, key func(result T) (string, error)) (result T, err error) {
+	endpoints, err := crossCheckEndpoints()
+	if err != nil {
+		return
+	}
+
+	return crossCheck(endpoints, method, topoHeight, params, key)
+}
+
+func crossCheck[T any](endpoints []string, method string, topoHeight int64, params interface{}, key func(result T) (string, error)) (result T, err error) {
+	if len(endpoints) < 2 {
+		err = fmt.Errorf("%w %s", ErrNotEnoughNodes, method)
+		return
+	}
+
+	answers := callAll[T](endpoints, method, params)
+	if answers[0].err != nil {
+		err = answers[0].err
+		return
+	}
+
+	result = answers[0].result
+	currentKey, err := key(result)
+	if err != nil {
+		return
+	}
+
+	var divergingEndpoints []string
+	verified := 0
+	for _, answer := range answers[1:] {
+		if answer.err != nil {
+			continue
+		}
+
+		answerKey, err := key(answer.result)
+		if err != nil || answerKey != currentKey {
+			divergingEndpoints = append(divergingEndpoints, answer.endpoint)
+			continue
+		}
+
+		verified++
+	}
+
+	if len(divergingEndpoints) > 0 {
+		err = &DivergenceError{
+			Method:     method,
+			TopoHeight: topoHeight,
+			Endpoints:  divergingEndpoints,
+		}
+		return
+	}
+
+	if verified == 0 {
+		err = fmt.Errorf("%w %s", ErrNotEnoughNodes, method)
+	}
+
+	return
+}
@@ -0,0 +1,308 @@
+package node_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/proxy_manager"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/gorilla/websocket"
+)
+
+var FAILOVER_CHECK_INTERVAL = 15 * time.Second
+var NODE_INFO_TIMEOUT = 5 * time.Second
+
+// a node is considered out of sync if it's behind the best node of the pool by more than this
+const MAX_HEIGHT_LAG = 3
+const MAX_SWITCH_EVENTS = 20
+
+type NodeHealth struct {
+	Node         app_db.NodeConnection
+	Info         rpc.GetInfo_Result
+	ConnectTime  time.Duration
+	Latency      time.Duration
+	WrongNetwork bool
+	Err          error
+}
+
+func (h NodeHealth) Healthy(bestHeight int64) bool {
+	return h.Err == nil && !h.WrongNetwork && h.Info.Height+MAX_HEIGHT_LAG >= bestHeight
+}
+
+type SwitchEvent struct {
+	Timestamp time.Time
+	From      app_db.NodeConnection
+	To        app_db.NodeConnection
+	Reason    string
+}
+
+var switchEvents []SwitchEvent
+var switchEventsLock sync.RWMutex
+var failoverOnce sync.Once
+
+// dialNode opens a separate connection to the node. It does not touch the wallet connection.
+func dialNode(endpoint string, timeout time.Duration) (*jrpc2.Client, *websocket.Conn, error) {
+	dialer := proxy_manager.NewWebsocketDialer(timeout)
+	ws, _, err := dialer.Dial(endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	input_output := rwc.New(ws)
+	client := jrpc2.NewClient(channel.RawJSON(input_output, input_output), &jrpc2.ClientOptions{})
+	return client, ws, nil
+}
+
+// CallNode opens a connection to the node for a single call
+func CallNode(endpoint string, method string, params interface{}, result interface{}, timeout time.Duration) error {
+	client, ws, err := dialNode(endpoint, timeout)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return client.CallResult(ctx, method, params, result)
+}
+
+// ProbeNode calls DERO.GetInfo and measures the connection time and the round-trip.
+func ProbeNode(node app_db.NodeConnection, timeout time.Duration) (health NodeHealth) {
+	health.Node = node
+
+	start := time.Now()
+	client, ws, err := dialNode(node.Endpoint, timeout)
+	health.ConnectTime = time.Since(start)
+	if err != nil {
+		health.Err = err
+		return
+	}
+	defer ws.Close()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start = time.Now()
+	health.Err = client.CallResult(ctx, "DERO.GetInfo", nil, &health.Info)
+	health.Latency = time.Since(start)
+	if health.Err == nil {
+		health.WrongNetwork = health.Info.Testnet == globals.IsMainnet()
+	}
+
+	return
+}
+
+// GetFailoverPool returns the nodes marked as pool and optionally the trusted nodes (no duplicate endpoints)
+func GetFailoverPool() ([]app_db.NodeConnection, error) {
+	nodes, err := app_db.GetNodeConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make(map[string]bool)
+	var pool []app_db.NodeConnection
+	for _, node := range nodes {
+		if node.Pool {
+			pool = append(pool, node)
+			endpoints[node.Endpoint] = true
+		}
+	}
+
+	if settings.App.NodeFailoverTrusted {
+		for _, node := range app_db.GetTrustedRemoveNodes() {
+			if !endpoints[node.Endpoint] {
+				pool = append(pool, node)
+				endpoints[node.Endpoint] = true
+			}
+		}
+	}
+
+	return pool, nil
+}
+
+// CheckPool fetches the info of every node in the pool concurrently.
+// The result is sorted by health and latency (best first).
+func CheckPool(pool []app_db.NodeConnection) (health []NodeHealth, bestHeight int64) {
+	health = make([]NodeHealth, len(pool))
+
+	var wg sync.WaitGroup
+	for i, node := range pool {
+		wg.Add(1)
+		go func(i int, node app_db.NodeConnection) {
+			defer wg.Done()
+			health[i] = ProbeNode(node, NODE_INFO_TIMEOUT)
+		}(i, node)
+	}
+	wg.Wait()
+
+	for _, h := range health {
+		if h.Err == nil && !h.WrongNetwork && h.Info.Height > bestHeight {
+			bestHeight = h.Info.Height
+		}
+	}
+
+	sort.SliceStable(health, func(i, j int) bool {
+		a, b := health[i], health[j]
+		if a.Healthy(bestHeight) != b.Healthy(bestHeight) {
+			return a.Healthy(bestHeight)
+		}
+
+		return a.Latency < b.Latency
+	})
+
+	return
+}
+
+func GetSwitchEvents() []SwitchEvent {
+	switchEventsLock.RLock()
+	defer switchEventsLock.RUnlock()
+
+	events := make([]SwitchEvent, len(switchEvents))
+	copy(events, switchEvents)
+	return events
+}
+
+// LastSwitchEvent returns the most recent switch event if any
+func LastSwitchEvent() *SwitchEvent {
+	switchEventsLock.RLock()
+	defer switchEventsLock.RUnlock()
+
+	if len(switchEvents) == 0 {
+		return nil
+	}
+
+	event := switchEvents[0]
+	return &event
+}
+
+func addSwitchEvent(event SwitchEvent) {
+	switchEventsLock.Lock()
+	defer switchEventsLock.Unlock()
+
+	switchEvents = append([]SwitchEvent{event}, switchEvents...)
+	if len(switchEvents) > MAX_SWITCH_EVENTS {
+		switchEvents = switchEvents[:MAX_SWITCH_EVENTS]
+	}
+}
+
+func isFailoverActive() bool {
+	currentNode := CurrentNode
+	if !settings.App.NodeFailover || currentNode == nil {
+		return false
+	}
+
+	// the integrated and local nodes are never replaced
+	return !currentNode.Integrated && currentNode.ID != app_db.GetLocalNode().ID
+}
+
+func checkFailover() {
+	if manualSwitches.Load() > 0 {
+		return
+	}
+
+	pool, err := GetFailoverPool()
+	if err != nil || len(pool) == 0 {
+		return
+	}
+
+	switchLock.Lock()
+	if CurrentNode == nil {
+		switchLock.Unlock()
+		return
+	}
+
+	currentNode := *CurrentNode
+	switchLock.Unlock()
+
+	inPool := false
+	for _, node := range pool {
+		if node.Endpoint == currentNode.Endpoint {
+			inPool = true
+		}
+	}
+
+	// always check the current node even if it's not part of the pool
+	if !inPool {
+		pool = append(pool, currentNode)
+	}
+
+	health, bestHeight := CheckPool(pool)
+
+	var currentHealth NodeHealth
+	for _, h := range health {
+		if h.Node.Endpoint == currentNode.Endpoint {
+			currentHealth = h
+		}
+	}
+
+	if currentHealth.Healthy(bestHeight) {
+		return
+	}
+
+	reason := ""
+	if currentHealth.Err != nil {
+		reason = currentHealth.Err.Error()
+	} else if currentHealth.WrongNetwork {
+		reason = "wrong network"
+	} else {
+		reason = fmt.Sprintf("out of sync %d / %d", currentHealth.Info.Height, bestHeight)
+	}
+
+	switchLock.Lock()
+	defer switchLock.Unlock()
+
+	// the user switched node while the pool was checked
+	if manualSwitches.Load() > 0 || CurrentNode == nil || CurrentNode.Endpoint != currentNode.Endpoint {
+		return
+	}
+
+	for _, h := range health {
+		if !h.Healthy(bestHeight) || h.Node.Endpoint == currentNode.Endpoint {
+			continue
+		}
+
+		err := walletapi.Connect(h.Node.Endpoint)
+		if err != nil {
+			continue
+		}
+
+		// don't save the settings to keep the node selected by the user on restart
+		node := h.Node
+		CurrentNode = &node
+		addSwitchEvent(SwitchEvent{
+			Timestamp: time.Now(),
+			From:      currentNode,
+			To:        node,
+			Reason:    reason,
+		})
+		return
+	}
+}
+
+// StartFailoverMonitor runs the health checks in the background as long as the app is running.
+// Nothing is done if the failover mode is disabled.
+func StartFailoverMonitor() {
+	failoverOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(FAILOVER_CHECK_INTERVAL)
+			for range ticker.C {
+				if isFailoverActive() {
+					checkFailover()
+				}
+			}
+		}()
+	})
+}
@@ -1,7 +1,10 @@
 package node_manager
 
 import (
+	"context"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/deroproject/derohe/walletapi"
 	"github.com/g45t345rt/g45w/app_db"
@@ -11,6 +14,36 @@ import (
 
 var CurrentNode *app_db.NodeConnection
 
+// switchLock is held while the wallet connection and CurrentNode are changed by Set or the failover monitor
+var switchLock sync.Mutex
+
+// number of Set calls in progress, the failover monitor doesn't switch node during a manual switch
+var manualSwitches atomic.Int32
+
+// cancels the integrated node start if the user switches to another node before it's running
+var cancelStart context.CancelFunc
+var cancelStartLock sync.Mutex
+
+func startIntegratedNode() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelStartLock.Lock()
+	cancelStart = cancel
+	cancelStartLock.Unlock()
+
+	defer cancel()
+	return integrated_node.Start(ctx)
+}
+
+func cancelIntegratedNodeStart() {
+	cancelStartLock.Lock()
+	defer cancelStartLock.Unlock()
+
+	if cancelStart != nil {
+		cancelStart()
+		cancelStart = nil
+	}
+}
+
 func Load() error {
 	nodeIdString := settings.App.NodeSelect
 	if nodeIdString != "" {
@@ -38,32 +71,51 @@ func Load() error {
 
 		err = Set(nodeConn, false)
 		if err != nil {
+			if settings.App.NodeFailover {
+				// keep the node assigned and let the failover monitor find a healthy one
+				switchLock.Lock()
+				CurrentNode = nodeConn
+				switchLock.Unlock()
+			}
+
 			return err
 		}
-
-		CurrentNode = nodeConn
 	}
 
 	return nil
 }
 
 func Set(nodeConn *app_db.NodeConnection, save bool) error {
-	if nodeConn != nil {
-		if nodeConn.Integrated {
-			err := integrated_node.Start()
-			if err != nil {
-				return err
-			}
+	manualSwitches.Add(1)
+	defer manualSwitches.Add(-1)
+
+	cancelIntegratedNodeStart()
+
+	// the integrated node start can take a while, don't hold the lock so another Set can cancel it
+	if nodeConn != nil && nodeConn.Integrated {
+		err := startIntegratedNode()
+		if err != nil {
+			return err
 		}
 
+		go verifySnapshot()
+	}
+
+	switchLock.Lock()
+	defer switchLock.Unlock()
+
+	if nodeConn != nil {
 		err := walletapi.Connect(nodeConn.Endpoint)
 		if err != nil {
 			return err
 		}
 
 		settings.App.NodeSelect = strconv.FormatInt(nodeConn.ID, 10)
-		if integrated_node.Running && !nodeConn.Integrated {
-			integrated_node.Stop()
+		if !nodeConn.Integrated {
+			err = integrated_node.Stop()
+			if err != nil {
+				return err
+			}
 		}
 	} else {
 		go func() {
@@ -73,8 +125,9 @@ func Set(nodeConn *app_db.NodeConnection, save bool) error {
 		}()
 
 		settings.App.NodeSelect = ""
-		if integrated_node.Running {
-			integrated_node.Stop()
+		err := integrated_node.Stop()
+		if err != nil {
+			return err
 		}
 	}
 
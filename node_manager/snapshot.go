@@ -0,0 +1,84 @@
+package node_manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/integrated_node"
+)
+
+var SNAPSHOT_VERIFY_INTERVAL = 30 * time.Second
+
+// networkBlockHash asks the trusted nodes for the block hash at the topoheight. They must all agree.
+func networkBlockHash(topoHeight int64) (string, error) {
+	hash := ""
+	for _, node := range app_db.GetTrustedRemoveNodes() {
+		var result rpc.GetBlockHeaderByHeight_Result
+		err := CallNode(node.Endpoint, "DERO.GetBlockHeaderByTopoHeight", rpc.GetBlockHeaderByTopoHeight_Params{
+			TopoHeight: uint64(topoHeight),
+		}, &result, NODE_INFO_TIMEOUT)
+		if err != nil {
+			continue
+		}
+
+		if hash != "" && hash != result.Block_Header.Hash {
+			return "", fmt.Errorf("the trusted nodes returned different blocks at topoheight %d", topoHeight)
+		}
+
+		hash = result.Block_Header.Hash
+	}
+
+	if hash == "" {
+		return "", fmt.Errorf("can't reach the network to verify the snapshot")
+	}
+
+	return hash, nil
+}
+
+// verifySnapshot retries until the network can be reached.
+// The integrated node is stopped if the imported snapshot is not part of the network chain.
+func verifySnapshot() {
+	ctx := integrated_node.Context()
+	for ctx.Err() == nil {
+		err := integrated_node.VerifySnapshot(networkBlockHash)
+		status := integrated_node.GetSnapshotStatus()
+		if status == integrated_node.SnapshotMismatch {
+			if CurrentNode != nil && CurrentNode.Integrated {
+				Set(nil, true)
+			}
+			return
+		}
+
+		if err == nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(SNAPSHOT_VERIFY_INTERVAL):
+		}
+	}
+}
+
+// WithIntegratedNodeStopped stops the integrated node while fn runs because the chain data can't be used by the node at the same time.
+// The node is started again if it's the current node.
+func WithIntegratedNodeStopped(fn func() error) error {
+	err := integrated_node.Stop()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+
+	if CurrentNode != nil && CurrentNode.Integrated {
+		restartErr := Set(CurrentNode, false)
+		if err == nil {
+			err = restartErr
+		}
+	}
+
+	return err
+}
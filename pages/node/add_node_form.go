@@ -33,6 +33,7 @@ type PageAddNodeForm struct {
 	buttonAdd   *components.Button
 	txtEndpoint *prefabs.TextField
 	txtName     *prefabs.TextField
+	poolSwitch  *NodeSwitch
 
 	list *widget.List
 }
@@ -67,6 +68,7 @@ func NewPageAddNodeForm() *PageAddNodeForm {
 		buttonAdd:   buttonAdd,
 		txtName:     txtName,
 		txtEndpoint: txtEndpoint,
+		poolSwitch:  NewNodeSwitch(),
 
 		list: list,
 	}
@@ -105,6 +107,9 @@ func (p *PageAddNodeForm) Layout(gtx layout.Context, th *material.Theme) layout.
 		func(gtx layout.Context) layout.Dimensions {
 			return p.txtEndpoint.Layout(gtx, th, lang.Translate("Endpoint"), "wss://node.deronfts.com/ws")
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.poolSwitch.Layout(gtx, th, lang.Translate("Failover Pool"), lang.Translate("Use this node as a fallback when the failover mode is enabled and the current node is unhealthy."))
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonAdd.Text = lang.Translate("ADD NODE")
 			p.buttonAdd.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -165,6 +170,7 @@ func (p *PageAddNodeForm) submitForm(gtx layout.Context) {
 		err = app_db.InsertNodeConnection(app_db.NodeConnection{
 			Name:     txtName.Text(),
 			Endpoint: txtEndpoint.Text(),
+			Pool:     p.poolSwitch.Value(),
 		})
 		if err != nil {
 			setError(err)
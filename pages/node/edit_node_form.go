@@ -29,6 +29,7 @@ type PageEditNodeForm struct {
 	buttonDelete *components.Button
 	txtEndpoint  *prefabs.TextField
 	txtName      *prefabs.TextField
+	poolSwitch   *NodeSwitch
 	nodeConn     app_db.NodeConnection
 
 	list *widget.List
@@ -77,6 +78,7 @@ func NewPageEditNodeForm() *PageEditNodeForm {
 		buttonDelete: buttonDelete,
 		txtName:      txtName,
 		txtEndpoint:  txtEndpoint,
+		poolSwitch:   NewNodeSwitch(),
 
 		list: list,
 	}
@@ -92,6 +94,7 @@ func (p *PageEditNodeForm) Enter() {
 
 	p.txtEndpoint.SetValue(p.nodeConn.Endpoint)
 	p.txtName.SetValue(p.nodeConn.Name)
+	p.poolSwitch.SetValue(p.nodeConn.Pool)
 }
 
 func (p *PageEditNodeForm) Leave() {
@@ -137,6 +140,9 @@ func (p *PageEditNodeForm) Layout(gtx layout.Context, th *material.Theme) layout
 		func(gtx layout.Context) layout.Dimensions {
 			return p.txtEndpoint.Layout(gtx, th, lang.Translate("Endpoint"), "wss://node.deronfts.com/ws")
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.poolSwitch.Layout(gtx, th, lang.Translate("Failover Pool"), lang.Translate("Use this node as a fallback when the failover mode is enabled and the current node is unhealthy."))
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonEdit.Text = lang.Translate("SAVE")
 			p.buttonEdit.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -222,6 +228,7 @@ func (p *PageEditNodeForm) submitForm(gtx layout.Context) {
 			Name:        txtName.Text(),
 			Endpoint:    txtEndpoint.Text(),
 			OrderNumber: p.nodeConn.OrderNumber,
+			Pool:        p.poolSwitch.Value(),
 		}
 
 		err = app_db.UpdateNodeConnection(node)
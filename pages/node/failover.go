@@ -0,0 +1,106 @@
+package page_node
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/node_manager"
+	"github.com/g45t345rt/g45w/theme"
+)
+
+type NodeSwitch struct {
+	switchBool  *widget.Bool
+	switchValue bool
+}
+
+func NewNodeSwitch() *NodeSwitch {
+	return &NodeSwitch{
+		switchBool: new(widget.Bool),
+	}
+}
+
+func (s *NodeSwitch) SetValue(value bool) {
+	s.switchBool.Value = value
+	s.switchValue = value
+}
+
+func (s *NodeSwitch) Value() bool {
+	return s.switchBool.Value
+}
+
+// Changed returns true once after the user toggled the switch
+func (s *NodeSwitch) Changed() bool {
+	// switch does not have a released func
+	if s.switchBool.Value != s.switchValue {
+		s.switchValue = s.switchBool.Value
+		return true
+	}
+
+	return false
+}
+
+func (s *NodeSwitch) Layout(gtx layout.Context, th *material.Theme, title string, description string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), title)
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			sw := material.Switch(th, s.switchBool, "")
+			sw.Color = theme.Current.SwitchColors
+			return sw.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), description)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func switchEventsLayout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	events := node_manager.GetSwitchEvents()
+
+	var childs []layout.FlexChild
+	childs = append(childs, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(18), lang.Translate("Failover Events"))
+		lbl.Font.Weight = font.Bold
+		return lbl.Layout(gtx)
+	}))
+
+	if len(events) == 0 {
+		childs = append(childs, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("The node was not switched yet."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	for i := range events {
+		event := events[i]
+		childs = append(childs,
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("%s → %s", event.From.Name, event.To.Name)
+				lbl := material.Label(th, unit.Sp(16), text)
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("%s - %s", event.Timestamp.Format("2006-01-02 15:04:05"), event.Reason)
+				lbl := material.Label(th, unit.Sp(14), text)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, childs...)
+}
@@ -2,11 +2,20 @@ package page_node
 
 import (
 	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
 	"time"
 
+	"gioui.org/font"
 	"gioui.org/layout"
+	"gioui.org/text"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
 	"github.com/g45t345rt/g45w/integrated_node"
 	"github.com/g45t345rt/g45w/lang"
 	"github.com/g45t345rt/g45w/prefabs"
@@ -14,16 +23,31 @@ import (
 	"github.com/g45t345rt/g45w/settings"
 	"github.com/g45t345rt/g45w/theme"
 	"github.com/g45t345rt/g45w/utils"
+	"golang.org/x/exp/shiny/materialdesign/icons"
 )
 
 type PageIntegratedNode struct {
 	isActive            bool
 	headerPageAnimation *prefabs.PageHeaderAnimation
 
+	nodeState      integrated_node.StateEvent
 	nodeStatus     integrated_node.NodeStatus
 	nodeStatusLoop *utils.ForceActiveLoop
 	nodeSize       int64
 	nodeSizeLoop   *utils.ForceActiveLoop
+
+	list              *widget.List
+	txtPriorityNodes  *prefabs.TextField
+	txtExclusiveNodes *prefabs.TextField
+	txtMinPeers       *prefabs.TextField
+	txtMaxPeers       *prefabs.TextField
+	txtPruneHistory   *prefabs.TextField
+	txtSocksProxy     *prefabs.TextField
+	txtP2PBind        *prefabs.TextField
+	rpcLanSwitch      *NodeSwitch
+	buttonSave        *components.Button
+	buttonDiagnostics *components.Button
+	nodeSnapshot      *NodeSnapshot
 }
 
 var _ router.Page = &PageIntegratedNode{}
@@ -31,17 +55,69 @@ var _ router.Page = &PageIntegratedNode{}
 func NewPageIntegratedNode() *PageIntegratedNode {
 	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_INTEGRATED_NODE)
 
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	buttonSave := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      saveIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSave.Label.Alignment = text.Middle
+	buttonSave.Style.Font.Weight = font.Bold
+
+	diagnosticsIcon, _ := widget.NewIcon(icons.ActionTimeline)
+	buttonDiagnostics := components.NewButton(components.ButtonStyle{
+		Icon:      diagnosticsIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonDiagnostics.Label.Alignment = text.Middle
+	buttonDiagnostics.Style.Font.Weight = font.Bold
+
 	page := &PageIntegratedNode{
 		headerPageAnimation: headerPageAnimation,
+
+		list:              list,
+		txtPriorityNodes:  prefabs.NewTextField(),
+		txtExclusiveNodes: prefabs.NewTextField(),
+		txtMinPeers:       prefabs.NewNumberTextField(),
+		txtMaxPeers:       prefabs.NewNumberTextField(),
+		txtPruneHistory:   prefabs.NewNumberTextField(),
+		txtSocksProxy:     prefabs.NewTextField(),
+		txtP2PBind:        prefabs.NewTextField(),
+		rpcLanSwitch:      NewNodeSwitch(),
+		buttonSave:        buttonSave,
+		buttonDiagnostics: buttonDiagnostics,
+		nodeSnapshot:      NewNodeSnapshot(),
 	}
 
+	page.nodeState = integrated_node.StateEvent{State: integrated_node.GetState()}
+	stateEvents := integrated_node.Subscribe()
+	go func() {
+		for event := range stateEvents {
+			page.nodeState = event
+			app_instance.Window.Invalidate()
+		}
+	}()
+
 	page.nodeStatusLoop = utils.NewForceActiveLoop(1*time.Second, func() {
 		page.nodeStatus = integrated_node.GetStatus()
 	})
 
 	page.nodeSizeLoop = utils.NewForceActiveLoop(10*time.Second, func() {
-		nodeDir := settings.IntegratedNodeDir
-		size, _ := utils.GetFolderSize(nodeDir)
+		size, _ := integrated_node.GetNodeSize()
 		page.nodeSize = size
 	})
 
@@ -55,23 +131,237 @@ func (p *PageIntegratedNode) IsActive() bool {
 func (p *PageIntegratedNode) Enter() {
 	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
 	page_instance.header.Title = func() string { return lang.Translate("Integrated Node") }
+	p.loadSettings()
 }
 
 func (p *PageIntegratedNode) Leave() {
 	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
 }
 
+func (p *PageIntegratedNode) loadSettings() {
+	config := settings.App.IntegratedNode
+
+	formatInt := func(value int64) string {
+		if value == 0 {
+			return ""
+		}
+
+		return strconv.FormatInt(value, 10)
+	}
+
+	p.txtPriorityNodes.SetValue(strings.Join(config.PriorityNodes, ", "))
+	p.txtExclusiveNodes.SetValue(strings.Join(config.ExclusiveNodes, ", "))
+	p.txtMinPeers.SetValue(formatInt(int64(config.MinPeers)))
+	p.txtMaxPeers.SetValue(formatInt(int64(config.MaxPeers)))
+	p.txtPruneHistory.SetValue(formatInt(config.PruneHistory))
+	p.txtSocksProxy.SetValue(config.SocksProxy)
+	p.txtP2PBind.SetValue(config.P2PBind)
+	p.rpcLanSwitch.SetValue(config.RPCLan)
+}
+
+// nodes can be separated by commas or spaces
+func parseNodeList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n'
+	})
+}
+
+func parseOptionalInt(value string, name string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return number, nil
+}
+
+func (p *PageIntegratedNode) submitForm() error {
+	config := settings.App.IntegratedNode
+	config.PriorityNodes = parseNodeList(p.txtPriorityNodes.Value())
+	config.ExclusiveNodes = parseNodeList(p.txtExclusiveNodes.Value())
+	config.SocksProxy = strings.TrimSpace(p.txtSocksProxy.Value())
+	config.P2PBind = strings.TrimSpace(p.txtP2PBind.Value())
+	config.RPCLan = p.rpcLanSwitch.Value()
+
+	minPeers, err := parseOptionalInt(p.txtMinPeers.Value(), "min peers")
+	if err != nil {
+		return err
+	}
+
+	maxPeers, err := parseOptionalInt(p.txtMaxPeers.Value(), "max peers")
+	if err != nil {
+		return err
+	}
+
+	pruneHistory, err := parseOptionalInt(p.txtPruneHistory.Value(), "prune history")
+	if err != nil {
+		return err
+	}
+
+	config.MinPeers = int(minPeers)
+	config.MaxPeers = int(maxPeers)
+	config.PruneHistory = pruneHistory
+
+	err = integrated_node.ValidateSettings(config)
+	if err != nil {
+		return err
+	}
+
+	settings.App.IntegratedNode = config
+	return settings.Save()
+}
+
 func (p *PageIntegratedNode) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
 
 	p.nodeStatusLoop.SetActive()
 	p.nodeSizeLoop.SetActive()
 
-	return layout.Inset{
-		Top: unit.Dp(0), Bottom: unit.Dp(30),
-		Left: theme.PagePadding, Right: theme.PagePadding,
-	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+	if p.buttonDiagnostics.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_NODE_DIAGNOSTICS)
+		page_instance.header.AddHistory(PAGE_NODE_DIAGNOSTICS)
+	}
+
+	if p.buttonSave.Clicked(gtx) {
+		go func() {
+			err := p.submitForm()
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("The settings will be applied the next time the node starts."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		p.layoutStatus(th),
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonDiagnostics.Text = lang.Translate("Diagnostics")
+			p.buttonDiagnostics.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonDiagnostics.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.nodeSnapshot.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Node Settings"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtPriorityNodes, lang.Translate("Priority Nodes"), "1.2.3.4:18089, 5.6.7.8:18089",
+				lang.Translate("The node always tries to stay connected to these peers."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtExclusiveNodes, lang.Translate("Exclusive Nodes"), "1.2.3.4:18089",
+				lang.Translate("If set, the node only connects to these peers."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtMinPeers, lang.Translate("Min Peers"), "", "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtMaxPeers, lang.Translate("Max Peers"), "", "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtPruneHistory, lang.Translate("Prune History"), "",
+				lang.Translate("Number of blocks to keep. The older blocks are removed at start to save disk space. Leave empty to keep everything."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtSocksProxy, lang.Translate("SOCKS Proxy"), "127.0.0.1:9050",
+				lang.Translate("Peer connections use UDP and can't go through the proxy. The node will only have incoming peers when a proxy is set."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutField(gtx, th, p.txtP2PBind, lang.Translate("P2P Bind Address"), "0.0.0.0:18089", "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.rpcLanSwitch.Layout(gtx, th, lang.Translate("Expose RPC on LAN"),
+				lang.Translate("Other devices on your local network can connect to the node. Don't enable this on public networks."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSave.Text = lang.Translate("SAVE")
+			p.buttonSave.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSave.Layout(gtx, th)
+		},
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageIntegratedNode) layoutField(gtx layout.Context, th *material.Theme, txt *prefabs.TextField, title string, hint string, description string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return txt.Layout(gtx, th, title, hint)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if description == "" {
+				return layout.Dimensions{}
+			}
+
+			return layout.Inset{Top: unit.Dp(3)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), description)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}),
+	)
+}
+
+func (p *PageIntegratedNode) layoutStatus(th *material.Theme) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Label(th, unit.Sp(18), lang.Translate("State"))
+				label.Color = theme.Current.TextMuteColor
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				var status string
+				switch p.nodeState.State {
+				case integrated_node.StateStarting:
+					status = lang.Translate("Starting...")
+				case integrated_node.StateRunning:
+					status = lang.Translate("Running")
+				case integrated_node.StateStopping:
+					status = lang.Translate("Stopping...")
+				default:
+					status = lang.Translate("Stopped")
+				}
+
+				label := material.Label(th, unit.Sp(22), status)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if p.nodeState.Err == nil {
+					return layout.Dimensions{}
+				}
+
+				label := material.Label(th, unit.Sp(14), p.nodeState.Err.Error())
+				label.Color = theme.Current.NodeStatusDotRedColor
+				return label.Layout(gtx)
+			}),
+
 			layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				label := material.Label(th, unit.Sp(18), lang.Translate("Node Height / Network Height"))
@@ -139,5 +429,5 @@ func (p *PageIntegratedNode) Layout(gtx layout.Context, th *material.Theme) layo
 				return label.Layout(gtx)
 			}),
 		)
-	})
+	}
 }
@@ -0,0 +1,347 @@
+package page_node
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/globals"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/integrated_node"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+const PEER_BAN_DURATION = 24 * time.Hour
+
+type PageNodeDiagnostics struct {
+	isActive            bool
+	headerPageAnimation *prefabs.PageHeaderAnimation
+
+	list            *widget.List
+	tabBars         *components.TabBars
+	buttonFlushPool *components.Button
+	diagnosticsLoop *utils.ForceActiveLoop
+
+	peers      []integrated_node.PeerInfo
+	peerItems  []*PeerListItem
+	mempoolTxs []integrated_node.PoolTx
+	regpoolTxs []integrated_node.PoolTx
+	nodeSize   int64
+}
+
+var _ router.Page = &PageNodeDiagnostics{}
+
+func NewPageNodeDiagnostics() *PageNodeDiagnostics {
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	tabBars := components.NewTabBars("peers", []*components.TabBarsItem{
+		components.NewTabBarItem("peers"),
+		components.NewTabBarItem("mempool"),
+		components.NewTabBarItem("regpool"),
+	})
+
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+	buttonFlushPool := components.NewButton(components.ButtonStyle{
+		Icon:      deleteIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonFlushPool.Label.Alignment = text.Middle
+	buttonFlushPool.Style.Font.Weight = font.Bold
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_NODE_DIAGNOSTICS)
+	page := &PageNodeDiagnostics{
+		headerPageAnimation: headerPageAnimation,
+		list:                list,
+		tabBars:             tabBars,
+		buttonFlushPool:     buttonFlushPool,
+	}
+
+	page.diagnosticsLoop = utils.NewForceActiveLoop(3*time.Second, page.refresh)
+	return page
+}
+
+func (p *PageNodeDiagnostics) refresh() {
+	p.peers = integrated_node.GetPeers()
+	p.mempoolTxs = integrated_node.GetMempoolTxs()
+	p.regpoolTxs = integrated_node.GetRegpoolTxs()
+	p.nodeSize, _ = integrated_node.GetNodeSize()
+
+	// keep the ban buttons of the peers that are still connected
+	items := make(map[string]*PeerListItem)
+	for _, item := range p.peerItems {
+		items[item.peer.Address] = item
+	}
+
+	var peerItems []*PeerListItem
+	for _, peer := range p.peers {
+		item, ok := items[peer.Address]
+		if !ok {
+			item = NewPeerListItem()
+		}
+
+		item.peer = peer
+		peerItems = append(peerItems, item)
+	}
+
+	p.peerItems = peerItems
+}
+
+func (p *PageNodeDiagnostics) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageNodeDiagnostics) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+	page_instance.header.Title = func() string { return lang.Translate("Node Diagnostics") }
+	go p.refresh()
+}
+
+func (p *PageNodeDiagnostics) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageNodeDiagnostics) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	p.diagnosticsLoop.SetActive()
+
+	if p.buttonFlushPool.Clicked(gtx) {
+		go func() {
+			yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+				Prompt: lang.Translate("Are you sure you want to remove all the registration txs from the regpool?"),
+			})
+
+			if yes {
+				integrated_node.FlushRegpool()
+				p.refresh()
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Regpool flushed."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(th, unit.Sp(18), lang.Translate("Space Used"))
+					label.Color = theme.Current.TextMuteColor
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(th, unit.Sp(22), utils.FormatBytes(p.nodeSize))
+					return label.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			text := make(map[string]string)
+			text["peers"] = fmt.Sprintf("%s (%d)", lang.Translate("Peers"), len(p.peers))
+			text["mempool"] = fmt.Sprintf("%s (%d)", lang.Translate("Mempool"), len(p.mempoolTxs))
+			text["regpool"] = fmt.Sprintf("%s (%d)", lang.Translate("Regpool"), len(p.regpoolTxs))
+			p.tabBars.Colors = theme.Current.TabBarsColors
+			return p.tabBars.Layout(gtx, th, unit.Sp(16), text)
+		},
+	}
+
+	emptyText := func(txt string) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), txt)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}
+	}
+
+	switch p.tabBars.Key {
+	case "peers":
+		if len(p.peerItems) == 0 {
+			widgets = append(widgets, emptyText(lang.Translate("The node is not connected to any peer.")))
+		}
+
+		for i := range p.peerItems {
+			item := p.peerItems[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
+	case "mempool":
+		if len(p.mempoolTxs) == 0 {
+			widgets = append(widgets, emptyText(lang.Translate("The mempool is empty.")))
+		}
+
+		for i := range p.mempoolTxs {
+			tx := p.mempoolTxs[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return poolTxLayout(gtx, th, tx)
+			})
+		}
+	case "regpool":
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonFlushPool.Text = lang.Translate("Flush Regpool")
+			p.buttonFlushPool.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonFlushPool.Layout(gtx, th)
+		})
+
+		if len(p.regpoolTxs) == 0 {
+			widgets = append(widgets, emptyText(lang.Translate("The regpool is empty.")))
+		}
+
+		for i := range p.regpoolTxs {
+			tx := p.regpoolTxs[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return poolTxLayout(gtx, th, tx)
+			})
+		}
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func poolTxLayout(gtx layout.Context, th *material.Theme, tx integrated_node.PoolTx) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(tx.TXID.String()))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			txt := fmt.Sprintf("%s: %s", lang.Translate("Size"), utils.FormatBytes(int64(tx.Size)))
+			if tx.Fees > 0 {
+				txt = fmt.Sprintf("%s | %s: %s", txt, lang.Translate("Fee"), globals.FormatMoney(tx.Fees))
+			}
+
+			lbl := material.Label(th, unit.Sp(14), txt)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+type PeerListItem struct {
+	peer      integrated_node.PeerInfo
+	buttonBan *components.Button
+}
+
+func NewPeerListItem() *PeerListItem {
+	banIcon, _ := widget.NewIcon(icons.ContentBlock)
+	buttonBan := components.NewButton(components.ButtonStyle{
+		Icon:      banIcon,
+		Animation: components.NewButtonAnimationScale(.95),
+	})
+
+	return &PeerListItem{
+		buttonBan: buttonBan,
+	}
+}
+
+func (item *PeerListItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	peer := item.peer
+
+	if item.buttonBan.Clicked(gtx) {
+		go func() {
+			prompt := lang.Translate("Are you sure you want to ban {} for 24 hours?")
+			yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+				Prompt: strings.Replace(prompt, "{}", peer.Address, -1),
+			})
+
+			if !yes {
+				return
+			}
+
+			err := integrated_node.BanPeer(peer.Address, PEER_BAN_DURATION)
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Peer banned."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+			}
+		}()
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					direction := lang.Translate("Out")
+					if peer.Incoming {
+						direction = lang.Translate("In")
+					}
+
+					lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("%s (%s)", peer.Address, direction))
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txt := fmt.Sprintf("%s: %d | %s: %s | %s",
+						lang.Translate("Height"), peer.Height,
+						lang.Translate("Latency"), peer.Latency.String(),
+						peer.Connected.String(),
+					)
+
+					lbl := material.Label(th, unit.Sp(14), txt)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txt := peer.Version
+					if peer.Tag != "" {
+						txt = fmt.Sprintf("%s | %s", peer.Tag, txt)
+					}
+
+					lbl := material.Label(th, unit.Sp(14), txt)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(30)
+			gtx.Constraints.Min.Y = gtx.Dp(30)
+			item.buttonBan.Style.Colors = theme.Current.ModalButtonColors
+			return item.buttonBan.Layout(gtx, th)
+		}),
+	)
+}
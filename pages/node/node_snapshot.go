@@ -0,0 +1,189 @@
+package page_node
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/integrated_node"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/node_manager"
+	"github.com/g45t345rt/g45w/theme"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type NodeSnapshot struct {
+	buttonExport *components.Button
+	buttonImport *components.Button
+}
+
+func NewNodeSnapshot() *NodeSnapshot {
+	newButton := func(icon *widget.Icon) *components.Button {
+		loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+		button := components.NewButton(components.ButtonStyle{
+			Icon:        icon,
+			TextSize:    unit.Sp(16),
+			IconGap:     unit.Dp(10),
+			Inset:       layout.UniformInset(unit.Dp(10)),
+			Animation:   components.NewButtonAnimationDefault(),
+			LoadingIcon: loadingIcon,
+			Border: widget.Border{
+				Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+				Width:        unit.Dp(2),
+				CornerRadius: unit.Dp(5),
+			},
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	exportIcon, _ := widget.NewIcon(icons.FileFileUpload)
+	importIcon, _ := widget.NewIcon(icons.FileFileDownload)
+
+	return &NodeSnapshot{
+		buttonExport: newButton(exportIcon),
+		buttonImport: newButton(importIcon),
+	}
+}
+
+func (n *NodeSnapshot) exportSnapshot() error {
+	yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Title:  lang.Translate("Export snapshot?"),
+		Prompt: lang.Translate("The node will be stopped during the export and started again after."),
+	})
+	if !yes {
+		return nil
+	}
+
+	name := fmt.Sprintf("node_snapshot_%s%s", time.Now().Format("2006-01-02"), integrated_node.SNAPSHOT_FILE_EXTENSION)
+	file, err := app_instance.Explorer.CreateFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = node_manager.WithIntegratedNodeStopped(func() error {
+		return integrated_node.ExportSnapshot(file)
+	})
+	if err != nil {
+		return err
+	}
+
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       lang.Translate("Snapshot exported."),
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+	return nil
+}
+
+func (n *NodeSnapshot) importSnapshot() error {
+	yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Title:  lang.Translate("Import snapshot?"),
+		Prompt: lang.Translate("The current blockchain data will be replaced. The top block of the snapshot is verified with the network once the node is connected."),
+	})
+	if !yes {
+		return nil
+	}
+
+	file, err := app_instance.Explorer.ChooseFile()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var manifest integrated_node.SnapshotManifest
+	err = node_manager.WithIntegratedNodeStopped(func() (err error) {
+		manifest, err = integrated_node.ImportSnapshot(file)
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	text := lang.Translate("Snapshot imported at height {}.")
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       strings.Replace(text, "{}", fmt.Sprint(manifest.Height), -1),
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+	return nil
+}
+
+func (n *NodeSnapshot) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	runAction := func(button *components.Button, action func() error) {
+		button.SetLoading(true)
+		go func() {
+			err := action()
+			button.SetLoading(false)
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			}
+		}()
+	}
+
+	if n.buttonExport.Clicked(gtx) {
+		runAction(n.buttonExport, n.exportSnapshot)
+	}
+
+	if n.buttonImport.Clicked(gtx) {
+		runAction(n.buttonImport, n.importSnapshot)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Snapshot"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			var txt string
+			lbl := material.Label(th, unit.Sp(14), "")
+			lbl.Color = theme.Current.TextMuteColor
+
+			switch integrated_node.GetSnapshotStatus() {
+			case integrated_node.SnapshotPending:
+				txt = lang.Translate("Waiting for the network to verify the imported snapshot.")
+			case integrated_node.SnapshotVerified:
+				txt = lang.Translate("The imported snapshot matches the network.")
+			case integrated_node.SnapshotMismatch:
+				txt = lang.Translate("The imported snapshot does not match the network and the node was stopped. Import another snapshot to use the integrated node.")
+				lbl.Color = theme.Current.NodeStatusDotRedColor
+			default:
+				txt = lang.Translate("Import the blockchain data from a file instead of downloading it from the network.")
+			}
+
+			lbl.Text = txt
+			return layout.Inset{Top: unit.Dp(3), Bottom: unit.Dp(10)}.Layout(gtx, lbl.Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			n.buttonImport.Text = lang.Translate("Import Snapshot")
+			n.buttonImport.Style.Colors = theme.Current.ButtonSecondaryColors
+			return n.buttonImport.Layout(gtx, th)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			n.buttonExport.Text = lang.Translate("Export Snapshot")
+			n.buttonExport.Style.Colors = theme.Current.ButtonSecondaryColors
+			return n.buttonExport.Layout(gtx, th)
+		}),
+	)
+}
@@ -24,6 +24,7 @@ type Page struct {
 	pageEditNodeForm   *PageEditNodeForm
 	pageRemoteNode     *PageRemoteNode
 	pageIntegratedNode *PageIntegratedNode
+	pageDiagnostics    *PageNodeDiagnostics
 	header             *prefabs.Header
 
 	pageSectionAnimation *pages.PageSectionAnimation
@@ -34,11 +35,12 @@ var _ router.Page = &Page{}
 var page_instance *Page
 
 const (
-	PAGE_SELECT_NODE     = "page_select_node"
-	PAGE_ADD_NODE_FORM   = "page_add_node_form"
-	PAGE_EDIT_NODE_FORM  = "page_edit_node_form"
-	PAGE_INTEGRATED_NODE = "page_integrated_node"
-	PAGE_REMOTE_NODE     = "page_remote_node"
+	PAGE_SELECT_NODE      = "page_select_node"
+	PAGE_ADD_NODE_FORM    = "page_add_node_form"
+	PAGE_EDIT_NODE_FORM   = "page_edit_node_form"
+	PAGE_INTEGRATED_NODE  = "page_integrated_node"
+	PAGE_REMOTE_NODE      = "page_remote_node"
+	PAGE_NODE_DIAGNOSTICS = "page_node_diagnostics"
 )
 
 func New() *Page {
@@ -58,6 +60,9 @@ func New() *Page {
 	pageRemoteNode := NewPageRemoteNode()
 	pageRouter.Add(PAGE_REMOTE_NODE, pageRemoteNode)
 
+	pageDiagnostics := NewPageNodeDiagnostics()
+	pageRouter.Add(PAGE_NODE_DIAGNOSTICS, pageDiagnostics)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
@@ -67,6 +72,7 @@ func New() *Page {
 		pageEditNodeForm:   pageEditNodeForm,
 		pageRemoteNode:     pageRemoteNode,
 		pageIntegratedNode: pageIntegratedNode,
+		pageDiagnostics:    pageDiagnostics,
 		header:             header,
 
 		pageSectionAnimation: pages.NewPageSectionAnimation(),
@@ -23,6 +23,7 @@ import (
 	"github.com/g45t345rt/g45w/node_manager"
 	"github.com/g45t345rt/g45w/prefabs"
 	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/settings"
 	"github.com/g45t345rt/g45w/theme"
 	"github.com/g45t345rt/g45w/utils"
 	"golang.org/x/exp/shiny/materialdesign/icons"
@@ -286,6 +287,12 @@ func (p *PageRemoteNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 		})
 	}
 
+	if settings.App.NodeFailover {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return switchEventsLayout(gtx, th)
+		})
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
 	})
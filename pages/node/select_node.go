@@ -1,7 +1,11 @@
 package page_node
 
 import (
+	"fmt"
 	"image"
+	"image/color"
+	"sort"
+	"time"
 
 	"gioui.org/font"
 	"gioui.org/io/pointer"
@@ -17,11 +21,13 @@ import (
 	"github.com/g45t345rt/g45w/app_instance"
 	"github.com/g45t345rt/g45w/components"
 	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/node_status_bar"
 	"github.com/g45t345rt/g45w/containers/notification_modal"
 	"github.com/g45t345rt/g45w/lang"
 	"github.com/g45t345rt/g45w/node_manager"
 	"github.com/g45t345rt/g45w/prefabs"
 	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/settings"
 	"github.com/g45t345rt/g45w/theme"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 )
@@ -33,6 +39,10 @@ type PageSelectNode struct {
 	buttonUseLocalNode      *components.Button
 	buttonAddNode           *components.Button
 	buttonResetNodeList     *components.Button
+	buttonBenchmark         *components.Button
+	buttonSortHealth        *components.Button
+	failoverSwitch          *NodeSwitch
+	failoverTrustedSwitch   *NodeSwitch
 	connecting              bool
 
 	nodeList *NodeList
@@ -77,6 +87,20 @@ func NewPageSelectNode() *PageSelectNode {
 		Animation: components.NewButtonAnimationScale(.92),
 	})
 
+	benchmarkIcon, _ := widget.NewIcon(icons.ImageTimer)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonBenchmark := components.NewButton(components.ButtonStyle{
+		Icon:        benchmarkIcon,
+		LoadingIcon: loadingIcon,
+		Animation:   components.NewButtonAnimationScale(.92),
+	})
+
+	sortIcon, _ := widget.NewIcon(icons.ContentSort)
+	buttonSortHealth := components.NewButton(components.ButtonStyle{
+		Icon:      sortIcon,
+		Animation: components.NewButtonAnimationScale(.92),
+	})
+
 	resetIcon, _ := widget.NewIcon(icons.NavigationRefresh)
 	buttonResetNodeList := components.NewButton(components.ButtonStyle{
 		Rounded:   components.UniformRounded(unit.Dp(5)),
@@ -98,6 +122,10 @@ func NewPageSelectNode() *PageSelectNode {
 		buttonUseLocalNode:      buttonUseLocalNode,
 		buttonAddNode:           buttonAddNode,
 		buttonResetNodeList:     buttonResetNodeList,
+		buttonBenchmark:         buttonBenchmark,
+		buttonSortHealth:        buttonSortHealth,
+		failoverSwitch:          NewNodeSwitch(),
+		failoverTrustedSwitch:   NewNodeSwitch(),
 	}
 }
 
@@ -109,6 +137,8 @@ func (p *PageSelectNode) Enter() {
 	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
 	page_instance.header.Title = func() string { return lang.Translate("Select Node") }
 	p.nodeList.Load()
+	p.failoverSwitch.SetValue(settings.App.NodeFailover)
+	p.failoverTrustedSwitch.SetValue(settings.App.NodeFailoverTrusted)
 }
 
 func (p *PageSelectNode) Leave() {
@@ -157,6 +187,23 @@ func (p *PageSelectNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 							lbl.Font.Weight = font.Bold
 							return lbl.Layout(gtx)
 						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Min.X = gtx.Dp(35)
+							gtx.Constraints.Min.Y = gtx.Dp(35)
+							p.buttonSortHealth.Style.Colors = theme.Current.ButtonIconPrimaryColors
+							if p.nodeList.sortByHealth {
+								p.buttonSortHealth.Style.Colors.TextColor = theme.Current.NodeStatusDotGreenColor
+							}
+							return p.buttonSortHealth.Layout(gtx, th)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Min.X = gtx.Dp(35)
+							gtx.Constraints.Min.Y = gtx.Dp(35)
+							p.buttonBenchmark.Style.Colors = theme.Current.ButtonIconPrimaryColors
+							return p.buttonBenchmark.Layout(gtx, th)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							gtx.Constraints.Min.X = gtx.Dp(35)
 							gtx.Constraints.Min.Y = gtx.Dp(35)
@@ -174,6 +221,18 @@ func (p *PageSelectNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 		func(gtx layout.Context) layout.Dimensions {
 			return layout.Spacer{Height: unit.Dp(20)}.Layout(gtx)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.failoverSwitch.Layout(gtx, th, lang.Translate("Failover Mode"), lang.Translate("Check the health of the nodes in the failover pool and automatically switch to the best one if the current remote node is down or out of sync."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Spacer{Height: unit.Dp(20)}.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.failoverTrustedSwitch.Layout(gtx, th, lang.Translate("Include Trusted Nodes"), lang.Translate("Add the default trusted remote nodes to the failover pool."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Spacer{Height: unit.Dp(20)}.Layout(gtx)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonResetNodeList.Text = lang.Translate("Reset node list")
 			p.buttonResetNodeList.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -184,6 +243,53 @@ func (p *PageSelectNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 		},
 	}
 
+	if p.buttonBenchmark.Clicked(gtx) {
+		go func() {
+			p.buttonBenchmark.SetLoading(true)
+			err := node_manager.BenchmarkNodes()
+			p.buttonBenchmark.SetLoading(false)
+			if err == nil {
+				err = p.nodeList.Load()
+			}
+
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Benchmark completed."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonSortHealth.Clicked(gtx) {
+		p.nodeList.sortByHealth = !p.nodeList.sortByHealth
+		p.nodeList.Load()
+	}
+
+	if p.failoverSwitch.Changed() || p.failoverTrustedSwitch.Changed() {
+		settings.App.NodeFailover = p.failoverSwitch.Value()
+		settings.App.NodeFailoverTrusted = p.failoverTrustedSwitch.Value()
+		go func() {
+			err := settings.Save()
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			}
+		}()
+	}
+
 	if p.buttonResetNodeList.Clicked(gtx) {
 		go func() {
 			yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{})
@@ -302,8 +408,9 @@ func (p *PageSelectNode) selectNode(nodeConn app_db.NodeConnection) {
 }
 
 type NodeList struct {
-	items []NodeListItem
-	list  *widget.List
+	items        []NodeListItem
+	list         *widget.List
+	sortByHealth bool
 
 	dragItems *components.DragItems
 }
@@ -327,6 +434,12 @@ func (l *NodeList) Load() error {
 		return err
 	}
 
+	if l.sortByHealth {
+		sort.SliceStable(nodeConnections, func(i, j int) bool {
+			return nodeConnections[i].HealthScore() > nodeConnections[j].HealthScore()
+		})
+	}
+
 	for _, nodeConn := range nodeConnections {
 		items = append(items,
 			NewNodeListItem(nodeConn),
@@ -340,7 +453,8 @@ func (l *NodeList) Load() error {
 func (l *NodeList) Layout(gtx layout.Context, th *material.Theme, emptyText string) layout.Dimensions {
 	{
 		moved, cIndex, nIndex := l.dragItems.ItemMoved()
-		if moved {
+		// the displayed order is not the stored order when sorted by health
+		if moved && !l.sortByHealth {
 			go func() {
 				updateIndex := func() error {
 					node := l.items[cIndex].conn
@@ -459,9 +573,22 @@ func (item *NodeListItem) Layout(gtx layout.Context, th *material.Theme, fill bo
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							lbl := material.Label(th, unit.Sp(18), item.conn.Name)
-							lbl.Font.Weight = font.Bold
-							return lbl.Layout(gtx)
+							return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									if !item.conn.Status.Valid {
+										return layout.Dimensions{}
+									}
+
+									return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+										return node_status_bar.StatusDot{Color: nodeStatusColor(item.conn.Status.String)}.Layout(gtx)
+									})
+								}),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									lbl := material.Label(th, unit.Sp(18), item.conn.Name)
+									lbl.Font.Weight = font.Bold
+									return lbl.Layout(gtx)
+								}),
+							)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
@@ -472,6 +599,18 @@ func (item *NodeListItem) Layout(gtx layout.Context, th *material.Theme, fill bo
 								}),
 							)
 						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return item.layoutBenchmark(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !item.conn.Pool {
+								return layout.Dimensions{}
+							}
+
+							lbl := material.Label(th, unit.Sp(14), lang.Translate("Failover Pool"))
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
 					)
 				}),
 			)
@@ -498,3 +637,58 @@ func (item *NodeListItem) Layout(gtx layout.Context, th *material.Theme, fill bo
 		return dims
 	})
 }
+
+func nodeStatusColor(status string) color.NRGBA {
+	switch status {
+	case app_db.NODE_STATUS_OK:
+		return theme.Current.NodeStatusDotGreenColor
+	case app_db.NODE_STATUS_STALE:
+		return theme.Current.NodeStatusDotYellowColor
+	}
+
+	return theme.Current.NodeStatusDotRedColor
+}
+
+func nodeStatusText(status string) string {
+	switch status {
+	case app_db.NODE_STATUS_OK:
+		return lang.Translate("Healthy")
+	case app_db.NODE_STATUS_STALE:
+		return lang.Translate("Stale")
+	case app_db.NODE_STATUS_AHEAD:
+		return lang.Translate("Height ahead of the network")
+	case app_db.NODE_STATUS_WRONG_NETWORK:
+		return lang.Translate("Wrong network")
+	case app_db.NODE_STATUS_UNREACHABLE:
+		return lang.Translate("Unreachable")
+	}
+
+	return status
+}
+
+func (item *NodeListItem) layoutBenchmark(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	conn := item.conn
+	if !conn.Status.Valid {
+		return layout.Dimensions{}
+	}
+
+	info := nodeStatusText(conn.Status.String)
+	if conn.Latency.Valid {
+		info += fmt.Sprintf(" - %d ms / %d ms", conn.ConnectTime.Int64, conn.Latency.Int64)
+	}
+
+	if conn.Height.Valid {
+		info += fmt.Sprintf(" - %d / %d", conn.Height.Int64, conn.BestHeight.Int64)
+	}
+
+	if conn.Version.Valid {
+		info += fmt.Sprintf(" - %s", conn.Version.String)
+	}
+
+	checkedAt := time.Unix(conn.CheckedAt.Int64, 0)
+	info += fmt.Sprintf(" (%s)", lang.TimeAgo(checkedAt))
+
+	lbl := material.Label(th, unit.Sp(14), info)
+	lbl.Color = theme.Current.TextMuteColor
+	return lbl.Layout(gtx)
+}
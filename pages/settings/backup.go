@@ -0,0 +1,380 @@
+package page_settings
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_backup"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageBackup struct {
+	isActive            bool
+	headerPageAnimation *prefabs.PageHeaderAnimation
+
+	walletItems           []*BackupWalletItem
+	txtPassword           *prefabs.TextField
+	txtConfirmPassword    *prefabs.TextField
+	txtImportPassword     *prefabs.TextField
+	buttonExport          *components.Button
+	buttonImport          *components.Button
+	buttonSelectAll       *components.Button
+	buttonSelectAllActive bool
+
+	list *widget.List
+}
+
+var _ router.Page = &PageBackup{}
+
+func NewPageBackup() *PageBackup {
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	newButton := func(icon *widget.Icon) *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:     components.UniformRounded(unit.Dp(5)),
+			Icon:        icon,
+			TextSize:    unit.Sp(14),
+			IconGap:     unit.Dp(10),
+			Inset:       layout.UniformInset(unit.Dp(10)),
+			LoadingIcon: loadingIcon,
+			Animation:   components.NewButtonAnimationDefault(),
+			Border: widget.Border{
+				Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+				Width:        unit.Dp(2),
+				CornerRadius: unit.Dp(5),
+			},
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	exportIcon, _ := widget.NewIcon(icons.FileFileUpload)
+	importIcon, _ := widget.NewIcon(icons.FileFileDownload)
+	selectAllIcon, _ := widget.NewIcon(icons.ToggleCheckBox)
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_BACKUP)
+	return &PageBackup{
+		headerPageAnimation: headerPageAnimation,
+		txtPassword:         prefabs.NewPasswordTextField(),
+		txtConfirmPassword:  prefabs.NewPasswordTextField(),
+		txtImportPassword:   prefabs.NewPasswordTextField(),
+		buttonExport:        newButton(exportIcon),
+		buttonImport:        newButton(importIcon),
+		buttonSelectAll:     newButton(selectAllIcon),
+		list:                list,
+	}
+}
+
+func (p *PageBackup) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageBackup) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string { return lang.Translate("Backup & Restore") }
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+
+	p.Load()
+}
+
+func (p *PageBackup) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageBackup) Load() {
+	wallets, _ := app_db.GetWallets()
+
+	items := []*BackupWalletItem{}
+	for _, walletInfo := range wallets {
+		items = append(items, NewBackupWalletItem(walletInfo))
+	}
+
+	p.walletItems = items
+	p.buttonSelectAllActive = false
+}
+
+func (p *PageBackup) showError(err error) {
+	notification_modal.Open(notification_modal.Params{
+		Type:  notification_modal.ERROR,
+		Title: lang.Translate("Error"),
+		Text:  err.Error(),
+	})
+}
+
+func (p *PageBackup) exportBackup() error {
+	var addrs []string
+	for _, item := range p.walletItems {
+		if item.switchSelect.Value {
+			addrs = append(addrs, item.walletInfo.Addr)
+		}
+	}
+
+	password := p.txtPassword.Value()
+	if password == "" {
+		return fmt.Errorf(lang.Translate("Password cannot be empty."))
+	}
+
+	if password != p.txtConfirmPassword.Value() {
+		return fmt.Errorf(lang.Translate("The confirm password does not match."))
+	}
+
+	data, err := app_backup.Export(addrs, password)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("g45w_backup_%s%s", time.Now().Format("20060102"), app_backup.FILE_EXTENSION)
+	file, err := app_instance.Explorer.CreateFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+
+	p.txtPassword.SetValue("")
+	p.txtConfirmPassword.SetValue("")
+	return nil
+}
+
+func (p *PageBackup) importBackup() (*app_backup.ImportResult, error) {
+	password := p.txtImportPassword.Value()
+	if password == "" {
+		return nil, fmt.Errorf(lang.Translate("Password cannot be empty."))
+	}
+
+	file, err := app_instance.Explorer.ChooseFile()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := app_backup.ReadManifest(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Unix(manifest.Timestamp, 0).Format("2006-01-02 15:04")
+	prompt := fmt.Sprintf(lang.Translate("Backup from %s with %d wallets, %d nodes and %d IPFS gateways. Existing wallets and endpoints are kept."),
+		date, len(manifest.Wallets), len(manifest.Nodes), len(manifest.IPFSGateways))
+
+	yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Title:  lang.Translate("Restore backup?"),
+		Prompt: prompt,
+	})
+	if !yes {
+		return nil, nil
+	}
+
+	result, err := app_backup.Import(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	p.txtImportPassword.SetValue("")
+	p.Load()
+	return &result, nil
+}
+
+func (p *PageBackup) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonSelectAll.Clicked(gtx) {
+		p.buttonSelectAllActive = !p.buttonSelectAllActive
+		for _, item := range p.walletItems {
+			item.switchSelect.Value = p.buttonSelectAllActive
+		}
+	}
+
+	if p.buttonExport.Clicked(gtx) {
+		go func() {
+			p.buttonExport.SetLoading(true)
+			err := p.exportBackup()
+			p.buttonExport.SetLoading(false)
+			if err != nil {
+				p.showError(err)
+			} else {
+				notification_modal.Open(notification_modal.Params{
+					Type:       notification_modal.SUCCESS,
+					Title:      lang.Translate("Success"),
+					Text:       lang.Translate("Backup exported."),
+					CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+				})
+			}
+		}()
+	}
+
+	if p.buttonImport.Clicked(gtx) {
+		go func() {
+			p.buttonImport.SetLoading(true)
+			result, err := p.importBackup()
+			p.buttonImport.SetLoading(false)
+			if err != nil {
+				p.showError(err)
+			} else if result != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.SUCCESS,
+					Title: lang.Translate("Success"),
+					Text: fmt.Sprintf(lang.Translate("Restored %d wallets (%d already present), %d nodes and %d IPFS gateways."),
+						result.Wallets, result.SkippedWallets, result.Nodes, result.IPFSGateways),
+				})
+			}
+
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	sectionTitle := func(gtx layout.Context, title string, description string) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), title)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), description)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Save your wallets, tokens, contacts, transactions, nodes and IPFS gateways in a single password protected file."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return sectionTitle(gtx, lang.Translate("Export"), lang.Translate("Select the wallets to include. Node connections and IPFS gateways are always included."))
+		},
+	}
+
+	if len(p.walletItems) > 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonSelectAll.Text = lang.Translate("SELECT ALL")
+			if p.buttonSelectAllActive {
+				p.buttonSelectAll.Text = lang.Translate("UNSELECT ALL")
+			}
+			p.buttonSelectAll.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonSelectAll.Layout(gtx, th)
+		})
+	}
+
+	for i := range p.walletItems {
+		item := p.walletItems[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtPassword.Layout(gtx, th, lang.Translate("Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtConfirmPassword.Layout(gtx, th, lang.Translate("Confirm Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonExport.Text = lang.Translate("EXPORT BACKUP")
+			p.buttonExport.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonExport.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return sectionTitle(gtx, lang.Translate("Restore"), lang.Translate("Merge a backup file into this app. Wallets already present are skipped and only unknown nodes and gateways are added."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtImportPassword.Layout(gtx, th, lang.Translate("Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonImport.Text = lang.Translate("RESTORE BACKUP")
+			p.buttonImport.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonImport.Layout(gtx, th)
+		},
+	)
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type BackupWalletItem struct {
+	walletInfo   app_db.WalletInfo
+	switchSelect *widget.Bool
+}
+
+func NewBackupWalletItem(walletInfo app_db.WalletInfo) *BackupWalletItem {
+	return &BackupWalletItem{
+		walletInfo:   walletInfo,
+		switchSelect: new(widget.Bool),
+	}
+}
+
+func (item *BackupWalletItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			s := material.Switch(th, item.switchSelect, "")
+			s.Color = theme.Current.SwitchColors
+			return s.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), item.walletInfo.Name)
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), utils.ReduceAddr(item.walletInfo.Addr))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+	)
+}
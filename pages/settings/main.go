@@ -32,9 +32,12 @@ type PageMain struct {
 	themeSelector                  *prefabs.ThemeSelector
 	buttonInfo                     *components.Button
 	buttonIpfsGateway              *components.Button
+	buttonBackup                   *components.Button
+	buttonProxy                    *components.Button
 	buttonDonation                 *components.Button
 	androidBackgroundServiceSwitch *AndroidBackgroundServiceSwitch
 	testnetSwitch                  *TestnetSwitch
+	paranoidModeSelector           *ParanoidModeSelector
 }
 
 var _ router.Page = &PageMain{}
@@ -77,6 +80,38 @@ func NewPageFront() *PageMain {
 	buttonIpfsGateway.Label.Alignment = text.Middle
 	buttonIpfsGateway.Style.Font.Weight = font.Bold
 
+	backupIcon, _ := widget.NewIcon(icons.ActionSettingsBackupRestore)
+	buttonBackup := components.NewButton(components.ButtonStyle{
+		Icon:      backupIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonBackup.Label.Alignment = text.Middle
+	buttonBackup.Style.Font.Weight = font.Bold
+
+	proxyIcon, _ := widget.NewIcon(icons.CommunicationVPNKey)
+	buttonProxy := components.NewButton(components.ButtonStyle{
+		Icon:      proxyIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonProxy.Label.Alignment = text.Middle
+	buttonProxy.Style.Font.Weight = font.Bold
+
 	donationIcon, _ := widget.NewIcon(app_icons.Donation)
 	buttonDonation := components.NewButton(components.ButtonStyle{
 		Icon:      donationIcon,
@@ -104,9 +139,12 @@ func NewPageFront() *PageMain {
 		themeSelector:                  themeSelector,
 		buttonInfo:                     buttonInfo,
 		buttonIpfsGateway:              buttonIpfsGateway,
+		buttonBackup:                   buttonBackup,
+		buttonProxy:                    buttonProxy,
 		buttonDonation:                 buttonDonation,
 		androidBackgroundServiceSwitch: NewAndroidBackgroundServiceSwitch(),
 		testnetSwitch:                  NewTestnetSwitch(),
+		paranoidModeSelector:           NewParanoidModeSelector(),
 	}
 }
 
@@ -143,6 +181,16 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		page_instance.header.AddHistory(PAGE_IPFS_GATEWAYS)
 	}
 
+	if p.buttonBackup.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_BACKUP)
+		page_instance.header.AddHistory(PAGE_BACKUP)
+	}
+
+	if p.buttonProxy.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_PROXY)
+		page_instance.header.AddHistory(PAGE_PROXY)
+	}
+
 	if p.buttonDonation.Clicked(gtx) {
 		page_instance.pageRouter.SetCurrent(PAGE_DONATION)
 		page_instance.header.AddHistory(PAGE_DONATION)
@@ -191,6 +239,16 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 			p.buttonIpfsGateway.Style.Colors = theme.Current.ButtonSecondaryColors
 			return p.buttonIpfsGateway.Layout(gtx, th)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonBackup.Text = lang.Translate("Backup & Restore")
+			p.buttonBackup.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonBackup.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonProxy.Text = lang.Translate("Proxy")
+			p.buttonProxy.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonProxy.Layout(gtx, th)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			return p.langSelector.Layout(gtx, th)
 		},
@@ -207,6 +265,10 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		})
 	}
 
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.paranoidModeSelector.Layout(gtx, th)
+	})
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return p.testnetSwitch.Layout(gtx, th)
 	})
@@ -33,6 +33,8 @@ var (
 	PAGE_ADD_IPFS_GATEWAY  = "page_add_ipfs_gateway"
 	PAGE_EDIT_IPFS_GATEWAY = "page_edit_ipfs_gateway"
 	PAGE_DONATION          = "page_donation"
+	PAGE_BACKUP            = "page_backup"
+	PAGE_PROXY             = "page_proxy"
 )
 
 var page_instance *Page
@@ -60,6 +62,12 @@ func New() *Page {
 	pageDonation := NewPageDonation()
 	pageRouter.Add(PAGE_DONATION, pageDonation)
 
+	pageBackup := NewPageBackup()
+	pageRouter.Add(PAGE_BACKUP, pageBackup)
+
+	pageProxy := NewPageProxy()
+	pageRouter.Add(PAGE_PROXY, pageProxy)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
@@ -0,0 +1,164 @@
+package page_settings
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/theme"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// listselect returns an empty key when closed so the off mode needs its own key
+const paranoidModeOffKey = "off"
+
+var paranoidNodeCounts = []int{2, 3, 4, 5}
+
+type ParanoidModeSelector struct {
+	buttonMode  *components.Button
+	buttonNodes *components.Button
+}
+
+func NewParanoidModeSelector() *ParanoidModeSelector {
+	newButton := func(icon *widget.Icon) *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Icon:      icon,
+			TextSize:  unit.Sp(16),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+			Border: widget.Border{
+				Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+				Width:        unit.Dp(2),
+				CornerRadius: unit.Dp(5),
+			},
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	securityIcon, _ := widget.NewIcon(icons.HardwareSecurity)
+	nodesIcon, _ := widget.NewIcon(icons.HardwareDeviceHub)
+
+	return &ParanoidModeSelector{
+		buttonMode:  newButton(securityIcon),
+		buttonNodes: newButton(nodesIcon),
+	}
+}
+
+func paranoidModeText(mode string) string {
+	switch mode {
+	case settings.ParanoidModeWarn:
+		return lang.Translate("Warn")
+	case settings.ParanoidModeBlock:
+		return lang.Translate("Block")
+	}
+
+	return lang.Translate("Off")
+}
+
+func (p *ParanoidModeSelector) save() {
+	err := settings.Save()
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
+}
+
+func (p *ParanoidModeSelector) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if p.buttonMode.Clicked(gtx) {
+		go func() {
+			var items []*listselect_modal.SelectListItem
+			for _, mode := range []string{paranoidModeOffKey, settings.ParanoidModeWarn, settings.ParanoidModeBlock} {
+				key := mode
+				if mode == paranoidModeOffKey {
+					mode = settings.ParanoidModeOff
+				}
+
+				items = append(items, listselect_modal.NewSelectListItem(key,
+					listselect_modal.NewItemText(nil, paranoidModeText(mode)).Layout,
+				))
+			}
+
+			activeKey := settings.App.ParanoidMode
+			if activeKey == settings.ParanoidModeOff {
+				activeKey = paranoidModeOffKey
+			}
+
+			for key := range listselect_modal.Instance.Open(items, activeKey) {
+				switch key {
+				case paranoidModeOffKey:
+					settings.App.ParanoidMode = settings.ParanoidModeOff
+				case settings.ParanoidModeWarn, settings.ParanoidModeBlock:
+					settings.App.ParanoidMode = key
+				default:
+					continue
+				}
+
+				p.save()
+			}
+		}()
+	}
+
+	if p.buttonNodes.Clicked(gtx) {
+		go func() {
+			var items []*listselect_modal.SelectListItem
+			for _, count := range paranoidNodeCounts {
+				items = append(items, listselect_modal.NewSelectListItem(strconv.Itoa(count),
+					listselect_modal.NewItemText(nil, fmt.Sprint(count)).Layout,
+				))
+			}
+
+			for key := range listselect_modal.Instance.Open(items, strconv.Itoa(settings.App.ParanoidNodes)) {
+				count, err := strconv.Atoi(key)
+				if err != nil {
+					continue
+				}
+
+				settings.App.ParanoidNodes = count
+				p.save()
+			}
+		}()
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(20), lang.Translate("Paranoid Mode"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			p.buttonMode.Text = fmt.Sprintf("%s: %s", lang.Translate("Mode"), paranoidModeText(settings.App.ParanoidMode))
+			p.buttonMode.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonMode.Layout(gtx, th)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			p.buttonNodes.Text = fmt.Sprintf("%s: %d", lang.Translate("Nodes"), settings.App.ParanoidNodes)
+			p.buttonNodes.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonNodes.Layout(gtx, th)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("Critical reads like smart contract data before a swap or your balance before sending are compared across multiple nodes at the same topoheight. Warn asks for confirmation if they diverge and Block cancels the transaction."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
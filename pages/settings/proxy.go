@@ -0,0 +1,241 @@
+package page_settings
+
+import (
+	"fmt"
+	"strconv"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/node_manager"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/proxy_manager"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/theme"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageProxy struct {
+	isActive            bool
+	headerPageAnimation *prefabs.PageHeaderAnimation
+
+	switchEnabled *widget.Bool
+	switchPrivacy *widget.Bool
+	txtHost       *prefabs.TextField
+	txtPort       *prefabs.TextField
+	txtUsername   *prefabs.TextField
+	txtPassword   *prefabs.TextField
+	buttonSave    *components.Button
+
+	list *widget.List
+}
+
+var _ router.Page = &PageProxy{}
+
+func NewPageProxy() *PageProxy {
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonSave := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        saveIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonSave.Label.Alignment = text.Middle
+	buttonSave.Style.Font.Weight = font.Bold
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_PROXY)
+	return &PageProxy{
+		headerPageAnimation: headerPageAnimation,
+
+		switchEnabled: new(widget.Bool),
+		switchPrivacy: new(widget.Bool),
+		txtHost:       prefabs.NewTextField(),
+		txtPort:       prefabs.NewNumberTextField(),
+		txtUsername:   prefabs.NewTextField(),
+		txtPassword:   prefabs.NewPasswordTextField(),
+		buttonSave:    buttonSave,
+
+		list: list,
+	}
+}
+
+func (p *PageProxy) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageProxy) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string { return lang.Translate("Proxy") }
+	page_instance.header.Subtitle = nil
+	page_instance.header.RightLayout = nil
+
+	config := settings.App.Proxy
+	p.switchEnabled.Value = config.Enabled
+	p.switchPrivacy.Value = config.PrivacyMode
+	p.txtHost.SetValue(config.Host)
+	port := ""
+	if config.Port > 0 {
+		port = strconv.Itoa(config.Port)
+	}
+	p.txtPort.SetValue(port)
+	p.txtUsername.SetValue(config.Username)
+	p.txtPassword.SetValue(config.Password)
+}
+
+func (p *PageProxy) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageProxy) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonSave.Clicked(gtx) {
+		p.submitForm()
+	}
+
+	layoutSwitch := func(gtx layout.Context, value *widget.Bool, title string, description string) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), title)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				s := material.Switch(th, value, "")
+				s.Color = theme.Current.SwitchColors
+				return s.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), description)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Route the remote node connection, IPFS gateways and image downloads through a SOCKS5 proxy like Tor. Local network addresses always connect directly."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitch(gtx, p.switchEnabled, lang.Translate("Use Proxy"), lang.Translate("A direct connection is used if the proxy can't be reached."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtHost.Layout(gtx, th, lang.Translate("Host"), "127.0.0.1")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtPort.Layout(gtx, th, lang.Translate("Port"), "9050")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtUsername.Layout(gtx, th, lang.Translate("Username"), lang.Translate("Optional"))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtPassword.Layout(gtx, th, lang.Translate("Password"), lang.Translate("Optional"))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitch(gtx, p.switchPrivacy, lang.Translate("Privacy Mode"), lang.Translate("Connections fail instead of falling back to a direct connection. The integrated node is disabled because its peer connections can't go through the proxy."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSave.Text = lang.Translate("SAVE")
+			p.buttonSave.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSave.Layout(gtx, th)
+		},
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageProxy) submitForm() {
+	p.buttonSave.SetLoading(true)
+	go func() {
+		setError := func(err error) {
+			p.buttonSave.SetLoading(false)
+			notification_modal.Open(notification_modal.Params{
+				Type:  notification_modal.ERROR,
+				Title: lang.Translate("Error"),
+				Text:  err.Error(),
+			})
+		}
+
+		config := settings.ProxySettings{
+			Enabled:     p.switchEnabled.Value,
+			PrivacyMode: p.switchPrivacy.Value,
+			Host:        p.txtHost.Value(),
+			Username:    p.txtUsername.Value(),
+			Password:    p.txtPassword.Value(),
+		}
+
+		if p.txtPort.Value() != "" {
+			port, err := strconv.Atoi(p.txtPort.Value())
+			if err != nil {
+				setError(fmt.Errorf("invalid proxy port"))
+				return
+			}
+
+			config.Port = port
+		}
+
+		err := proxy_manager.ValidateSettings(config)
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		settings.App.Proxy = config
+		err = settings.Save()
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		// reconnect with the new proxy settings
+		currentNode := node_manager.CurrentNode
+		if currentNode != nil {
+			if currentNode.Integrated && config.PrivacyMode {
+				err = node_manager.Set(nil, true)
+			} else if !currentNode.Integrated {
+				err = node_manager.Set(currentNode, false)
+			}
+
+			if err != nil {
+				setError(err)
+				return
+			}
+		}
+
+		p.buttonSave.SetLoading(false)
+		notification_modal.Open(notification_modal.Params{
+			Type:       notification_modal.SUCCESS,
+			Title:      lang.Translate("Success"),
+			Text:       lang.Translate("Proxy settings saved."),
+			CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+		})
+	}()
+}
@@ -0,0 +1,279 @@
+package page_wallet
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+)
+
+type BalanceChart struct {
+	scId     crypto.Hash
+	decimals int
+	period   wallet_manager.BalancePeriod
+	history  *wallet_manager.BalanceHistory
+
+	buttonPeriods map[wallet_manager.BalancePeriod]*components.Button
+}
+
+func NewBalanceChart() *BalanceChart {
+	buttonPeriods := make(map[wallet_manager.BalancePeriod]*components.Button)
+	for _, period := range wallet_manager.BalancePeriods {
+		buttonPeriods[period] = components.NewButton(components.ButtonStyle{
+			TextSize: unit.Sp(14),
+			Inset: layout.Inset{
+				Top: unit.Dp(4), Bottom: unit.Dp(4),
+				Left: unit.Dp(8), Right: unit.Dp(8),
+			},
+			Rounded:   components.UniformRounded(5),
+			Animation: components.NewButtonAnimationDefault(),
+		})
+	}
+
+	return &BalanceChart{
+		period:        wallet_manager.BALANCE_PERIOD_WEEK,
+		buttonPeriods: buttonPeriods,
+	}
+}
+
+func (c *BalanceChart) SetToken(scId crypto.Hash, decimals int) {
+	if c.scId != scId {
+		c.history = nil
+	}
+
+	c.scId = scId
+	c.decimals = decimals
+}
+
+func (c *BalanceChart) Load() error {
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return nil
+	}
+
+	history, err := wallet.GetBalanceHistory(c.scId, c.period)
+	if err != nil {
+		return err
+	}
+
+	c.history = &history
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+func balancePeriodText(period wallet_manager.BalancePeriod) string {
+	switch period {
+	case wallet_manager.BALANCE_PERIOD_DAY:
+		return lang.Translate("Day")
+	case wallet_manager.BALANCE_PERIOD_WEEK:
+		return lang.Translate("Week")
+	case wallet_manager.BALANCE_PERIOD_MONTH:
+		return lang.Translate("Month")
+	}
+
+	return lang.Translate("All")
+}
+
+func (c *BalanceChart) layoutLine(gtx layout.Context, history *wallet_manager.BalanceHistory) layout.Dimensions {
+	size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(100))
+
+	maxBalance := uint64(0)
+	minBalance := ^uint64(0)
+	for _, point := range history.Points {
+		if point.Balance > maxBalance {
+			maxBalance = point.Balance
+		}
+
+		if point.Balance < minBalance {
+			minBalance = point.Balance
+		}
+	}
+
+	// keep some room so a flat balance is drawn in the middle
+	if maxBalance == minBalance {
+		maxBalance++
+		if minBalance > 0 {
+			minBalance--
+		}
+	}
+
+	lineWidth := float32(gtx.Dp(2))
+	height := float32(size.Y) - lineWidth
+	stepX := float32(size.X) / float32(len(history.Points)-1)
+	pointY := func(balance uint64) float32 {
+		ratio := float32(balance-minBalance) / float32(maxBalance-minBalance)
+		return lineWidth/2 + height*(1-ratio)
+	}
+
+	points := make([]f32.Point, len(history.Points))
+	for i, point := range history.Points {
+		points[i] = f32.Pt(float32(i)*stepX, pointY(point.Balance))
+	}
+
+	var area clip.Path
+	area.Begin(gtx.Ops)
+	area.MoveTo(f32.Pt(0, float32(size.Y)))
+	for _, pt := range points {
+		area.LineTo(pt)
+	}
+	area.LineTo(f32.Pt(float32(size.X), float32(size.Y)))
+	area.Close()
+	areaSpec := area.End()
+
+	var line clip.Path
+	line.Begin(gtx.Ops)
+	line.MoveTo(points[0])
+	for _, pt := range points[1:] {
+		line.LineTo(pt)
+	}
+	lineSpec := line.End()
+
+	areaColor := theme.Current.TextColor
+	areaColor.A = 25
+	paint.FillShape(gtx.Ops, areaColor, clip.Outline{Path: areaSpec}.Op())
+	paint.FillShape(gtx.Ops, theme.Current.TextColor, clip.Stroke{Path: lineSpec, Width: lineWidth}.Op())
+
+	return layout.Dimensions{Size: size}
+}
+
+func (c *BalanceChart) layoutFlow(gtx layout.Context, th *material.Theme, title string, amount string, dotColor color.NRGBA) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Dp(8)
+			paint.FillShape(gtx.Ops, dotColor, clip.Ellipse{Max: image.Pt(size, size)}.Op(gtx.Ops))
+			return layout.Dimensions{Size: image.Pt(size, size)}
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), title)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), amount)
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func (c *BalanceChart) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	for period, button := range c.buttonPeriods {
+		if button.Clicked(gtx) && c.period != period {
+			c.period = period
+			go c.Load()
+		}
+	}
+
+	history := c.history
+
+	var periodChilds []layout.FlexChild
+	for i, period := range wallet_manager.BalancePeriods {
+		button := c.buttonPeriods[period]
+		button.Text = balancePeriodText(period)
+		if c.period == period {
+			button.Style.Colors = theme.Current.ButtonPrimaryColors
+		} else {
+			button.Style.Colors = theme.Current.ButtonInvertColors
+		}
+
+		if i > 0 {
+			periodChilds = append(periodChilds, layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout))
+		}
+
+		periodChilds = append(periodChilds, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return button.Layout(gtx, th)
+		}))
+	}
+
+	r := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, periodChilds...)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if history == nil || len(history.Points) < 2 {
+					return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, gtx.Dp(100))}
+				}
+
+				if settings.App.HideBalance {
+					size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(100))
+					paint.FillShape(gtx.Ops, theme.Current.HideBalanceBgColor,
+						clip.UniformRRect(image.Rectangle{Max: size}, gtx.Dp(5)).Op(gtx.Ops))
+					return layout.Dimensions{Size: size}
+				}
+
+				return c.layoutLine(gtx, history)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if history == nil {
+					return layout.Dimensions{}
+				}
+
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(12), history.From.Format("2006-01-02 15:04"))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(12), history.To.Format("2006-01-02 15:04"))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if history == nil {
+					return layout.Dimensions{}
+				}
+
+				inflow := lang.Translate("HIDDEN")
+				outflow := lang.Translate("HIDDEN")
+				if !settings.App.HideBalance {
+					inflow = fmt.Sprintf("+%s", utils.ShiftNumber{Number: history.Inflow, Decimals: c.decimals}.Format())
+					outflow = fmt.Sprintf("-%s", utils.ShiftNumber{Number: history.Outflow, Decimals: c.decimals}.Format())
+				}
+
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return c.layoutFlow(gtx, th, lang.Translate("In"), inflow, theme.Current.NodeStatusDotGreenColor)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(15)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return c.layoutFlow(gtx, th, lang.Translate("Out"), outflow, theme.Current.NodeStatusDotRedColor)
+					}),
+				)
+			}),
+		)
+	})
+	call := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(15),
+		).Op(gtx.Ops))
+
+	call.Add(gtx.Ops)
+	return dims
+}
@@ -57,6 +57,7 @@ type PageBalanceTokens struct {
 	buttonContacts   *components.Button
 	tabBars          *components.TabBars
 	txBar            *TxBar
+	balanceChart     *BalanceChart
 	txItems          []*TxListItem
 	getEntriesParams wallet_manager.GetEntriesParams
 	tokenDragItems   *components.DragItems
@@ -138,6 +139,9 @@ func NewPageBalanceTokens() *PageBalanceTokens {
 	tabBars := components.NewTabBars(defaultTabKey, tabBarsItems)
 
 	txBar := NewTxBar()
+	balanceChart := NewBalanceChart()
+	dero := wallet_manager.DeroToken()
+	balanceChart.SetToken(dero.GetHash(), int(dero.Decimals))
 	tokenDragItems := components.NewDragItems()
 	tokenList := new(widget.List)
 	tokenList.Axis = layout.Vertical
@@ -164,6 +168,7 @@ func NewPageBalanceTokens() *PageBalanceTokens {
 		buttonContacts:      buttonContacts,
 		tabBars:             tabBars,
 		txBar:               txBar,
+		balanceChart:        balanceChart,
 		tokenDragItems:      tokenDragItems,
 		tokenList:           tokenList,
 		bgImg:               bgImg,
@@ -241,9 +246,12 @@ func (p *PageBalanceTokens) LoadTokens() error {
 	return nil
 }
 
-func (p *PageBalanceTokens) LoadTxs() {
+func (p *PageBalanceTokens) LoadTxs() error {
 	wallet := wallet_manager.OpenedWallet
-	entries := wallet.GetEntries(&crypto.ZEROHASH, p.getEntriesParams)
+	entries, err := wallet.GetEntries(&crypto.ZEROHASH, p.getEntriesParams)
+	if err != nil {
+		return err
+	}
 
 	txItems := []*TxListItem{}
 
@@ -253,6 +261,7 @@ func (p *PageBalanceTokens) LoadTxs() {
 
 	p.txItems = txItems
 	p.txBar.txCount = len(entries)
+	return p.balanceChart.Load()
 }
 
 func (p *PageBalanceTokens) ResetWalletHeader() {
@@ -303,6 +312,18 @@ func (p *PageBalanceTokens) ResetWalletHeader() {
 				p.buttonCopyAddr.Style.Colors = theme.Current.ModalButtonColors
 				return p.buttonCopyAddr.Layout(gtx, th)
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !wallet.IsLocked() {
+					return layout.Dimensions{}
+				}
+
+				return layout.Inset{Left: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(th, unit.Sp(14), lang.Translate("Read-only"))
+					label.Color = theme.Current.TextMuteColor
+					label.Font.Weight = font.Bold
+					return label.Layout(gtx)
+				})
+			}),
 		)
 	}
 }
@@ -404,6 +425,16 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 		}
 	}
 
+	if wallet != nil {
+		syncErr := wallet.EntriesSyncError()
+		if syncErr != nil {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				text := lang.Translate("The transaction history could not be updated. {}")
+				return p.alertBox.Layout(gtx, th, strings.Replace(text, "{}", syncErr.Error(), -1))
+			})
+		}
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{
 			Left: theme.PagePadding, Right: theme.PagePadding,
@@ -413,6 +444,15 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 		})
 	})
 
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Left: theme.PagePadding, Right: theme.PagePadding,
+			Top: unit.Dp(0), Bottom: unit.Dp(10),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return p.balanceChart.Layout(gtx, th)
+		})
+	})
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{
 			Left: theme.PagePadding, Right: theme.PagePadding,
@@ -462,6 +502,7 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 
 	{
 		changed, tab := p.txBar.Changed()
+		search := p.txBar.Search()
 		if changed {
 			go func() {
 				switch tab {
@@ -481,6 +522,7 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 					}
 				}
 
+				p.getEntriesParams.Search = search
 				p.LoadTxs()
 				app_instance.Window.Invalidate()
 			}()
@@ -738,6 +780,15 @@ func NewSendReceiveButtons() *SendReceiveButtons {
 }
 
 func (s *SendReceiveButtons) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	// sending is hidden when the wallet was opened with the viewing PIN
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil && wallet.IsLocked() {
+		gtx.Constraints.Max.Y = gtx.Dp(40)
+		s.ButtonReceive.Text = lang.Translate("RECEIVE")
+		s.ButtonReceive.Style.Colors = theme.Current.ButtonPrimaryColors
+		return s.ButtonReceive.Layout(gtx, th)
+	}
+
 	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			gtx.Constraints.Max.Y = gtx.Dp(40)
@@ -1090,6 +1141,7 @@ type TxBar struct {
 	buttonOut      *components.Button
 	buttonCoinbase *components.Button
 	buttonFilter   *components.Button
+	txtSearch      *prefabs.Input
 	txCount        int
 
 	textColorOn  color.NRGBA
@@ -1098,6 +1150,7 @@ type TxBar struct {
 	bgColorOff   color.NRGBA
 
 	tab     string
+	search  string
 	changed bool
 }
 
@@ -1171,6 +1224,7 @@ func NewTxBar() *TxBar {
 		buttonOut:      buttonOut,
 		buttonCoinbase: buttonCoinbase,
 		buttonFilter:   buttonFilter,
+		txtSearch:      prefabs.NewInput(),
 		tab:            "all",
 
 		textColorOn:  textColorOn,
@@ -1184,6 +1238,11 @@ func (t *TxBar) Changed() (bool, string) {
 	return t.changed, t.tab
 }
 
+// Search matches the txid or the annotations of the entries
+func (t *TxBar) Search() sql.NullString {
+	return sql.NullString{String: t.search, Valid: t.search != ""}
+}
+
 func (t *TxBar) setActiveButton(button *components.Button, tab string) {
 	if t.tab == tab {
 		button.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -1217,6 +1276,12 @@ func (t *TxBar) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions
 		t.tab = "coinbase"
 	}
 
+	search := strings.TrimSpace(t.txtSearch.Value())
+	if t.search != search {
+		t.changed = true
+		t.search = search
+	}
+
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
@@ -1256,6 +1321,12 @@ func (t *TxBar) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions
 				}),*/
 			)
 		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			t.txtSearch.TextSize = unit.Sp(16)
+			t.txtSearch.Colors = theme.Current.InputColors
+			return t.txtSearch.Layout(gtx, th, lang.Translate("Search txid, note, category or tag..."))
+		}),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			txt := lang.Translate("{} transactions")
@@ -0,0 +1,675 @@
+package page_wallet
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"reflect"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/containers/password_modal"
+	"github.com/g45t345rt/g45w/containers/recent_txs_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageBatchSend struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	buttonAddRow        *components.Button
+	buttonImport        *components.Button
+	buttonSend          *components.Button
+	buttonCancel        *components.Button
+
+	// default token of new rows
+	scId       string
+	rows       []*BatchSendRow
+	batches    [][]rpc.Transfer
+	batchRows  []*BatchSendRow // rows of the prepared batches, in the same order
+	sending    bool
+	cancelSend context.CancelFunc // stops waiting for the confirmation of the previous tx
+	status     string
+
+	list *widget.List
+}
+
+var _ router.Page = &PageBatchSend{}
+
+func NewPageBatchSend() *PageBatchSend {
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	buttonAddRow := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonAddRow.Label.Alignment = text.Middle
+	buttonAddRow.Style.Font.Weight = font.Bold
+
+	importIcon, _ := widget.NewIcon(icons.FileFileDownload)
+	buttonImport := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      importIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonImport.Label.Alignment = text.Middle
+	buttonImport.Style.Font.Weight = font.Bold
+
+	sendIcon, _ := widget.NewIcon(icons.ContentSend)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonSend := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        sendIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		LoadingIcon: loadingIcon,
+		Animation:   components.NewButtonAnimationDefault(),
+	})
+	buttonSend.Label.Alignment = text.Middle
+	buttonSend.Style.Font.Weight = font.Bold
+
+	buttonCancel := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonCancel.Label.Alignment = text.Middle
+	buttonCancel.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_BATCH_SEND)
+	return &PageBatchSend{
+		headerPageAnimation: headerPageAnimation,
+		buttonAddRow:        buttonAddRow,
+		buttonImport:        buttonImport,
+		buttonSend:          buttonSend,
+		buttonCancel:        buttonCancel,
+		scId:                crypto.ZEROHASH.String(),
+		rows:                make([]*BatchSendRow, 0),
+		list:                list,
+	}
+}
+
+func (p *PageBatchSend) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageBatchSend) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Batch Send")
+	}
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+
+	if len(p.rows) == 0 {
+		p.rows = append(p.rows, NewBatchSendRow(p.scId))
+	}
+}
+
+func (p *PageBatchSend) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+// SetToken sets the token of new rows
+func (p *PageBatchSend) SetToken(token *wallet_manager.Token) {
+	p.scId = token.SCID
+}
+
+func (p *PageBatchSend) importCSV() error {
+	file, err := app_instance.Explorer.ChooseFile(".csv")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// address,amount,scid,comment,destination port
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []*BatchSendRow
+	for i := 0; ; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if i == 0 && strings.EqualFold(record[0], "address") {
+			continue // skip header
+		}
+
+		if len(record) < 2 {
+			return fmt.Errorf("invalid row %d: expected at least an address and an amount", i+1)
+		}
+
+		row := NewBatchSendRow(p.scId)
+		row.txtAddr.SetValue(record[0])
+		row.txtAmount.SetValue(record[1])
+		if len(record) > 2 && record[2] != "" {
+			row.txtSCID.SetValue(record[2])
+		}
+		if len(record) > 3 {
+			row.txtComment.SetValue(record[3])
+		}
+		if len(record) > 4 {
+			row.txtDstPort.SetValue(record[4])
+		}
+
+		rows = append(rows, row)
+	}
+
+	// replace the empty row created when entering the page
+	if len(p.rows) == 1 && p.rows[0].txtAddr.Value() == "" {
+		p.rows = make([]*BatchSendRow, 0)
+	}
+
+	p.rows = append(p.rows, rows...)
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+// prepareBatches validates every row and splits the transfers into txs
+func (p *PageBatchSend) prepareBatches() error {
+	if len(p.rows) == 0 {
+		return fmt.Errorf(lang.Translate("Add at least one recipient."))
+	}
+
+	tokens := make(map[string]*wallet_manager.Token)
+	var transfers []rpc.Transfer
+	invalid := 0
+
+	for _, row := range p.rows {
+		row.txId = ""
+		transfer, err := row.transfer(tokens)
+		row.err = err
+		if err != nil {
+			invalid++
+			continue
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf(lang.Translate("Some recipients are invalid."))
+	}
+
+	p.batches = wallet_manager.SplitTransfers(transfers)
+	p.batchRows = make([]*BatchSendRow, len(p.rows))
+	copy(p.batchRows, p.rows)
+	return nil
+}
+
+// sendBatches rebuilds the batches from the rows and sends them if they are still the ones the user confirmed
+func (p *PageBatchSend) sendBatches() {
+	wallet := wallet_manager.OpenedWallet
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancelSend = cancel
+	p.sending = true
+	p.buttonSend.SetLoading(true)
+	defer func() {
+		cancel()
+		p.sending = false
+		p.buttonSend.SetLoading(false)
+		app_instance.Window.Invalidate()
+	}()
+
+	// the rows can be edited while the confirm and password prompts are opened
+	confirmedBatches := p.batches
+	err := p.prepareBatches()
+	if err == nil && !reflect.DeepEqual(confirmedBatches, p.batches) {
+		err = fmt.Errorf(lang.Translate("The recipients were changed. Send again to confirm them."))
+	}
+
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+		return
+	}
+
+	ringsize := uint64(settings.App.SendRingSize)
+	offset := 0
+	for i, batch := range p.batches {
+		rows := p.batchRows[offset : offset+len(batch)]
+		offset += len(batch)
+
+		if i > 0 {
+			// the previous tx must be mined before using the balance again
+			p.setStatus(lang.Translate("Waiting for transaction {} of {} to be confirmed..."), i, len(p.batches))
+			prevTxId := p.batchRows[offset-len(batch)-1].txId
+			outgoingTx, err := wallet.WaitOutgoingTx(ctx, prevTxId)
+			if err == context.Canceled {
+				err = fmt.Errorf(lang.Translate("The batch send was canceled."))
+			} else if err == nil && outgoingTx.Status.String != "valid" {
+				txt := lang.Translate("Transaction {} was not confirmed.")
+				err = fmt.Errorf(strings.Replace(txt, "{}", utils.ReduceTxId(prevTxId), -1))
+			}
+
+			if err != nil {
+				p.failBatch(rows, err)
+				return
+			}
+		}
+
+		p.setStatus(lang.Translate("Sending transaction {} of {}..."), i+1, len(p.batches))
+		txId, err := p.sendBatch(batch, ringsize)
+		for _, row := range rows {
+			row.txId = txId
+		}
+
+		if err != nil {
+			p.failBatch(rows, err)
+			return
+		}
+	}
+
+	p.status = lang.Translate("All transactions were sent.")
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       p.status,
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+	recent_txs_modal.Instance.SetVisible(true)
+}
+
+func (p *PageBatchSend) sendBatch(transfers []rpc.Transfer, ringsize uint64) (string, error) {
+	wallet := wallet_manager.OpenedWallet
+	transfer, err := wallet_manager.FormatTransfer(rpc.Transfer_Params{
+		Transfers: transfers,
+		Ringsize:  ringsize,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	txFees, gasFees, err := wallet.EstimateFees(&transfer)
+	if err != nil {
+		return "", err
+	}
+
+	// the txid is returned with the error if the tx was broadcasted anyway
+	tx, err := wallet.BuildAndSendTx(transfer, txFees, gasFees, lang.Translate("Batch Send"))
+	if tx == nil {
+		return "", err
+	}
+
+	return tx.GetHash().String(), err
+}
+
+func (p *PageBatchSend) setStatus(format string, current int, total int) {
+	status := strings.Replace(format, "{}", fmt.Sprint(current), 1)
+	p.status = strings.Replace(status, "{}", fmt.Sprint(total), 1)
+	app_instance.Window.Invalidate()
+}
+
+func (p *PageBatchSend) failBatch(rows []*BatchSendRow, err error) {
+	for _, row := range rows {
+		row.err = err
+	}
+
+	p.status = lang.Translate("The batch was interrupted. Rows without a TXID were not sent.")
+	notification_modal.Open(notification_modal.Params{
+		Type:  notification_modal.ERROR,
+		Title: lang.Translate("Error"),
+		Text:  err.Error(),
+	})
+}
+
+func (p *PageBatchSend) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.sending && p.buttonCancel.Clicked(gtx) {
+		p.cancelSend()
+	}
+
+	if !p.sending {
+		if p.buttonAddRow.Clicked(gtx) {
+			p.rows = append(p.rows, NewBatchSendRow(p.scId))
+		}
+
+		if p.buttonImport.Clicked(gtx) {
+			go func() {
+				err := p.importCSV()
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+				}
+			}()
+		}
+
+		if p.buttonSend.Clicked(gtx) {
+			go func() {
+				p.buttonSend.SetLoading(true)
+				err := p.prepareBatches()
+				p.buttonSend.SetLoading(false)
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+					return
+				}
+
+				prompt := lang.Translate("{} transfers will be sent in {} transactions. Every transaction must be confirmed before the next one is sent.")
+				prompt = strings.Replace(prompt, "{}", fmt.Sprint(len(p.rows)), 1)
+				prompt = strings.Replace(prompt, "{}", fmt.Sprint(len(p.batches)), 1)
+				yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+					Prompt: prompt,
+				})
+
+				if yes {
+					password_modal.Instance.SetVisible(true)
+				}
+			}()
+		}
+
+		for i := range p.rows {
+			if p.rows[i].buttonRemove.Clicked(gtx) {
+				p.rows = append(p.rows[:i], p.rows[i+1:]...)
+				break
+			}
+		}
+	}
+
+	submitted, password := password_modal.Instance.Submitted()
+	if submitted {
+		go func() {
+			wallet := wallet_manager.OpenedWallet
+			password_modal.Instance.SetLoading(true)
+			validPassword := wallet.Memory.Check_Password(password)
+			password_modal.Instance.SetLoading(false)
+			password_modal.Instance.Input.UnlockSubmit()
+
+			if !validPassword {
+				password_modal.Instance.StartWrongPassAnimation()
+			} else {
+				password_modal.Instance.Input.SetValue("")
+				password_modal.Instance.SetVisible(false)
+				p.sendBatches()
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			txt := lang.Translate("Import a CSV file with the columns address, amount, scid, comment and destination port. Leave the scid empty to send DERO.")
+			lbl := material.Label(th, unit.Sp(16), txt)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+	}
+
+	for i := range p.rows {
+		idx := i
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			if idx < len(p.rows) {
+				return p.rows[idx].Layout(gtx, th, idx)
+			}
+			return layout.Dimensions{}
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonAddRow.Text = lang.Translate("ADD ROW")
+					p.buttonAddRow.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonAddRow.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonImport.Text = lang.Translate("IMPORT CSV")
+					p.buttonImport.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonImport.Layout(gtx, th)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+	)
+
+	if p.status != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), p.status)
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSend.Text = lang.Translate("SEND ALL")
+			p.buttonSend.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSend.Layout(gtx, th)
+		},
+	)
+
+	if p.sending {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonCancel.Text = lang.Translate("CANCEL")
+			p.buttonCancel.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonCancel.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+		},
+	)
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type BatchSendRow struct {
+	txtAddr      *prefabs.TextField
+	txtAmount    *prefabs.TextField
+	txtSCID      *prefabs.TextField
+	txtComment   *prefabs.TextField
+	txtDstPort   *prefabs.TextField
+	buttonRemove *components.Button
+
+	txId string
+	err  error
+}
+
+func NewBatchSendRow(scId string) *BatchSendRow {
+	removeIcon, _ := widget.NewIcon(icons.NavigationCancel)
+	buttonRemove := components.NewButton(components.ButtonStyle{
+		Icon: removeIcon,
+	})
+
+	txtSCID := prefabs.NewTextField()
+	if scId != crypto.ZEROHASH.String() {
+		txtSCID.SetValue(scId)
+	}
+
+	return &BatchSendRow{
+		txtAddr:      prefabs.NewTextField(),
+		txtAmount:    prefabs.NewNumberTextField(),
+		txtSCID:      txtSCID,
+		txtComment:   prefabs.NewTextField(),
+		txtDstPort:   prefabs.NewNumberTextField(),
+		buttonRemove: buttonRemove,
+	}
+}
+
+// transfer validates the row like the send form does
+func (row *BatchSendRow) transfer(tokens map[string]*wallet_manager.Token) (transfer rpc.Transfer, err error) {
+	scId := row.txtSCID.Value()
+	if scId == "" {
+		scId = crypto.ZEROHASH.String()
+	}
+
+	token, ok := tokens[scId]
+	if !ok {
+		token, err = wallet_manager.GetTokenBySCID(scId)
+		if err != nil {
+			return
+		}
+
+		tokens[scId] = token
+	}
+
+	if row.txtAmount.Value() == "" {
+		err = fmt.Errorf(lang.Translate("Amount cannot be empty."))
+		return
+	}
+
+	amount := &utils.ShiftNumber{Decimals: int(token.Decimals)}
+	err = amount.Parse(row.txtAmount.Value())
+	if err != nil {
+		return
+	}
+
+	if amount.Number == 0 {
+		err = fmt.Errorf(lang.Translate("Amount must be greater than 0."))
+		return
+	}
+
+	return newTransfer(token.GetHash(), row.txtAddr.Value(), amount.Number, row.txtComment.Value(), row.txtDstPort.Value())
+}
+
+func (row *BatchSendRow) Layout(gtx layout.Context, th *material.Theme, index int) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("#%d", index+1))
+						lbl.Font.Weight = font.Bold
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						row.buttonRemove.Style.Colors = theme.Current.ButtonIconPrimaryColors
+						gtx.Constraints.Min.X = gtx.Dp(25)
+						gtx.Constraints.Min.Y = gtx.Dp(25)
+						return row.buttonRemove.Layout(gtx, th)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return row.txtAddr.Layout(gtx, th, lang.Translate("Address / Name"), "")
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return row.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return row.txtDstPort.Layout(gtx, th, lang.Translate("Destination Port"), "")
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return row.txtSCID.Layout(gtx, th, lang.Translate("Token SCID"), "DERO")
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return row.txtComment.Layout(gtx, th, lang.Translate("Comment"), "")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if row.txId != "" {
+					return layout.Inset{Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(14), fmt.Sprintf("TXID: %s", row.txId))
+						return lbl.Layout(gtx)
+					})
+				}
+
+				if row.err != nil {
+					return layout.Inset{Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(14), row.err.Error())
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					})
+				}
+
+				return layout.Dimensions{}
+			}),
+		)
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops),
+	)
+
+	c.Add(gtx.Ops)
+	return dims
+}
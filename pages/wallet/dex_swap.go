@@ -1,6 +1,7 @@
 package page_wallet
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -23,6 +24,7 @@ import (
 	"github.com/g45t345rt/g45w/containers/listselect_modal"
 	"github.com/g45t345rt/g45w/containers/notification_modal"
 	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/node_manager"
 	"github.com/g45t345rt/g45w/prefabs"
 	"github.com/g45t345rt/g45w/router"
 	"github.com/g45t345rt/g45w/sc/dex_sc"
@@ -265,6 +267,27 @@ func (p *PageDEXSwap) submitForm() error {
 		return err
 	}
 
+	if node_manager.IsParanoid() {
+		// make sure the pair reserves are not faked by the current node before swapping
+		result, err := wallet_manager.CrossCheckSC(p.pair.SCID)
+
+		// the result is empty if the call failed, only a divergence returns the variables of the current node
+		var divergenceErr *node_manager.DivergenceError
+		hasResult := err == nil || errors.As(err, &divergenceErr)
+
+		err = build_tx_modal.ConfirmCrossCheck(err)
+		if err != nil {
+			return err
+		}
+
+		if hasResult {
+			err = p.pair.Parse(p.pair.SCID, result.VariableStringKeys)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	build_tx_modal.Instance.OpenWithRandomAddr(crypto.ZEROHASH, func(randomAddr string) build_tx_modal.TxPayload {
 		return build_tx_modal.TxPayload{
 			Transfer: rpc.Transfer_Params{
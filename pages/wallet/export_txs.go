@@ -0,0 +1,347 @@
+package page_wallet
+
+import (
+	"database/sql"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageExportTxs struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	buttonFormat        *components.Button
+	buttonToken         *components.Button
+	txtFromDate         *prefabs.TextField
+	txtToDate           *prefabs.TextField
+	feeRows             *widget.Bool
+	buttonExport        *components.Button
+
+	format wallet_manager.ExportFormat
+	token  *wallet_manager.Token // nil for all tokens
+
+	list *widget.List
+}
+
+var _ router.Page = &PageExportTxs{}
+
+func NewPageExportTxs() *PageExportTxs {
+	filterIcon, _ := widget.NewIcon(icons.ContentFilterList)
+	newSelectButton := func() *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      filterIcon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	exportIcon, _ := widget.NewIcon(icons.EditorPublish)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonExport := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        exportIcon,
+		LoadingIcon: loadingIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+	})
+	buttonExport.Label.Alignment = text.Middle
+	buttonExport.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_EXPORT_TXS)
+	return &PageExportTxs{
+		headerPageAnimation: headerPageAnimation,
+		buttonFormat:        newSelectButton(),
+		buttonToken:         newSelectButton(),
+		txtFromDate:         prefabs.NewTextField(),
+		txtToDate:           prefabs.NewTextField(),
+		feeRows:             new(widget.Bool),
+		buttonExport:        buttonExport,
+		format:              wallet_manager.EXPORT_FORMAT_CSV,
+		list:                list,
+	}
+}
+
+func (p *PageExportTxs) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageExportTxs) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Export Transactions")
+	}
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+}
+
+func (p *PageExportTxs) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageExportTxs) openFormatSelect() {
+	var items []*listselect_modal.SelectListItem
+	for _, format := range wallet_manager.ExportFormats {
+		txt := format.String()
+		items = append(items, listselect_modal.NewSelectListItem(string(format), func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), txt)
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	key := <-listselect_modal.Instance.Open(items, string(p.format))
+	if key != "" {
+		p.format = wallet_manager.ExportFormat(key)
+	}
+}
+
+func tokenSelectText(token *wallet_manager.Token) string {
+	if token.Symbol.String != "" {
+		return token.Symbol.String
+	}
+
+	if token.Name != "" {
+		return token.Name
+	}
+
+	return utils.ReduceTxId(token.SCID)
+}
+
+func (p *PageExportTxs) openTokenSelect() error {
+	wallet := wallet_manager.OpenedWallet
+	tokens, err := wallet.GetTokens(wallet_manager.GetTokensParams{})
+	if err != nil {
+		return err
+	}
+
+	tokens = append([]wallet_manager.Token{*wallet_manager.DeroToken()}, tokens...)
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("all", func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("All tokens"))
+			return lbl.Layout(gtx)
+		}),
+	}
+
+	for i := range tokens {
+		txt := tokenSelectText(&tokens[i])
+		items = append(items, listselect_modal.NewSelectListItem(tokens[i].SCID, func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), txt)
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	current := "all"
+	if p.token != nil {
+		current = p.token.SCID
+	}
+
+	key := <-listselect_modal.Instance.Open(items, current)
+	switch key {
+	case "":
+	case "all":
+		p.token = nil
+	default:
+		for i := range tokens {
+			if tokens[i].SCID == key {
+				p.token = &tokens[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *PageExportTxs) export() error {
+	fromTimestamp, err := parseDate(p.txtFromDate.Value())
+	if err != nil {
+		return err
+	}
+
+	toTimestamp, err := parseDate(p.txtToDate.Value())
+	if err != nil {
+		return err
+	}
+
+	params := wallet_manager.ExportTxsParams{
+		Format:  p.format,
+		FeeRows: p.feeRows.Value,
+	}
+
+	if fromTimestamp.Valid {
+		params.TimeFrom = sql.NullTime{Time: time.Unix(fromTimestamp.Int64, 0), Valid: true}
+	}
+
+	// include the whole end day
+	if toTimestamp.Valid {
+		params.TimeTo = sql.NullTime{Time: time.Unix(toTimestamp.Int64, 0).AddDate(0, 0, 1), Valid: true}
+	}
+
+	if p.token != nil {
+		hash := crypto.HashHexToHash(p.token.SCID)
+		params.SCID = &hash
+	}
+
+	p.buttonExport.SetLoading(true)
+	defer p.buttonExport.SetLoading(false)
+
+	wallet := wallet_manager.OpenedWallet
+	txs, err := wallet.GetExportTxs(params)
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile(params.Format.FileName())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = wallet_manager.WriteExportTxs(file, params.Format, txs)
+	if err != nil {
+		return err
+	}
+
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       lang.Translate("Transactions exported."),
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+	return nil
+}
+
+func (p *PageExportTxs) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	showError := func(err error) {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
+
+	if p.buttonFormat.Clicked(gtx) {
+		go p.openFormatSelect()
+	}
+
+	if p.buttonToken.Clicked(gtx) {
+		go func() {
+			err := p.openTokenSelect()
+			if err != nil {
+				showError(err)
+			}
+		}()
+	}
+
+	if p.buttonExport.Clicked(gtx) {
+		go func() {
+			err := p.export()
+			if err != nil {
+				showError(err)
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("Amounts are exported with the decimals of each token and dates in UTC (ISO 8601). Counterparties are resolved with your contacts."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonFormat.Text = p.format.String()
+					p.buttonFormat.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonFormat.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("All tokens")
+					if p.token != nil {
+						txt = tokenSelectText(p.token)
+					}
+
+					p.buttonToken.Text = txt
+					p.buttonToken.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonToken.Layout(gtx, th)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtFromDate.Layout(gtx, th, lang.Translate("From"), "YYYY-MM-DD")
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtToDate.Layout(gtx, th, lang.Translate("To"), "YYYY-MM-DD")
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					s := material.Switch(th, p.feeRows, "")
+					s.Color = theme.Current.SwitchColors
+					return s.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), lang.Translate("Export fees as separate rows"))
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonExport.Text = lang.Translate("EXPORT")
+			p.buttonExport.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonExport.Layout(gtx, th)
+		},
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
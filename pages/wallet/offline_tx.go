@@ -0,0 +1,480 @@
+package page_wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/containers/password_modal"
+	"github.com/g45t345rt/g45w/containers/recent_txs_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageOfflineTx struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	txtSigner           *prefabs.TextField
+	txtAddr             *prefabs.TextField
+	txtAmount           *prefabs.TextField
+	txtSCID             *prefabs.TextField
+	txtComment          *prefabs.TextField
+	txtDstPort          *prefabs.TextField
+	buttonPrepare       *components.Button
+	buttonSign          *components.Button
+	buttonBroadcast     *components.Button
+
+	unsignedTx *wallet_manager.UnsignedTx
+
+	list *widget.List
+}
+
+var _ router.Page = &PageOfflineTx{}
+
+func NewPageOfflineTx() *PageOfflineTx {
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	newButton := func(icon *widget.Icon) *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:     components.UniformRounded(unit.Dp(5)),
+			Icon:        icon,
+			TextSize:    unit.Sp(14),
+			IconGap:     unit.Dp(10),
+			Inset:       layout.UniformInset(unit.Dp(10)),
+			LoadingIcon: loadingIcon,
+			Animation:   components.NewButtonAnimationDefault(),
+			Border: widget.Border{
+				Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+				Width:        unit.Dp(2),
+				CornerRadius: unit.Dp(5),
+			},
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	prepareIcon, _ := widget.NewIcon(icons.EditorPublish)
+	signIcon, _ := widget.NewIcon(icons.ActionLock)
+	broadcastIcon, _ := widget.NewIcon(icons.ContentSend)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_OFFLINE_TX)
+	return &PageOfflineTx{
+		headerPageAnimation: headerPageAnimation,
+		txtSigner:           prefabs.NewTextField(),
+		txtAddr:             prefabs.NewTextField(),
+		txtAmount:           prefabs.NewNumberTextField(),
+		txtSCID:             prefabs.NewTextField(),
+		txtComment:          prefabs.NewTextField(),
+		txtDstPort:          prefabs.NewNumberTextField(),
+		buttonPrepare:       newButton(prepareIcon),
+		buttonSign:          newButton(signIcon),
+		buttonBroadcast:     newButton(broadcastIcon),
+		list:                list,
+	}
+}
+
+func (p *PageOfflineTx) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageOfflineTx) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Offline Signing")
+	}
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+}
+
+func (p *PageOfflineTx) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageOfflineTx) showError(err error) {
+	notification_modal.Open(notification_modal.Params{
+		Type:  notification_modal.ERROR,
+		Title: lang.Translate("Error"),
+		Text:  err.Error(),
+	})
+}
+
+func (p *PageOfflineTx) showSuccess(text string) {
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       text,
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+}
+
+// prepareTx fetches everything the signer needs (ring members, balances, topoheight and fees) and exports it
+func (p *PageOfflineTx) prepareTx() error {
+	wallet := wallet_manager.OpenedWallet
+
+	signer, err := rpc.NewAddress(p.txtSigner.Value())
+	if err != nil {
+		return fmt.Errorf(lang.Translate("Invalid signer address."))
+	}
+
+	scId := p.txtSCID.Value()
+	if scId == "" {
+		scId = crypto.ZEROHASH.String()
+	}
+
+	token, err := wallet_manager.GetTokenBySCID(scId)
+	if err != nil {
+		return err
+	}
+
+	if p.txtAmount.Value() == "" {
+		return fmt.Errorf(lang.Translate("Amount cannot be empty."))
+	}
+
+	amount := &utils.ShiftNumber{Decimals: int(token.Decimals)}
+	err = amount.Parse(p.txtAmount.Value())
+	if err != nil {
+		return err
+	}
+
+	if amount.Number == 0 {
+		return fmt.Errorf(lang.Translate("Amount must be greater than 0."))
+	}
+
+	transfer, err := newTransfer(token.GetHash(), p.txtAddr.Value(), amount.Number, p.txtComment.Value(), p.txtDstPort.Value())
+	if err != nil {
+		return err
+	}
+
+	txParams, err := wallet_manager.FormatTransfer(rpc.Transfer_Params{
+		Transfers: []rpc.Transfer{transfer},
+		Ringsize:  uint64(settings.App.SendRingSize),
+		Signer:    signer.BaseAddress().String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	txFees, gasFees, err := wallet.EstimateFees(&txParams)
+	if err != nil {
+		return err
+	}
+
+	unsignedTx, err := wallet.PrepareTx(signer.String(), txParams, txFees, gasFees)
+	if err != nil {
+		return err
+	}
+
+	unsignedTx.Description = lang.Translate("Offline Signing")
+
+	data, err := json.MarshalIndent(unsignedTx, "", " ")
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile("unsigned_tx.json")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func (p *PageOfflineTx) chooseUnsignedTx() (*wallet_manager.UnsignedTx, error) {
+	file, err := app_instance.Explorer.ChooseFile(".json")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var unsignedTx wallet_manager.UnsignedTx
+	err = json.Unmarshal(data, &unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unsignedTx, nil
+}
+
+// unsignedTxPrompt lists what is about to be signed so it can be reviewed on the offline device
+func (p *PageOfflineTx) unsignedTxPrompt(u *wallet_manager.UnsignedTx) string {
+	var lines []string
+	for _, transfer := range u.Transfers {
+		if transfer.Amount == 0 && transfer.Burn == 0 {
+			continue // zero transfer added for fees
+		}
+
+		amount := utils.ShiftNumber{Number: transfer.Amount, Decimals: 5}.Format()
+		token := "DERO"
+		if !transfer.SCID.IsZero() {
+			amount = fmt.Sprint(transfer.Amount)
+			token = utils.ReduceTxId(transfer.SCID.String())
+		}
+
+		// the full address must be checked, a shortened one is easy to spoof
+		lines = append(lines, fmt.Sprintf("%s %s > %s", amount, token, transfer.Destination))
+	}
+
+	fees := utils.ShiftNumber{Number: u.TxFees + u.GasFees, Decimals: 5}.Format()
+	lines = append(lines, strings.Replace(lang.Translate("Fees: {} DERO"), "{}", fees, -1))
+	return strings.Join(lines, "\n")
+}
+
+func (p *PageOfflineTx) signTx() error {
+	wallet := wallet_manager.OpenedWallet
+	tx, err := wallet.SignTx(p.unsignedTx)
+	if err != nil {
+		return err
+	}
+
+	signedTx := wallet_manager.NewSignedTx(tx, p.unsignedTx.Description)
+	data, err := json.MarshalIndent(signedTx, "", " ")
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile("signed_tx.json")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func (p *PageOfflineTx) broadcastTx() error {
+	file, err := app_instance.Explorer.ChooseFile(".json")
+	if err != nil {
+		return err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	var signedTx wallet_manager.SignedTx
+	err = json.Unmarshal(data, &signedTx)
+	if err != nil {
+		return err
+	}
+
+	prompt := strings.Replace(lang.Translate("Broadcast transaction {}?"), "{}", utils.ReduceTxId(signedTx.TxId), -1)
+	yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: prompt,
+	})
+
+	if !yes {
+		return nil
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	_, err = wallet.SendSignedTx(signedTx)
+	if err != nil {
+		return err
+	}
+
+	recent_txs_modal.Instance.SetVisible(true)
+	return nil
+}
+
+func (p *PageOfflineTx) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonPrepare.Clicked(gtx) {
+		go func() {
+			p.buttonPrepare.SetLoading(true)
+			err := p.prepareTx()
+			p.buttonPrepare.SetLoading(false)
+			if err != nil {
+				p.showError(err)
+			} else {
+				p.showSuccess(lang.Translate("Unsigned transaction exported."))
+			}
+		}()
+	}
+
+	if p.buttonSign.Clicked(gtx) {
+		go func() {
+			unsignedTx, err := p.chooseUnsignedTx()
+			if err != nil {
+				p.showError(err)
+				return
+			}
+
+			yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+				Title:  lang.Translate("Sign transaction?"),
+				Prompt: p.unsignedTxPrompt(unsignedTx),
+			})
+
+			if yes {
+				p.unsignedTx = unsignedTx
+				password_modal.Instance.SetVisible(true)
+			}
+		}()
+	}
+
+	if p.buttonBroadcast.Clicked(gtx) {
+		go func() {
+			p.buttonBroadcast.SetLoading(true)
+			err := p.broadcastTx()
+			p.buttonBroadcast.SetLoading(false)
+			if err != nil {
+				p.showError(err)
+			}
+		}()
+	}
+
+	submitted, password := password_modal.Instance.Submitted()
+	if submitted {
+		go func() {
+			wallet := wallet_manager.OpenedWallet
+			password_modal.Instance.SetLoading(true)
+			validPassword := wallet.Memory.Check_Password(password)
+			password_modal.Instance.SetLoading(false)
+			password_modal.Instance.Input.UnlockSubmit()
+
+			if !validPassword {
+				password_modal.Instance.StartWrongPassAnimation()
+			} else {
+				password_modal.Instance.Input.SetValue("")
+				password_modal.Instance.SetVisible(false)
+
+				p.buttonSign.SetLoading(true)
+				err := p.signTx()
+				p.buttonSign.SetLoading(false)
+				p.unsignedTx = nil
+				if err != nil {
+					p.showError(err)
+				} else {
+					p.showSuccess(lang.Translate("Signed transaction exported."))
+				}
+			}
+
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	sectionTitle := func(gtx layout.Context, title string, description string) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), title)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), description)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Keep the keys of a wallet on a device that never goes online. Transfer the files between the two devices."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return sectionTitle(gtx, lang.Translate("1. Prepare"), lang.Translate("Online: fetch the ring members and estimate the fees for the offline wallet."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSigner.Layout(gtx, th, lang.Translate("Offline Wallet Address"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtAddr.Layout(gtx, th, lang.Translate("Address / Name"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "")
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtDstPort.Layout(gtx, th, lang.Translate("Destination Port"), "")
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSCID.Layout(gtx, th, lang.Translate("Token SCID"), "DERO")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtComment.Layout(gtx, th, lang.Translate("Comment"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonPrepare.Text = lang.Translate("EXPORT UNSIGNED TX")
+			p.buttonPrepare.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonPrepare.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return sectionTitle(gtx, lang.Translate("2. Sign"), lang.Translate("Offline: import the unsigned transaction with the wallet that holds the keys and export the signed transaction."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSign.Text = lang.Translate("IMPORT & SIGN")
+			p.buttonSign.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonSign.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return sectionTitle(gtx, lang.Translate("3. Broadcast"), lang.Translate("Online: import the signed transaction and send it to the network."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonBroadcast.Text = lang.Translate("IMPORT & BROADCAST")
+			p.buttonBroadcast.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonBroadcast.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+		},
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
@@ -0,0 +1,364 @@
+package page_wallet
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/browser"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// PageOutgoingTx shows what was actually sent by decoding the stored hex data of an outgoing tx
+type PageOutgoingTx struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	buttonViewExplorer  *components.Button
+
+	outgoingTx wallet_manager.OutgoingTx
+	tx         *transaction.Transaction
+	decodeErr  error
+
+	txIdEditor    *widget.Editor
+	scDataEditor  *widget.Editor
+	hexDataEditor *widget.Editor
+	infoRows      []*prefabs.InfoRow
+	payloads      []*OutgoingTxPayload
+	txTransfers   *TxTransfers
+	payloadList   []*RPCArgInfo
+
+	list *widget.List
+}
+
+var _ router.Page = &PageOutgoingTx{}
+
+func NewPageOutgoingTx() *PageOutgoingTx {
+	explorerIcon, _ := widget.NewIcon(icons.ActionOpenInBrowser)
+	buttonViewExplorer := components.NewButton(components.ButtonStyle{
+		Icon: explorerIcon,
+	})
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_OUTGOING_TX)
+	return &PageOutgoingTx{
+		headerPageAnimation: headerPageAnimation,
+		buttonViewExplorer:  buttonViewExplorer,
+		txIdEditor:          &widget.Editor{ReadOnly: true},
+		scDataEditor:        &widget.Editor{ReadOnly: true},
+		hexDataEditor:       &widget.Editor{ReadOnly: true},
+		infoRows:            prefabs.NewInfoRows(9),
+		txTransfers:         NewTxTransfers(),
+		list:                list,
+	}
+}
+
+func (p *PageOutgoingTx) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageOutgoingTx) SetOutgoingTx(outgoingTx wallet_manager.OutgoingTx) {
+	p.outgoingTx = outgoingTx
+	p.tx, p.decodeErr = outgoingTx.Transaction()
+
+	p.payloads = make([]*OutgoingTxPayload, 0)
+	p.payloadList = make([]*RPCArgInfo, 0)
+	p.txIdEditor.SetText(outgoingTx.TxId)
+	p.hexDataEditor.SetText(outgoingTx.HexData.String)
+	p.scDataEditor.SetText("")
+
+	if p.tx != nil {
+		for i, payload := range p.tx.Payloads {
+			p.payloads = append(p.payloads, NewOutgoingTxPayload(i, payload))
+		}
+
+		if len(p.tx.SCDATA) > 0 {
+			scData, _ := json.MarshalIndent(p.tx.SCDATA, "", "  ")
+			p.scDataEditor.SetText(string(scData))
+		}
+	}
+
+	// the payloads are encrypted, use the entries of the wallet (available once the tx is mined and synced) to show them
+	wallet := wallet_manager.OpenedWallet
+	entries, _ := wallet.GetEntries(nil, wallet_manager.GetEntriesParams{
+		TXID: sql.NullString{String: outgoingTx.TxId, Valid: true},
+	})
+
+	p.txTransfers.items = make([]*TxTransferItem, 0)
+	if len(entries) > 0 {
+		p.txTransfers.Load(entries[0])
+		for _, arg := range entries[0].Payload_RPC {
+			p.payloadList = append(p.payloadList, NewRPCArgInfo(arg))
+		}
+	}
+}
+
+func (p *PageOutgoingTx) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Outgoing Transaction")
+	}
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(p.outgoingTx.TxId))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		p.buttonViewExplorer.Style.Colors = theme.Current.ButtonIconPrimaryColors
+		gtx.Constraints.Min.X = gtx.Dp(30)
+		gtx.Constraints.Min.Y = gtx.Dp(30)
+
+		if p.buttonViewExplorer.Clicked(gtx) {
+			go func() {
+				url := fmt.Sprintf("https://explorer.dero.io/tx/%s", p.outgoingTx.TxId)
+				err := browser.OpenUrl(url)
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+				}
+			}()
+		}
+
+		return p.buttonViewExplorer.Layout(gtx, th)
+	}
+}
+
+func (p *PageOutgoingTx) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageOutgoingTx) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	titleLayout := func(gtx layout.Context, title string) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), title)
+		lbl.Font.Weight = font.Bold
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	boxLayout := func(gtx layout.Context, editor *widget.Editor) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			editor := material.Editor(th, editor, "")
+			editor.TextSize = unit.Sp(12)
+			return editor.Layout(gtx)
+		})
+		c := r.Stop()
+
+		paint.FillShape(gtx.Ops, theme.Current.BgColor, clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+		c.Add(gtx.Ops)
+		return dims
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			editor := material.Editor(th, p.txIdEditor, "")
+			return editor.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			outgoingTx := p.outgoingTx
+			date := time.Unix(outgoingTx.Timestamp.Int64, 0)
+			txType := transaction.TransactionType(outgoingTx.TxType.Int32)
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[0].Layout(gtx, th, lang.Translate("Status"), outgoingTxStatusText(outgoingTx.Status.String))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[1].Layout(gtx, th, lang.Translate("Type"), outgoingTxTypeText(txType))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[2].Layout(gtx, th, lang.Translate("Date"), date.Format("2006-01-02 15:04"))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[3].Layout(gtx, th, lang.Translate("Height Built"), fmt.Sprint(outgoingTx.HeightBuilt.Int64))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[4].Layout(gtx, th, lang.Translate("Block Height"), fmt.Sprint(outgoingTx.BlockHeight.Int64))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[5].Layout(gtx, th, lang.Translate("Pending Since Height"), fmt.Sprint(outgoingTx.PendingHeight))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if p.tx == nil {
+						return layout.Dimensions{}
+					}
+
+					fees := utils.ShiftNumber{Number: p.tx.Fees(), Decimals: 5}
+					return p.infoRows[6].Layout(gtx, th, lang.Translate("Fees"), fees.Format())
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if p.tx == nil || p.tx.TransactionType != transaction.SC_TX {
+						return layout.Dimensions{}
+					}
+
+					gas := utils.ShiftNumber{Number: p.tx.Value, Decimals: 5}
+					return p.infoRows[7].Layout(gtx, th, lang.Translate("Gas"), gas.Format())
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[8].Layout(gtx, th, lang.Translate("Description"), outgoingTx.Description.String)
+				}),
+			)
+		},
+	}
+
+	if p.decodeErr != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), p.decodeErr.Error())
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range p.payloads {
+		payload := p.payloads[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return payload.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		if len(p.txTransfers.items) == 0 {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return titleLayout(gtx, lang.Translate("Transfers"))
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("The encrypted amounts and payload are available once the transaction is mined and the wallet is synced."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		}
+
+		return p.txTransfers.Layout(gtx, th)
+	})
+
+	for i := range p.payloadList {
+		idx := i
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.payloadList[idx].Layout(gtx, th)
+		})
+	}
+
+	if p.scDataEditor.Text() != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return titleLayout(gtx, "SC DATA")
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return boxLayout(gtx, p.scDataEditor)
+				}),
+			)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return titleLayout(gtx, lang.Translate("Hex Data"))
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return boxLayout(gtx, p.hexDataEditor)
+			}),
+		)
+	}, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type OutgoingTxPayload struct {
+	index    int
+	payload  transaction.AssetPayload
+	infoRows []*prefabs.InfoRow
+}
+
+func NewOutgoingTxPayload(index int, payload transaction.AssetPayload) *OutgoingTxPayload {
+	return &OutgoingTxPayload{
+		index:    index,
+		payload:  payload,
+		infoRows: prefabs.NewInfoRows(5),
+	}
+}
+
+func (o *OutgoingTxPayload) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	payload := o.payload
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			txt := lang.Translate("Payload #{}")
+			lbl := material.Label(th, unit.Sp(16), strings.Replace(txt, "{}", fmt.Sprint(o.index+1), -1))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			scId := "DERO"
+			if !payload.SCID.IsZero() {
+				scId = utils.ReduceTxId(payload.SCID.String())
+			}
+
+			return o.infoRows[0].Layout(gtx, th, lang.Translate("SCID"), scId)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return o.infoRows[1].Layout(gtx, th, lang.Translate("Ring Size"), fmt.Sprint(payload.Statement.RingSize))
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return o.infoRows[2].Layout(gtx, th, lang.Translate("Burn"), fmt.Sprint(payload.BurnValue))
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			fees := utils.ShiftNumber{Number: payload.Statement.Fees, Decimals: 5}
+			return o.infoRows[3].Layout(gtx, th, lang.Translate("Fees"), fees.Format())
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			txt := fmt.Sprintf("%d / %s", payload.RPCType, utils.ReduceString(hex.EncodeToString(payload.RPCPayload), 6, 6))
+			return o.infoRows[4].Layout(gtx, th, lang.Translate("Encrypted Payload"), txt)
+		}),
+	)
+}
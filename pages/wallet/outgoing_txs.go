@@ -0,0 +1,541 @@
+package page_wallet
+
+import (
+	"database/sql"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+const OUTGOING_TXS_PAGE_SIZE = 20
+
+type PageOutgoingTxs struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	txtSearch           *prefabs.TextField
+	txtFromDate         *prefabs.TextField
+	txtToDate           *prefabs.TextField
+	buttonFilterStatus  *components.Button
+	buttonFilterType    *components.Button
+	buttonSearch        *components.Button
+	buttonPrev          *components.Button
+	buttonNext          *components.Button
+
+	filterStatus sql.NullString
+	filterTxType *transaction.TransactionType
+	params       wallet_manager.GetOutgoingTxsParams
+
+	pageIndex uint64
+	count     uint64
+	items     []*OutgoingTxItem
+
+	list *widget.List
+}
+
+var _ router.Page = &PageOutgoingTxs{}
+
+func NewPageOutgoingTxs() *PageOutgoingTxs {
+	filterIcon, _ := widget.NewIcon(icons.ContentFilterList)
+	newFilterButton := func() *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      filterIcon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	searchIcon, _ := widget.NewIcon(icons.ActionSearch)
+	buttonSearch := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      searchIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSearch.Label.Alignment = text.Middle
+	buttonSearch.Style.Font.Weight = font.Bold
+
+	prevIcon, _ := widget.NewIcon(icons.NavigationChevronLeft)
+	buttonPrev := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      prevIcon,
+		Inset:     layout.UniformInset(unit.Dp(5)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+
+	nextIcon, _ := widget.NewIcon(icons.NavigationChevronRight)
+	buttonNext := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      nextIcon,
+		Inset:     layout.UniformInset(unit.Dp(5)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_OUTGOING_TXS)
+	return &PageOutgoingTxs{
+		headerPageAnimation: headerPageAnimation,
+		txtSearch:           prefabs.NewTextField(),
+		txtFromDate:         prefabs.NewTextField(),
+		txtToDate:           prefabs.NewTextField(),
+		buttonFilterStatus:  newFilterButton(),
+		buttonFilterType:    newFilterButton(),
+		buttonSearch:        buttonSearch,
+		buttonPrev:          buttonPrev,
+		buttonNext:          buttonNext,
+		items:               make([]*OutgoingTxItem, 0),
+		list:                list,
+	}
+}
+
+func (p *PageOutgoingTxs) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageOutgoingTxs) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Outgoing Transactions")
+	}
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		txt := lang.Translate("{} transactions")
+		lbl := material.Label(th, unit.Sp(14), strings.Replace(txt, "{}", fmt.Sprint(p.count), -1))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+
+	p.Load()
+}
+
+func (p *PageOutgoingTxs) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageOutgoingTxs) pageCount() uint64 {
+	pages := (p.count + OUTGOING_TXS_PAGE_SIZE - 1) / OUTGOING_TXS_PAGE_SIZE
+	if pages == 0 {
+		return 1
+	}
+
+	return pages
+}
+
+func (p *PageOutgoingTxs) Load() error {
+	wallet := wallet_manager.OpenedWallet
+
+	count, err := wallet.CountOutgoingTxs(p.params)
+	if err != nil {
+		return err
+	}
+
+	p.count = count
+	if p.pageIndex >= p.pageCount() {
+		p.pageIndex = p.pageCount() - 1
+	}
+
+	params := p.params
+	params.OrderBy = "timestamp"
+	params.Descending = true
+	params.Limit = OUTGOING_TXS_PAGE_SIZE
+	params.Offset = p.pageIndex * OUTGOING_TXS_PAGE_SIZE
+
+	outgoingTxs, err := wallet.GetOutgoingTxs(params)
+	if err != nil {
+		return err
+	}
+
+	p.items = make([]*OutgoingTxItem, 0)
+	for _, outgoingTx := range outgoingTxs {
+		p.items = append(p.items, NewOutgoingTxItem(outgoingTx))
+	}
+
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+// parseDate expects YYYY-MM-DD in local time
+func parseDate(value string) (sql.NullInt64, error) {
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf(lang.Translate("Invalid date. Use the YYYY-MM-DD format."))
+	}
+
+	return sql.NullInt64{Int64: date.Unix(), Valid: true}, nil
+}
+
+func (p *PageOutgoingTxs) applyFilters() error {
+	fromTimestamp, err := parseDate(p.txtFromDate.Value())
+	if err != nil {
+		return err
+	}
+
+	toTimestamp, err := parseDate(p.txtToDate.Value())
+	if err != nil {
+		return err
+	}
+
+	// include the whole end day
+	if toTimestamp.Valid {
+		toTimestamp.Int64 = time.Unix(toTimestamp.Int64, 0).AddDate(0, 0, 1).Unix()
+	}
+
+	search := strings.TrimSpace(p.txtSearch.Value())
+	p.params = wallet_manager.GetOutgoingTxsParams{
+		TxType:        p.filterTxType,
+		Status:        p.filterStatus,
+		Search:        sql.NullString{String: search, Valid: search != ""},
+		FromTimestamp: fromTimestamp,
+		ToTimestamp:   toTimestamp,
+	}
+	p.pageIndex = 0
+	return p.Load()
+}
+
+func outgoingTxStatusText(status string) string {
+	switch status {
+	case "valid":
+		return lang.Translate("Valid")
+	case "invalid":
+		return lang.Translate("Invalid")
+	case "replaced":
+		return lang.Translate("Replaced")
+	default:
+		return lang.Translate("Pending")
+	}
+}
+
+func outgoingTxTypeText(txType transaction.TransactionType) string {
+	switch txType {
+	case transaction.REGISTRATION:
+		return lang.Translate("Registration")
+	case transaction.BURN_TX:
+		return lang.Translate("Burn")
+	case transaction.SC_TX:
+		return lang.Translate("Smart Contract")
+	default:
+		return lang.Translate("Transfer")
+	}
+}
+
+func (p *PageOutgoingTxs) openStatusFilter() {
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("", func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("All"))
+			return lbl.Layout(gtx)
+		}),
+	}
+
+	for _, status := range []string{"pending", "valid", "invalid", "replaced"} {
+		txt := outgoingTxStatusText(status)
+		items = append(items, listselect_modal.NewSelectListItem(status, func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), txt)
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	key := <-listselect_modal.Instance.Open(items, p.filterStatus.String)
+	p.filterStatus = sql.NullString{String: key, Valid: key != ""}
+}
+
+func (p *PageOutgoingTxs) openTypeFilter() {
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("", func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("All"))
+			return lbl.Layout(gtx)
+		}),
+	}
+
+	txTypes := []transaction.TransactionType{transaction.NORMAL, transaction.SC_TX, transaction.BURN_TX, transaction.REGISTRATION}
+	for _, txType := range txTypes {
+		txt := outgoingTxTypeText(txType)
+		items = append(items, listselect_modal.NewSelectListItem(fmt.Sprint(int(txType)), func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), txt)
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	current := ""
+	if p.filterTxType != nil {
+		current = fmt.Sprint(int(*p.filterTxType))
+	}
+
+	key := <-listselect_modal.Instance.Open(items, current)
+	p.filterTxType = nil
+	for _, txType := range txTypes {
+		if key == fmt.Sprint(int(txType)) {
+			value := txType
+			p.filterTxType = &value
+		}
+	}
+}
+
+func (p *PageOutgoingTxs) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	showError := func(err error) {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
+
+	if p.buttonFilterStatus.Clicked(gtx) {
+		go func() {
+			p.openStatusFilter()
+			err := p.applyFilters()
+			if err != nil {
+				showError(err)
+			}
+		}()
+	}
+
+	if p.buttonFilterType.Clicked(gtx) {
+		go func() {
+			p.openTypeFilter()
+			err := p.applyFilters()
+			if err != nil {
+				showError(err)
+			}
+		}()
+	}
+
+	if p.buttonSearch.Clicked(gtx) {
+		go func() {
+			err := p.applyFilters()
+			if err != nil {
+				showError(err)
+			}
+		}()
+	}
+
+	if p.buttonPrev.Clicked(gtx) && p.pageIndex > 0 {
+		p.pageIndex--
+		go p.Load()
+	}
+
+	if p.buttonNext.Clicked(gtx) && p.pageIndex+1 < p.pageCount() {
+		p.pageIndex++
+		go p.Load()
+	}
+
+	for _, item := range p.items {
+		if item.clickable.Clicked(gtx) {
+			page_instance.pageOutgoingTx.SetOutgoingTx(item.tx)
+			page_instance.pageRouter.SetCurrent(PAGE_OUTGOING_TX)
+			page_instance.header.AddHistory(PAGE_OUTGOING_TX)
+		}
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSearch.Layout(gtx, th, lang.Translate("Search"), lang.Translate("TXID or description"))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtFromDate.Layout(gtx, th, lang.Translate("From"), "YYYY-MM-DD")
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtToDate.Layout(gtx, th, lang.Translate("To"), "YYYY-MM-DD")
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("All statuses")
+					if p.filterStatus.Valid {
+						txt = outgoingTxStatusText(p.filterStatus.String)
+					}
+
+					p.buttonFilterStatus.Text = txt
+					p.buttonFilterStatus.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonFilterStatus.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("All types")
+					if p.filterTxType != nil {
+						txt = outgoingTxTypeText(*p.filterTxType)
+					}
+
+					p.buttonFilterType.Text = txt
+					p.buttonFilterType.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonFilterType.Layout(gtx, th)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSearch.Text = lang.Translate("SEARCH")
+			p.buttonSearch.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSearch.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+	}
+
+	if len(p.items) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("No transactions."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range p.items {
+		item := p.items[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				p.buttonPrev.Disabled = p.pageIndex == 0
+				p.buttonPrev.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonPrev.Layout(gtx, th)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				txt := lang.Translate("Page {} of {}")
+				txt = strings.Replace(txt, "{}", fmt.Sprint(p.pageIndex+1), 1)
+				txt = strings.Replace(txt, "{}", fmt.Sprint(p.pageCount()), 1)
+				lbl := material.Label(th, unit.Sp(16), txt)
+				lbl.Alignment = text.Middle
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				p.buttonNext.Disabled = p.pageIndex+1 >= p.pageCount()
+				p.buttonNext.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonNext.Layout(gtx, th)
+			}),
+		)
+	}, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(10),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type OutgoingTxItem struct {
+	tx        wallet_manager.OutgoingTx
+	clickable *widget.Clickable
+}
+
+func NewOutgoingTxItem(tx wallet_manager.OutgoingTx) *OutgoingTxItem {
+	return &OutgoingTxItem{
+		tx:        tx,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *OutgoingTxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	return item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.Inset{
+			Top: unit.Dp(13), Bottom: unit.Dp(13),
+			Left: unit.Dp(15), Right: unit.Dp(15),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(item.tx.TxId))
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							date := time.Unix(item.tx.Timestamp.Int64, 0)
+							lbl := material.Label(th, unit.Sp(14), date.Format("2006-01-02 15:04"))
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txType := transaction.TransactionType(item.tx.TxType.Int32)
+					txt := fmt.Sprintf("%s - %s", outgoingTxTypeText(txType), outgoingTxStatusText(item.tx.Status.String))
+					lbl := material.Label(th, unit.Sp(14), txt)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if item.tx.Description.String == "" {
+						return layout.Dimensions{}
+					}
+
+					lbl := material.Label(th, unit.Sp(14), item.tx.Description.String)
+					lbl.MaxLines = 1
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+		}
+
+		paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+			clip.UniformRRect(
+				image.Rectangle{Max: dims.Size},
+				gtx.Dp(10),
+			).Op(gtx.Ops),
+		)
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+}
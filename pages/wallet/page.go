@@ -66,6 +66,8 @@ type Page struct {
 	pageSCExplorer      *PageSCExplorer
 	pageSCFunction      *PageSCFunction
 	pageSCViewCode      *PageSCViewCode
+	pageBatchSend       *PageBatchSend
+	pageOutgoingTx      *PageOutgoingTx
 
 	pageRouter *router.Router
 }
@@ -99,9 +101,17 @@ var (
 	PAGE_DEX_SC_BRIDGE_IN  = "page_dex_sc_bridge_in"
 	PAGE_XSWD_MANAGE       = "page_xswd_manage"
 	PAGE_XSWD_APP          = "page_xswd_app"
+	PAGE_XSWD_LOGS         = "page_xswd_logs"
+	PAGE_XSWD_POLICY_FORM  = "page_xswd_policy_form"
 	PAGE_SC_EXPLORER       = "page_sc_explorer"
 	PAGE_SC_FUNCTION       = "page_sc_function"
 	PAGE_SC_VIEW_CODE      = "page_sc_view_code"
+	PAGE_RPC_SERVER        = "page_rpc_server"
+	PAGE_BATCH_SEND        = "page_batch_send"
+	PAGE_OFFLINE_TX        = "page_offline_tx"
+	PAGE_OUTGOING_TXS      = "page_outgoing_txs"
+	PAGE_OUTGOING_TX       = "page_outgoing_tx"
+	PAGE_EXPORT_TXS        = "page_export_txs"
 )
 
 func New() *Page {
@@ -175,6 +185,12 @@ func New() *Page {
 	pageXSWDApp := NewPageXSWDApp()
 	pageRouter.Add(PAGE_XSWD_APP, pageXSWDApp)
 
+	pageXSWDLogs := NewPageXSWDLogs()
+	pageRouter.Add(PAGE_XSWD_LOGS, pageXSWDLogs)
+
+	pageXSWDPolicyForm := NewPageXSWDPolicyForm()
+	pageRouter.Add(PAGE_XSWD_POLICY_FORM, pageXSWDPolicyForm)
+
 	pageSCExplorer := NewPageSCExplorer()
 	pageRouter.Add(PAGE_SC_EXPLORER, pageSCExplorer)
 
@@ -184,6 +200,24 @@ func New() *Page {
 	pageSCViewCode := NewPageSCViewCode()
 	pageRouter.Add(PAGE_SC_VIEW_CODE, pageSCViewCode)
 
+	pageRPCServer := NewPageRPCServer()
+	pageRouter.Add(PAGE_RPC_SERVER, pageRPCServer)
+
+	pageBatchSend := NewPageBatchSend()
+	pageRouter.Add(PAGE_BATCH_SEND, pageBatchSend)
+
+	pageOfflineTx := NewPageOfflineTx()
+	pageRouter.Add(PAGE_OFFLINE_TX, pageOfflineTx)
+
+	pageOutgoingTxs := NewPageOutgoingTxs()
+	pageRouter.Add(PAGE_OUTGOING_TXS, pageOutgoingTxs)
+
+	pageOutgoingTx := NewPageOutgoingTx()
+	pageRouter.Add(PAGE_OUTGOING_TX, pageOutgoingTx)
+
+	pageExportTxs := NewPageExportTxs()
+	pageRouter.Add(PAGE_EXPORT_TXS, pageExportTxs)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
@@ -210,6 +244,8 @@ func New() *Page {
 		pageSCExplorer:      pageSCExplorer,
 		pageSCFunction:      pageSCFunction,
 		pageSCViewCode:      pageSCViewCode,
+		pageBatchSend:       pageBatchSend,
+		pageOutgoingTx:      pageOutgoingTx,
 
 		pageRouter: pageRouter,
 	}
@@ -353,7 +389,7 @@ func (p *Page) OpenXSWD() error {
 			description = strings.Replace(description, "{}", appData.Url, -1)
 
 			transferResponse := make(chan build_tx_modal.TransferResponse)
-			go build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+			txPayload := build_tx_modal.TxPayload{
 				Transfer: rpc.Transfer_Params{
 					Transfers: params.Transfers,
 					Ringsize:  params.Ringsize,
@@ -363,7 +399,14 @@ func (p *Page) OpenXSWD() error {
 				},
 				Description:      description,
 				TransferResponse: transferResponse,
-			})
+			}
+
+			sent, result, err := sendWithXSWDPolicy(appData, txPayload)
+			if sent {
+				return xswd.Allow, result, err
+			}
+
+			go build_tx_modal.Instance.Open(txPayload)
 
 			res := <-transferResponse
 			return xswd.Allow, res.Result, res.Err
@@ -379,9 +422,29 @@ func (p *Page) OpenXSWD() error {
 			description := lang.Translate("A dApp from {} wants to make a transfer.")
 			description = strings.Replace(description, "{}", appData.Url, -1)
 
+			policies, err := wallet.GetXSWDPolicies(appData.Id)
+			if err != nil {
+				return xswd.Allow, nil, err
+			}
+
+			// the random addr is only needed upfront if a policy can approve the transfer
+			if len(policies) > 0 {
+				randomAddr, err := wallet.GetRandomAddress(crypto.ZEROHASH)
+				if err != nil {
+					return xswd.Allow, nil, err
+				}
+
+				sent, result, err := sendWithXSWDPolicy(appData, build_tx_modal.TxPayload{
+					Transfer: wallet_manager.FormatSCInvoke(params, randomAddr),
+				})
+				if sent {
+					return xswd.Allow, result, err
+				}
+			}
+
 			transferResponse := make(chan build_tx_modal.TransferResponse)
 			go build_tx_modal.Instance.OpenWithRandomAddr(crypto.ZEROHASH, func(addr string) build_tx_modal.TxPayload {
-				transferParams := build_tx_modal.FormatSCInvoke(params, addr)
+				transferParams := wallet_manager.FormatSCInvoke(params, addr)
 				return build_tx_modal.TxPayload{
 					Transfer:         transferParams,
 					TransferResponse: transferResponse,
@@ -407,6 +470,20 @@ func (p *Page) OpenXSWD() error {
 	return nil
 }
 
+// sendWithXSWDPolicy sends the transfer without the build tx prompt if a spending policy of the app allows it.
+func sendWithXSWDPolicy(appData *xswd.ApplicationData, txPayload build_tx_modal.TxPayload) (bool, rpc.Transfer_Result, error) {
+	wallet := wallet_manager.OpenedWallet
+	sent, result, err := wallet.SendXSWDPolicyTransfer(*appData, txPayload.Transfer,
+		txPayload.TotalDeroAmount(), txPayload.TotalTokensAmount())
+	if sent && err == nil {
+		txt := lang.Translate("A transfer from {} was approved by a spending policy.")
+		txt = strings.Replace(txt, "{}", appData.Name, -1)
+		notify.Push("XSWD", txt)
+	}
+
+	return sent, result, err
+}
+
 func (p *Page) askToCreateFolderTokens() {
 	wallet := wallet_manager.OpenedWallet
 
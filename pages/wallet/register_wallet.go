@@ -315,6 +315,10 @@ func (p *RegisterWalletForm) startRegistration() error {
 	}
 
 	wallet := wallet_manager.OpenedWallet
+	if wallet.IsLocked() {
+		return wallet_manager.ErrReadOnly
+	}
+
 	p.normalReg.Start(int(threadCount), wallet.Memory)
 	return nil
 }
@@ -385,6 +389,10 @@ func (p *SendRegistrationForm) Layout(gtx layout.Context, th *material.Theme) la
 
 func (p *SendRegistrationForm) sendTransaction() error {
 	wallet := wallet_manager.OpenedWallet
+	if wallet.IsLocked() {
+		return wallet_manager.ErrReadOnly
+	}
+
 	txHex := wallet.Info.RegistrationTxHex
 	data, err := hex.DecodeString(txHex)
 	if err != nil {
@@ -397,7 +405,7 @@ func (p *SendRegistrationForm) sendTransaction() error {
 		return err
 	}
 
-	err = wallet.InsertOutgoingTx(tx, "")
+	err = wallet.InsertOutgoingTx(tx, nil, "")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,238 @@
+package page_wallet
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/containers/password_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageRPCServer struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	txtAddr             *prefabs.TextField
+	txtUsername         *prefabs.TextField
+	txtPassword         *prefabs.TextField
+	buttonStart         *components.Button
+	buttonStop          *components.Button
+
+	list *widget.List
+}
+
+var _ router.Page = &PageRPCServer{}
+
+func NewPageRPCServer() *PageRPCServer {
+	startIcon, _ := widget.NewIcon(icons.AVPlayArrow)
+	buttonStart := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      startIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonStart.Label.Alignment = text.Middle
+	buttonStart.Style.Font.Weight = font.Bold
+
+	stopIcon, _ := widget.NewIcon(icons.AVStop)
+	buttonStop := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      stopIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonStop.Label.Alignment = text.Middle
+	buttonStop.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_RPC_SERVER)
+	return &PageRPCServer{
+		headerPageAnimation: headerPageAnimation,
+		txtAddr:             prefabs.NewTextField(),
+		txtUsername:         prefabs.NewTextField(),
+		txtPassword:         prefabs.NewPasswordTextField(),
+		buttonStart:         buttonStart,
+		buttonStop:          buttonStop,
+		list:                list,
+	}
+}
+
+func (p *PageRPCServer) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageRPCServer) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("RPC Server")
+	}
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+
+	wallet := wallet_manager.OpenedWallet
+	p.txtAddr.SetValue(wallet.Settings.RPCServerAddr)
+	p.txtUsername.SetValue(wallet.Settings.RPCServerUsername)
+	// only the hash of the password is stored
+	p.txtPassword.SetValue("")
+}
+
+func (p *PageRPCServer) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageRPCServer) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	wallet := wallet_manager.OpenedWallet
+
+	if p.buttonStart.Clicked(gtx) {
+		password_modal.Instance.SetVisible(true)
+	}
+
+	if p.buttonStop.Clicked(gtx) {
+		wallet.CloseRPCServer()
+		notification_modal.Open(notification_modal.Params{
+			Type:       notification_modal.INFO,
+			Title:      lang.Translate("Info"),
+			Text:       lang.Translate("RPC server stopped."),
+			CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+		})
+	}
+
+	submitted, password := password_modal.Instance.Submitted()
+	if submitted {
+		go func() {
+			password_modal.Instance.SetLoading(true)
+			validPassword := wallet.Memory.Check_Password(password)
+			password_modal.Instance.SetLoading(false)
+			password_modal.Instance.Input.UnlockSubmit()
+
+			if !validPassword {
+				password_modal.Instance.StartWrongPassAnimation()
+			} else {
+				password_modal.Instance.Input.SetValue("")
+				password_modal.Instance.SetVisible(false)
+
+				err := p.startServer()
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+				} else {
+					notification_modal.Open(notification_modal.Params{
+						Type:       notification_modal.SUCCESS,
+						Title:      lang.Translate("Success"),
+						Text:       lang.Translate("RPC server started."),
+						CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+					})
+				}
+
+				app_instance.Window.Invalidate()
+			}
+		}()
+	}
+
+	running := wallet.ServerRPC != nil
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Expose this wallet with an authenticated JSON-RPC endpoint. Transfers made through the server are not confirmed, only share the credentials with software you trust."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			status := lang.Translate("Stopped")
+			if running {
+				status = fmt.Sprintf("%s - http://%s/json_rpc", lang.Translate("Running"), wallet.ServerRPC.Addr)
+			}
+
+			lbl := material.Label(th, unit.Sp(16), status)
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtAddr.Layout(gtx, th, lang.Translate("Bind Address"), wallet_manager.DefaultRPCServerAddr())
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtUsername.Layout(gtx, th, lang.Translate("Username"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			hint := ""
+			if wallet.Settings.RPCServerPasswordHash != "" {
+				hint = lang.Translate("Leave empty to keep the current password")
+			}
+
+			return p.txtPassword.Layout(gtx, th, lang.Translate("Password"), hint)
+		},
+	}
+
+	if running {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonStop.Text = lang.Translate("STOP SERVER")
+			p.buttonStop.Style.Colors = theme.Current.ButtonDangerColors
+			return p.buttonStop.Layout(gtx, th)
+		})
+	} else {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonStart.Text = lang.Translate("START SERVER")
+			p.buttonStart.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonStart.Layout(gtx, th)
+		})
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageRPCServer) startServer() error {
+	wallet := wallet_manager.OpenedWallet
+
+	wallet.Settings.RPCServerAddr = p.txtAddr.Value()
+	wallet.Settings.RPCServerUsername = p.txtUsername.Value()
+	password := p.txtPassword.Value()
+	if password != "" {
+		err := wallet.SetRPCServerPassword(password)
+		if err != nil {
+			return err
+		}
+
+		p.txtPassword.SetValue("")
+	}
+
+	err := wallet.SaveSettings()
+	if err != nil {
+		return err
+	}
+
+	return wallet.OpenRPCServer()
+}
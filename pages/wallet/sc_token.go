@@ -447,10 +447,13 @@ func (p *PageSCToken) OpenMenu() {
 	}
 }
 
-func (p *PageSCToken) LoadTxs() {
+func (p *PageSCToken) LoadTxs() error {
 	wallet := wallet_manager.OpenedWallet
 	hash := p.token.GetHash()
-	entries := wallet.GetEntries(&hash, p.getEntriesParams)
+	entries, err := wallet.GetEntries(&hash, p.getEntriesParams)
+	if err != nil {
+		return err
+	}
 
 	txItems := []*TxListItem{}
 
@@ -460,6 +463,7 @@ func (p *PageSCToken) LoadTxs() {
 
 	p.txItems = txItems
 	p.txBar.txCount = len(entries)
+	return p.balanceContainer.chart.Load()
 }
 
 func (p *PageSCToken) SetToken(token *wallet_manager.Token) {
@@ -478,6 +482,7 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 
 	{
 		changed, tab := p.txBar.Changed()
+		search := p.txBar.Search()
 		if changed {
 			go func() {
 				switch tab {
@@ -497,6 +502,7 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 					}
 				}
 
+				p.getEntriesParams.Search = search
 				p.LoadTxs()
 				app_instance.Window.Invalidate()
 			}()
@@ -641,6 +647,7 @@ type BalanceContainer struct {
 	balanceEditor     *widget.Editor
 	buttonHideBalance *ButtonHideBalance
 	tokenImage        *prefabs.ImageHoverClick
+	chart             *BalanceChart
 }
 
 func NewBalanceContainer() *BalanceContainer {
@@ -653,11 +660,13 @@ func NewBalanceContainer() *BalanceContainer {
 		buttonHideBalance: buttonHideBalance,
 		balanceEditor:     balanceEditor,
 		tokenImage:        prefabs.NewImageHoverClick(),
+		chart:             NewBalanceChart(),
 	}
 }
 
 func (b *BalanceContainer) SetToken(token *wallet_manager.Token) {
 	b.token = token
+	b.chart.SetToken(token.GetHash(), int(token.Decimals))
 }
 
 func (b *BalanceContainer) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
@@ -755,6 +764,10 @@ func (b *BalanceContainer) Layout(gtx layout.Context, th *material.Theme) layout
 			c.Add(gtx.Ops)
 			return dims
 		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return b.chart.Layout(gtx, th)
+		}),
 	)
 }
 
@@ -17,6 +17,7 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
 	"github.com/deroproject/derohe/transaction"
 	"github.com/g45t345rt/g45w/app_icons"
@@ -43,6 +44,7 @@ type PageSendForm struct {
 
 	buttonValidate   *components.Button
 	buttonOptions    *components.Button
+	buttonBatchSend  *components.Button
 	buttonSetMax     *components.Button
 	balanceContainer *BalanceContainer
 	walletAddrInput  *WalletAddrInput
@@ -100,6 +102,23 @@ func NewPageSendForm() *PageSendForm {
 	buttonOptions.Label.Alignment = text.Middle
 	buttonOptions.Style.Font.Weight = font.Bold
 
+	batchIcon, _ := widget.NewIcon(icons.ActionList)
+	buttonBatchSend := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Icon:      batchIcon,
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonBatchSend.Label.Alignment = text.Middle
+	buttonBatchSend.Style.Font.Weight = font.Bold
+
 	buttonSetMax := components.NewButton(components.ButtonStyle{
 		TextSize: unit.Sp(16),
 	})
@@ -117,6 +136,7 @@ func NewPageSendForm() *PageSendForm {
 		list:                list,
 		buttonOptions:       buttonOptions,
 		buttonSetMax:        buttonSetMax,
+		buttonBatchSend:     buttonBatchSend,
 		balanceContainer:    balanceContainer,
 		walletAddrInput:     walletAddrInput,
 	}
@@ -163,6 +183,12 @@ func (p *PageSendForm) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		page_instance.header.AddHistory(PAGE_SEND_OPTIONS_FORM)
 	}
 
+	if p.buttonBatchSend.Clicked(gtx) {
+		page_instance.pageBatchSend.SetToken(p.token)
+		page_instance.pageRouter.SetCurrent(PAGE_BATCH_SEND)
+		page_instance.header.AddHistory(PAGE_BATCH_SEND)
+	}
+
 	if p.buttonSetMax.Clicked(gtx) {
 		wallet := wallet_manager.OpenedWallet
 		balance, _ := wallet.Memory.Get_Balance_scid(p.token.GetHash())
@@ -224,6 +250,11 @@ func (p *PageSendForm) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 			p.buttonOptions.Style.Colors = theme.Current.ButtonSecondaryColors
 			return p.buttonOptions.Layout(gtx, th)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonBatchSend.Text = lang.Translate("BATCH SEND")
+			p.buttonBatchSend.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonBatchSend.Layout(gtx, th)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			return prefabs.Divider(gtx, unit.Dp(5))
 		},
@@ -290,42 +321,74 @@ func (p *PageSendForm) prepareTx() error {
 	}
 
 	txtWalletAddr := p.walletAddrInput.txtWalletAddr
-	if txtWalletAddr.Value() == "" {
-		return fmt.Errorf(lang.Translate("Destination address is empty."))
-	}
-
 	txtComment := page_instance.pageSendOptionsForm.txtComment
 	txtDstPort := page_instance.pageSendOptionsForm.txtDstPort
 
+	scId := p.token.GetHash()
+	transfer, err := newTransfer(scId, txtWalletAddr.Value(), amount.Number, txtComment.Value(), txtDstPort.Value())
+	if err != nil {
+		return err
+	}
+
+	ringsize := uint64(p.ringSizeSelector.Size)
+	deroBalance, _ := wallet.Memory.Get_Balance()
+
+	transfers := []rpc.Transfer{transfer}
+
+	if scId.IsZero() && deroBalance == amount.Number {
+		// sender is trying to send entire Dero balance to another wallet
+		// let's calculate fees before and deduct
+		txFees := wallet.Memory.EstimateTxFees(len(transfers), int(ringsize), nil, transaction.NORMAL)
+		transfers[0].Amount = amount.Number - txFees
+	}
+
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfer: rpc.Transfer_Params{
+			Transfers: transfers,
+			Ringsize:  ringsize,
+		},
+		TokensInfo: []*wallet_manager.Token{p.token},
+	})
+
+	return nil
+}
+
+// newTransfer validates the destination (address, integrated address or registered name) and its arguments.
+// The comment and destination port are ignored if the integrated address provides the arguments.
+func newTransfer(scId crypto.Hash, addrValue string, amount uint64, comment string, destPortString string) (transfer rpc.Transfer, err error) {
+	if addrValue == "" {
+		return transfer, fmt.Errorf(lang.Translate("Destination address is empty."))
+	}
+
+	wallet := wallet_manager.OpenedWallet
 	var arguments rpc.Arguments
 
-	addrValue := txtWalletAddr.Value()
 	address, err := rpc.NewAddress(addrValue)
 	if err != nil {
 		addrString, err := wallet.Memory.NameToAddress(addrValue)
 
 		if err != nil {
 			if utils.IsErrLeafNotFound(err) {
-				return fmt.Errorf("address not found for [%s]", addrValue)
+				return transfer, fmt.Errorf("address not found for [%s]", addrValue)
 			}
 
-			return err
+			return transfer, err
 		}
 
 		address, err = rpc.NewAddress(addrString)
 		if err != nil {
-			return err
+			return transfer, err
 		}
 	}
 
 	if address.IsIntegratedAddress() {
 		err = address.Arguments.Validate_Arguments()
 		if err != nil {
-			return err
+			return transfer, err
 		}
 
 		if !address.Arguments.Has(rpc.RPC_DESTINATION_PORT, rpc.DataUint64) {
-			return fmt.Errorf(lang.Translate("The integrated address does not contain a destination port."))
+			return transfer, fmt.Errorf(lang.Translate("The integrated address does not contain a destination port."))
 		}
 
 		destinationPort := address.Arguments.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64)
@@ -339,20 +402,18 @@ func (p *PageSendForm) prepareTx() error {
 		if address.Arguments.Has(rpc.RPC_EXPIRY, rpc.DataTime) {
 			expireTime := address.Arguments.Value(rpc.RPC_EXPIRY, rpc.DataTime).(time.Time)
 			if expireTime.Before(time.Now().UTC()) {
-				return fmt.Errorf(lang.Translate("The integrated address has expired."))
+				return transfer, fmt.Errorf(lang.Translate("The integrated address has expired."))
 			}
 		}
 	} else {
-		comment := txtComment.Value()
 		if len(comment) > 0 {
 			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_COMMENT, DataType: rpc.DataString, Value: comment})
 		}
 
-		destPortString := txtDstPort.Value()
 		if len(destPortString) > 0 {
 			destPort, err := strconv.ParseUint(destPortString, 10, 64)
 			if err != nil {
-				return err
+				return transfer, err
 			}
 
 			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_DESTINATION_PORT, DataType: rpc.DataUint64, Value: destPort})
@@ -361,38 +422,17 @@ func (p *PageSendForm) prepareTx() error {
 
 	_, err = arguments.CheckPack(transaction.PAYLOAD0_LIMIT)
 	if err != nil {
-		return err
+		return transfer, err
 	}
 
-	scId := p.token.GetHash()
-	ringsize := uint64(p.ringSizeSelector.Size)
-	deroBalance, _ := wallet.Memory.Get_Balance()
-
-	transfers := []rpc.Transfer{
-		{
-			SCID:        scId,
-			Destination: address.String(),
-			Amount:      amount.Number,
-			Payload_RPC: arguments,
-		},
+	transfer = rpc.Transfer{
+		SCID:        scId,
+		Destination: address.String(),
+		Amount:      amount,
+		Payload_RPC: arguments,
 	}
 
-	if scId.IsZero() && deroBalance == amount.Number {
-		// sender is trying to send entire Dero balance to another wallet
-		// let's calculate fees before and deduct
-		txFees := wallet.Memory.EstimateTxFees(len(transfers), int(ringsize), nil, transaction.NORMAL)
-		transfers[0].Amount = amount.Number - txFees
-	}
-
-	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
-		Transfer: rpc.Transfer_Params{
-			Transfers: transfers,
-			Ringsize:  ringsize,
-		},
-		TokensInfo: []*wallet_manager.Token{p.token},
-	})
-
-	return nil
+	return transfer, nil
 }
 
 type TokenContainer struct {
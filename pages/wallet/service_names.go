@@ -90,7 +90,7 @@ func (p *PageServiceNames) Load() {
 	p.entries = make([]wallet_manager.Entry, 0)
 	wallet := wallet_manager.OpenedWallet
 
-	p.entries = wallet.GetEntries(&crypto.ZEROHASH, wallet_manager.GetEntriesParams{
+	p.entries, _ = wallet.GetEntries(&crypto.ZEROHASH, wallet_manager.GetEntriesParams{
 		SC_CALL: &wallet_manager.SCCallParams{
 			SCID:       sql.NullString{String: SERVICE_NAME_SCID.String(), Valid: true},
 			Entrypoint: sql.NullString{String: "Register", Valid: true},
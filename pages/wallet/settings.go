@@ -1,22 +1,27 @@
 package page_wallet
 
 import (
-	"encoding/csv"
+	"database/sql"
 	"fmt"
 	"image/color"
+	"strconv"
 
 	"gioui.org/font"
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_db"
 	"github.com/g45t345rt/g45w/app_icons"
 	"github.com/g45t345rt/g45w/app_instance"
 	"github.com/g45t345rt/g45w/components"
 	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
 	"github.com/g45t345rt/g45w/containers/notification_modal"
 	"github.com/g45t345rt/g45w/containers/password_modal"
+	"github.com/g45t345rt/g45w/containers/prompt_modal"
 	"github.com/g45t345rt/g45w/lang"
 	"github.com/g45t345rt/g45w/pages"
 	"github.com/g45t345rt/g45w/prefabs"
@@ -32,12 +37,20 @@ type PageSettings struct {
 	buttonDeleteWallet      *components.Button
 	buttonInfo              *components.Button
 	buttonServiceNames      *components.Button
+	buttonRPCServer         *components.Button
+	buttonOfflineTx         *components.Button
+	buttonOutgoingTxs       *components.Button
 	txtWalletName           *prefabs.TextField
 	txtWalletChangePassword *prefabs.TextField
 	buttonSave              *components.Button
 	buttonCleanWallet       *components.Button
 	buttonExportTxs         *components.Button
 	buttonAddDEXTokens      *components.Button
+	txtViewingPIN           *prefabs.TextField
+	buttonReadOnly          *components.Button
+	buttonWalletGroup       *components.Button
+
+	groupName string
 
 	headerPageAnimation *prefabs.PageHeaderAnimation
 
@@ -117,6 +130,54 @@ func NewPageSettings() *PageSettings {
 	buttonServiceNames.Label.Alignment = text.Middle
 	buttonServiceNames.Style.Font.Weight = font.Bold
 
+	rpcIcon, _ := widget.NewIcon(icons.ActionSettingsEthernet)
+	buttonRPCServer := components.NewButton(components.ButtonStyle{
+		Icon:      rpcIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonRPCServer.Label.Alignment = text.Middle
+	buttonRPCServer.Style.Font.Weight = font.Bold
+
+	offlineIcon, _ := widget.NewIcon(icons.ActionLock)
+	buttonOfflineTx := components.NewButton(components.ButtonStyle{
+		Icon:      offlineIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonOfflineTx.Label.Alignment = text.Middle
+	buttonOfflineTx.Style.Font.Weight = font.Bold
+
+	historyIcon, _ := widget.NewIcon(icons.ActionHistory)
+	buttonOutgoingTxs := components.NewButton(components.ButtonStyle{
+		Icon:      historyIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonOutgoingTxs.Label.Alignment = text.Middle
+	buttonOutgoingTxs.Style.Font.Weight = font.Bold
+
 	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
 	exportIcon, _ := widget.NewIcon(icons.EditorPublish)
 	buttonExportTxs := components.NewButton(components.ButtonStyle{
@@ -152,6 +213,34 @@ func NewPageSettings() *PageSettings {
 	buttonAddDEXTokens.Label.Alignment = text.Middle
 	buttonAddDEXTokens.Style.Font.Weight = font.Bold
 
+	groupIcon, _ := widget.NewIcon(icons.FileFolderOpen)
+	buttonWalletGroup := components.NewButton(components.ButtonStyle{
+		Icon:      groupIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonWalletGroup.Label.Alignment = text.Middle
+	buttonWalletGroup.Style.Font.Weight = font.Bold
+
+	lockIcon, _ := widget.NewIcon(icons.ActionLockOutline)
+	buttonReadOnly := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      lockIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonReadOnly.Label.Alignment = text.Middle
+	buttonReadOnly.Style.Font.Weight = font.Bold
+
 	list := new(widget.List)
 	list.Axis = layout.Vertical
 
@@ -170,7 +259,13 @@ func NewPageSettings() *PageSettings {
 		buttonCleanWallet:       buttonCleanWallet,
 		buttonExportTxs:         buttonExportTxs,
 		buttonServiceNames:      buttonServiceNames,
+		buttonRPCServer:         buttonRPCServer,
+		buttonOfflineTx:         buttonOfflineTx,
+		buttonOutgoingTxs:       buttonOutgoingTxs,
 		buttonAddDEXTokens:      buttonAddDEXTokens,
+		txtViewingPIN:           prefabs.NewPasswordTextField(),
+		buttonReadOnly:          buttonReadOnly,
+		buttonWalletGroup:       buttonWalletGroup,
 	}
 }
 
@@ -187,6 +282,14 @@ func (p *PageSettings) Enter() {
 	page_instance.header.RightLayout = nil
 	page_instance.header.LeftLayout = nil
 
+	p.groupName = ""
+	if openedWallet.Info.GroupId.Valid {
+		group, err := app_db.GetWalletGroup(openedWallet.Info.GroupId.Int64)
+		if err == nil {
+			p.groupName = group.Name
+		}
+	}
+
 	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
 }
 
@@ -210,6 +313,51 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		page_instance.header.AddHistory(PAGE_SERVICE_NAMES)
 	}
 
+	if p.buttonRPCServer.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_RPC_SERVER)
+		page_instance.header.AddHistory(PAGE_RPC_SERVER)
+	}
+
+	if p.buttonOfflineTx.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_OFFLINE_TX)
+		page_instance.header.AddHistory(PAGE_OFFLINE_TX)
+	}
+
+	if p.buttonOutgoingTxs.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_OUTGOING_TXS)
+		page_instance.header.AddHistory(PAGE_OUTGOING_TXS)
+	}
+
+	if p.buttonWalletGroup.Clicked(gtx) {
+		go func() {
+			err := p.selectWalletGroup()
+			if err != nil {
+				notification_modal.Open(notification_modal.Params{
+					Type:  notification_modal.ERROR,
+					Title: lang.Translate("Error"),
+					Text:  err.Error(),
+				})
+			}
+		}()
+	}
+
+	if p.buttonReadOnly.Clicked(gtx) {
+		p.action = "read_only"
+		if wallet_manager.OpenedWallet.Info.ReadOnly {
+			password_modal.Instance.SetVisible(true)
+		} else {
+			go func() {
+				yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+					Title:  lang.Translate("Read-only mode"),
+					Prompt: lang.Translate("The wallet password is stored on this device encrypted with the viewing PIN. Anyone with a copy of the app data can try to guess the PIN to recover the password. Use a long PIN that you don't use anywhere else. The read-only mode is not included in the app backups."),
+				})
+				if yes {
+					password_modal.Instance.SetVisible(true)
+				}
+			}()
+		}
+	}
+
 	if p.buttonInfo.Clicked(gtx) {
 		p.action = "wallet_info"
 		password_modal.Instance.SetVisible(true)
@@ -294,6 +442,54 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 				}),
 			)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonRPCServer.Text = lang.Translate("RPC Server")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonRPCServer.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonRPCServer.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Use the wallet from your scripts with JSON-RPC."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonOutgoingTxs.Text = lang.Translate("Outgoing Transactions")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonOutgoingTxs.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonOutgoingTxs.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Search and audit every transaction sent from this wallet."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonOfflineTx.Text = lang.Translate("Offline Signing")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonOfflineTx.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonOfflineTx.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Prepare, sign and broadcast transactions of an air-gapped wallet."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonInfo.Text = lang.Translate("Wallet Information")
 
@@ -316,6 +512,26 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		func(gtx layout.Context) layout.Dimensions {
 			return p.txtWalletName.Layout(gtx, th, lang.Translate("Wallet Name"), "")
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			if p.groupName != "" {
+				p.buttonWalletGroup.Text = p.groupName
+			} else {
+				p.buttonWalletGroup.Text = lang.Translate("No Group")
+			}
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonWalletGroup.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonWalletGroup.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Group the wallet with others on the wallet selection page."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			return p.txtWalletChangePassword.Layout(gtx, th, lang.Translate("Change Password"), "Enter new password")
 		},
@@ -327,6 +543,39 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		func(gtx layout.Context) layout.Dimensions {
 			return prefabs.Divider(gtx, unit.Dp(5))
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			wallet := wallet_manager.OpenedWallet
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if wallet.Info.ReadOnly {
+						return layout.Dimensions{}
+					}
+
+					return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return p.txtViewingPIN.Layout(gtx, th, lang.Translate("Viewing PIN"), lang.Translate("Enter viewing PIN"))
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if wallet.Info.ReadOnly {
+						p.buttonReadOnly.Text = lang.Translate("DISABLE READ-ONLY MODE")
+					} else {
+						p.buttonReadOnly.Text = lang.Translate("ENABLE READ-ONLY MODE")
+					}
+
+					p.buttonReadOnly.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonReadOnly.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("The wallet can be opened with the viewing PIN to check balances and history. Sending, XSWD, registration and offline signing require opening the wallet with the full password. Changing the password disables the read-only mode."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -351,7 +600,7 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 				}),
 				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					lbl := material.Label(th, unit.Sp(14), lang.Translate("Export all your transactions into a CSV file."))
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Export your transactions as CSV, Koinly CSV or JSON with date and token filters."))
 					lbl.Color = theme.Current.TextMuteColor
 					return lbl.Layout(gtx)
 				}),
@@ -405,6 +654,10 @@ func (p *PageSettings) submitForm(gtx layout.Context, password string) error {
 		page_instance.header.AddHistory(PAGE_WALLET_INFO)
 	case "clean_wallet":
 		wallet.Memory.Clean()
+		err := wallet.SyncEntries()
+		if err != nil {
+			return err
+		}
 
 		notification_modal.Open(notification_modal.Params{
 			Type:       notification_modal.SUCCESS,
@@ -429,69 +682,29 @@ func (p *PageSettings) submitForm(gtx layout.Context, password string) error {
 			CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
 		})
 	case "export_txs":
-		go func() error {
-			setError := func(err error) error {
-				p.buttonExportTxs.SetLoading(false)
-				notification_modal.Open(notification_modal.Params{
-					Type:  notification_modal.ERROR,
-					Title: lang.Translate("Error"),
-					Text:  err.Error(),
-				})
+		page_instance.pageRouter.SetCurrent(PAGE_EXPORT_TXS)
+		page_instance.header.AddHistory(PAGE_EXPORT_TXS)
+	case "read_only":
+		if wallet.Info.ReadOnly {
+			err := wallet.DisableReadOnly()
+			if err != nil {
 				return err
 			}
-
-			notification_modal.Open(notification_modal.Params{
-				Type:       notification_modal.INFO,
-				Title:      lang.Translate("Info"),
-				Text:       lang.Translate("Exporting transactions..."),
-				CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
-			})
-
-			account := wallet.Memory.GetAccount()
-			p.buttonExportTxs.SetLoading(true)
-
-			file, err := app_instance.Explorer.CreateFile("transactions.csv")
+		} else {
+			err := wallet.EnableReadOnly(password, p.txtViewingPIN.Value())
 			if err != nil {
-				return setError(err)
-			}
-
-			writer := csv.NewWriter(file)
-			defer writer.Flush()
-
-			header := []string{"SCID", "TXID", "Height", "Blockhash",
-				"Coinbase", "Incoming", "Destination", "Atomic Amount",
-				"Atomic Burn", "Atomic Fees", "Proof", "Time", "EWData",
-				"Sender", "Destination Port", "Source Port"}
-			err = writer.Write(header)
-			if err != nil {
-				return setError(err)
+				return err
 			}
 
-			for scId, entries := range account.EntriesNative {
-				for _, entry := range entries {
-					sender := wallet.GetTxSender(wallet_manager.Entry{Entry: entry})
-					destination := wallet.GetTxDestination(wallet_manager.Entry{Entry: entry})
-
-					row := []string{scId.String(), entry.TXID, fmt.Sprint(entry.Height), entry.BlockHash,
-						fmt.Sprint(entry.Coinbase), fmt.Sprint(entry.Incoming), destination, fmt.Sprint(entry.Amount),
-						fmt.Sprint(entry.Burn), fmt.Sprint(entry.Fees), entry.Proof, entry.Time.String(), entry.EWData,
-						sender, fmt.Sprint(entry.DestinationPort), fmt.Sprint(entry.SourcePort)}
-					err = writer.Write(row)
-					if err != nil {
-						return setError(err)
-					}
-				}
-			}
+			p.txtViewingPIN.SetValue("")
+		}
 
-			p.buttonExportTxs.SetLoading(false)
-			notification_modal.Open(notification_modal.Params{
-				Type:       notification_modal.SUCCESS,
-				Title:      lang.Translate("Success"),
-				Text:       lang.Translate("Transactions exported."),
-				CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
-			})
-			return nil
-		}()
+		notification_modal.Open(notification_modal.Params{
+			Type:       notification_modal.SUCCESS,
+			Title:      lang.Translate("Success"),
+			Text:       lang.Translate("Data saved."),
+			CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+		})
 	case "save_changes":
 		newWalletName := p.txtWalletName.Value()
 		if wallet.Info.Name != newWalletName {
@@ -521,3 +734,85 @@ func (p *PageSettings) submitForm(gtx layout.Context, password string) error {
 
 	return nil
 }
+
+func (p *PageSettings) selectWalletGroup() error {
+	groups, err := app_db.GetWalletGroups()
+	if err != nil {
+		return err
+	}
+
+	groupIcon, _ := widget.NewIcon(icons.FileFolder)
+	noGroupIcon, _ := widget.NewIcon(icons.ContentClear)
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+
+	var items []*listselect_modal.SelectListItem
+	for _, group := range groups {
+		items = append(items, listselect_modal.NewSelectListItem(fmt.Sprint(group.ID),
+			listselect_modal.NewItemText(groupIcon, group.Name).Layout,
+		))
+	}
+
+	items = append(items,
+		listselect_modal.NewSelectListItem("no_group",
+			listselect_modal.NewItemText(noGroupIcon, lang.Translate("No Group")).Layout,
+		),
+		listselect_modal.NewSelectListItem("new_group",
+			listselect_modal.NewItemText(addIcon, lang.Translate("New Group")).Layout,
+		),
+	)
+
+	wallet := wallet_manager.OpenedWallet
+	activeKey := "no_group"
+	if wallet.Info.GroupId.Valid {
+		activeKey = fmt.Sprint(wallet.Info.GroupId.Int64)
+	}
+
+	for selectedKey := range listselect_modal.Instance.Open(items, activeKey) {
+		var groupId sql.NullInt64
+		groupName := ""
+
+		switch selectedKey {
+		case "no_group":
+		case "new_group":
+			name := <-prompt_modal.Instance.Open("", lang.Translate("Enter group name"), key.HintAny)
+			if name == "" {
+				return nil
+			}
+
+			id, err := app_db.InsertWalletGroup(app_db.WalletGroup{Name: name})
+			if err != nil {
+				return err
+			}
+
+			groupId = sql.NullInt64{Int64: id, Valid: true}
+			groupName = name
+		default:
+			id, err := strconv.ParseInt(selectedKey, 10, 64)
+			if err != nil {
+				return err
+			}
+
+			for _, group := range groups {
+				if group.ID == id {
+					groupName = group.Name
+				}
+			}
+
+			groupId = sql.NullInt64{Int64: id, Valid: true}
+		}
+
+		if groupId == wallet.Info.GroupId {
+			return nil
+		}
+
+		err = wallet.SetGroup(groupId)
+		if err != nil {
+			return err
+		}
+
+		p.groupName = groupName
+		app_instance.Window.Invalidate()
+	}
+
+	return nil
+}
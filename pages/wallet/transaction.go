@@ -12,6 +12,7 @@ import (
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
@@ -45,6 +46,7 @@ type PageTransaction struct {
 	scDataEditor            *widget.Editor
 	infoRows                []*prefabs.InfoRow
 	txTransfers             *TxTransfers
+	txAnnotationForm        *TxAnnotationForm
 
 	payloadList []*RPCArgInfo
 
@@ -80,6 +82,7 @@ func NewPageTransaction() *PageTransaction {
 		scDataEditor:            &widget.Editor{ReadOnly: true},
 		infoRows:                prefabs.NewInfoRows(3),
 		txTransfers:             NewTxTransfers(),
+		txAnnotationForm:        NewTxAnnotationForm(),
 		buttonViewExplorer:      buttonViewExplorer,
 
 		txTypeImg: txTypeImg,
@@ -175,6 +178,7 @@ func (p *PageTransaction) Leave() {
 func (p *PageTransaction) SetEntry(e wallet_manager.Entry) {
 	p.entry = e
 	p.txTransfers.Load(e)
+	p.txAnnotationForm.Load(e)
 }
 
 func (p *PageTransaction) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
@@ -255,6 +259,12 @@ func (p *PageTransaction) Layout(gtx layout.Context, th *material.Theme) layout.
 		}
 	}
 
+	if p.entry.TXID != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.txAnnotationForm.Layout(gtx, th)
+		})
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -489,7 +499,7 @@ func (t *TxTransfers) Load(entry wallet_manager.Entry) {
 
 	t.items = make([]*TxTransferItem, 0)
 	if entry.TXID != "" {
-		entries := wallet.GetEntries(nil, wallet_manager.GetEntriesParams{
+		entries, _ := wallet.GetEntries(nil, wallet_manager.GetEntriesParams{
 			TXID: sql.NullString{String: entry.TXID, Valid: true},
 		})
 
@@ -528,3 +538,104 @@ func (t *TxTransfers) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 		}),
 	)
 }
+
+type TxAnnotationForm struct {
+	entry       wallet_manager.Entry
+	txtNote     *prefabs.TextField
+	txtCategory *prefabs.TextField
+	txtTags     *prefabs.TextField
+	buttonSave  *components.Button
+}
+
+func NewTxAnnotationForm() *TxAnnotationForm {
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	buttonSave := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      saveIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSave.Label.Alignment = text.Middle
+	buttonSave.Style.Font.Weight = font.Bold
+
+	txtNote := prefabs.NewTextField()
+	txtNote.Editor().SingleLine = false
+	txtNote.Editor().Submit = false
+
+	return &TxAnnotationForm{
+		txtNote:     txtNote,
+		txtCategory: prefabs.NewTextField(),
+		txtTags:     prefabs.NewTextField(),
+		buttonSave:  buttonSave,
+	}
+}
+
+func (t *TxAnnotationForm) Load(entry wallet_manager.Entry) {
+	t.entry = entry
+
+	wallet := wallet_manager.OpenedWallet
+	annotation, _ := wallet.GetTxAnnotation(entry.TXID, entry.SCID.String())
+	t.txtNote.SetValue(annotation.Note)
+	t.txtCategory.SetValue(annotation.Category)
+	t.txtTags.SetValue(annotation.Tags)
+}
+
+func (t *TxAnnotationForm) submit() error {
+	wallet := wallet_manager.OpenedWallet
+	return wallet.StoreTxAnnotation(wallet_manager.TxAnnotation{
+		TXID:     t.entry.TXID,
+		SCID:     t.entry.SCID.String(),
+		Note:     t.txtNote.Value(),
+		Category: t.txtCategory.Value(),
+		Tags:     t.txtTags.Value(),
+	})
+}
+
+func (t *TxAnnotationForm) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if t.buttonSave.Clicked(gtx) {
+		err := t.submit()
+		if err != nil {
+			notification_modal.Open(notification_modal.Params{
+				Type:  notification_modal.ERROR,
+				Title: lang.Translate("Error"),
+				Text:  err.Error(),
+			})
+		} else {
+			notification_modal.Open(notification_modal.Params{
+				Type:       notification_modal.SUCCESS,
+				Title:      lang.Translate("Success"),
+				Text:       lang.Translate("Data saved."),
+				CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+			})
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Annotation"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			t.txtNote.Input.EditorMinY = gtx.Dp(75)
+			return t.txtNote.Layout(gtx, th, lang.Translate("Note"), "")
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return t.txtCategory.Layout(gtx, th, lang.Translate("Category"), "")
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return t.txtTags.Layout(gtx, th, lang.Translate("Tags"), lang.Translate("Separate tags with a comma."))
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			t.buttonSave.Text = lang.Translate("SAVE ANNOTATION")
+			t.buttonSave.Style.Colors = theme.Current.ButtonPrimaryColors
+			return t.buttonSave.Layout(gtx, th)
+		}),
+	)
+}
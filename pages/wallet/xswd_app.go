@@ -1,7 +1,12 @@
 package page_wallet
 
 import (
+	"fmt"
 	"image"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gioui.org/font"
 	"gioui.org/io/pointer"
@@ -9,16 +14,22 @@ import (
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/globals"
 	"github.com/deroproject/derohe/walletapi/xswd"
+	"github.com/g45t345rt/g45w/app_instance"
 	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
 	"github.com/g45t345rt/g45w/containers/notification_modal"
 	"github.com/g45t345rt/g45w/lang"
 	"github.com/g45t345rt/g45w/prefabs"
 	"github.com/g45t345rt/g45w/router"
 	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
 	"github.com/g45t345rt/g45w/wallet_manager"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 )
@@ -28,10 +39,12 @@ type PageXSWDApp struct {
 
 	headerPageAnimation *prefabs.PageHeaderAnimation
 
-	buttonRemove *components.Button
-	list         *widget.List
-	app          xswd.ApplicationData
-	permissions  []*DAppPermissionItem
+	buttonRemove    *components.Button
+	buttonAddPolicy *components.Button
+	list            *widget.List
+	app             xswd.ApplicationData
+	permissions     []*DAppPermissionItem
+	policies        []*DAppPolicyItem
 }
 
 var _ router.Page = &PageXSWDApp{}
@@ -43,6 +56,23 @@ func NewPageXSWDApp() *PageXSWDApp {
 		Icon: removeIcon,
 	})
 
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	buttonAddPolicy := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonAddPolicy.Label.Alignment = text.Middle
+	buttonAddPolicy.Style.Font.Weight = font.Bold
+
 	list := new(widget.List)
 	list.Axis = layout.Vertical
 
@@ -51,6 +81,7 @@ func NewPageXSWDApp() *PageXSWDApp {
 		headerPageAnimation: headerPageAnimation,
 		list:                list,
 		buttonRemove:        buttonRemove,
+		buttonAddPolicy:     buttonAddPolicy,
 	}
 }
 
@@ -58,11 +89,41 @@ func (p *PageXSWDApp) IsActive() bool {
 	return p.isActive
 }
 
-func (p *PageXSWDApp) Load() {
+func (p *PageXSWDApp) Load() error {
+	wallet := wallet_manager.OpenedWallet
+	storedApp, err := wallet.GetXSWDApp(p.app.Id)
+	if err != nil {
+		return err
+	}
+
+	permissions := p.app.Permissions
+	if storedApp != nil {
+		permissions = storedApp.Permissions
+	}
+
+	var methods []string
+	for method := range permissions {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
 	p.permissions = make([]*DAppPermissionItem, 0)
-	for name, perm := range p.app.Permissions {
-		p.permissions = append(p.permissions, NewDAppPermissionItem(name, perm))
+	for _, method := range methods {
+		p.permissions = append(p.permissions, NewDAppPermissionItem(method, permissions[method]))
+	}
+
+	policies, err := wallet.GetXSWDPolicies(p.app.Id)
+	if err != nil {
+		return err
 	}
+
+	p.policies = make([]*DAppPolicyItem, 0)
+	for _, policy := range policies {
+		p.policies = append(p.policies, NewDAppPolicyItem(policy))
+	}
+
+	app_instance.Window.Invalidate()
+	return nil
 }
 
 func (p *PageXSWDApp) Enter() {
@@ -84,10 +145,27 @@ func (p *PageXSWDApp) Enter() {
 		gtx.Constraints.Min.X = gtx.Dp(30)
 		gtx.Constraints.Min.Y = gtx.Dp(30)
 
-		xswd := wallet_manager.OpenedWallet.ServerXSWD
 		if p.buttonRemove.Clicked(gtx) {
 			go func() {
-				xswd.RemoveApplication(&p.app)
+				yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+					Prompt: lang.Translate("The app and its permissions will be removed. The app will have to ask for your authorization again."),
+				})
+
+				if !yes {
+					return
+				}
+
+				wallet := wallet_manager.OpenedWallet
+				err := wallet.RevokeXSWDApp(p.app.Id)
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+					return
+				}
+
 				page_instance.header.GoBack()
 				page_instance.pageXSWDManage.Load()
 				notification_modal.Open(notification_modal.Params{
@@ -102,7 +180,14 @@ func (p *PageXSWDApp) Enter() {
 		return p.buttonRemove.Layout(gtx, th)
 	}
 
-	p.Load()
+	err := p.Load()
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
 }
 
 func (p *PageXSWDApp) Leave() {
@@ -112,6 +197,11 @@ func (p *PageXSWDApp) Leave() {
 func (p *PageXSWDApp) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
 
+	if p.buttonAddPolicy.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_XSWD_POLICY_FORM)
+		page_instance.header.AddHistory(PAGE_XSWD_POLICY_FORM)
+	}
+
 	widgets := []layout.Widget{}
 
 	for i := range p.permissions {
@@ -121,6 +211,35 @@ func (p *PageXSWDApp) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 		})
 	}
 
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Spending Policies"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+	})
+
+	if len(p.policies) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Every transfer of this app requires your confirmation."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range p.policies {
+		policy := p.policies[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return policy.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonAddPolicy.Text = lang.Translate("ADD SPENDING POLICY")
+		p.buttonAddPolicy.Style.Colors = theme.Current.ButtonSecondaryColors
+		return p.buttonAddPolicy.Layout(gtx, th)
+	})
+
 	listStyle := material.List(th, p.list)
 	listStyle.AnchorStrategy = material.Overlay
 
@@ -146,9 +265,58 @@ func NewDAppPermissionItem(name string, perm xswd.Permission) *DAppPermissionIte
 	}
 }
 
+func permissionText(perm xswd.Permission) string {
+	switch perm {
+	case xswd.Ask:
+		return lang.Translate("Ask")
+	case xswd.AlwaysAllow:
+		return lang.Translate("Allow Always")
+	case xswd.AlwaysDeny:
+		return lang.Translate("Deny Always")
+	}
+
+	return perm.String()
+}
+
+func (item *DAppPermissionItem) changePermission() {
+	var items []*listselect_modal.SelectListItem
+	for _, perm := range []xswd.Permission{xswd.Ask, xswd.AlwaysAllow, xswd.AlwaysDeny} {
+		name := permissionText(perm)
+		items = append(items, listselect_modal.NewSelectListItem(fmt.Sprint(int(perm)),
+			func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), name)
+				return lbl.Layout(gtx)
+			},
+		))
+	}
+
+	keyChan := listselect_modal.Instance.Open(items, fmt.Sprint(int(item.perm)))
+	key := <-keyChan
+
+	value, err := strconv.Atoi(key)
+	if err != nil {
+		return
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	app := page_instance.pageXSWDApp.app
+	err = wallet.UpdateXSWDAppPermission(app.Id, item.name, xswd.Permission(value))
+	if err == nil {
+		err = page_instance.pageXSWDApp.Load()
+	}
+
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
+}
+
 func (item *DAppPermissionItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	if item.clickable.Clicked(gtx) {
-		// TODO
+		go item.changePermission()
 	}
 
 	m := op.Record(gtx.Ops)
@@ -164,7 +332,7 @@ func (item *DAppPermissionItem) Layout(gtx layout.Context, th *material.Theme) l
 					return lbl.Layout(gtx)
 				}),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					lbl := material.Label(th, unit.Sp(14), item.perm.String())
+					lbl := material.Label(th, unit.Sp(14), permissionText(item.perm))
 					return lbl.Layout(gtx)
 				}),
 			)
@@ -194,3 +362,106 @@ func (item *DAppPermissionItem) Layout(gtx layout.Context, th *material.Theme) l
 
 	return dims
 }
+
+type DAppPolicyItem struct {
+	policy    wallet_manager.XSWDPolicy
+	clickable *widget.Clickable
+}
+
+func NewDAppPolicyItem(policy wallet_manager.XSWDPolicy) *DAppPolicyItem {
+	return &DAppPolicyItem{
+		policy:    policy,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *DAppPolicyItem) remove() {
+	yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: lang.Translate("Remove this spending policy? Transfers will require your confirmation again."),
+	})
+
+	if !yes {
+		return
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	err := wallet.DelXSWDPolicy(item.policy.ID)
+	if err == nil {
+		err = page_instance.pageXSWDApp.Load()
+	}
+
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
+}
+
+func (item *DAppPolicyItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if item.clickable.Clicked(gtx) {
+		go item.remove()
+	}
+
+	m := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(13), Bottom: unit.Dp(13),
+			Left: unit.Dp(15), Right: unit.Dp(15),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					scId := lang.Translate("Any smart contract")
+					if item.policy.SCID != "" {
+						scId = utils.ReduceTxId(item.policy.SCID)
+					}
+
+					entrypoint := lang.Translate("Any entrypoint")
+					if item.policy.Entrypoint != "" {
+						entrypoint = item.policy.Entrypoint
+					}
+
+					lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("%s - %s", scId, entrypoint))
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("{} DERO per day")
+					txt = strings.Replace(txt, "{}", globals.FormatMoney(item.policy.DeroLimit), -1)
+					if len(item.policy.TokenLimits) > 0 {
+						tokenTxt := lang.Translate("{} token limits")
+						tokenTxt = strings.Replace(tokenTxt, "{}", fmt.Sprint(len(item.policy.TokenLimits)), -1)
+						txt = fmt.Sprintf("%s, %s", txt, tokenTxt)
+					}
+
+					lbl := material.Label(th, unit.Sp(14), txt)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+	})
+	c := m.Stop()
+
+	if item.clickable.Hovered() {
+		pointer.CursorPointer.Add(gtx.Ops)
+		paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+			clip.UniformRRect(
+				image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+				gtx.Dp(10),
+			).Op(gtx.Ops),
+		)
+	} else {
+		paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+			clip.UniformRRect(
+				image.Rectangle{Max: dims.Size},
+				gtx.Dp(10),
+			).Op(gtx.Ops),
+		)
+	}
+
+	c.Add(gtx.Ops)
+
+	return dims
+}
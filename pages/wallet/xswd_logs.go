@@ -0,0 +1,377 @@
+package page_wallet
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageXSWDLogs struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	buttonExport        *components.Button
+	buttonFilterApp     *components.Button
+	buttonFilterMethod  *components.Button
+
+	filterAppUrl sql.NullString
+	filterMethod sql.NullString
+
+	logs []wallet_manager.XSWDLog
+	list *widget.List
+}
+
+var _ router.Page = &PageXSWDLogs{}
+
+func NewPageXSWDLogs() *PageXSWDLogs {
+	exportIcon, _ := widget.NewIcon(icons.EditorPublish)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonExport := components.NewButton(components.ButtonStyle{
+		Icon:        exportIcon,
+		LoadingIcon: loadingIcon,
+	})
+
+	filterIcon, _ := widget.NewIcon(icons.ContentFilterList)
+	buttonFilterApp := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      filterIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonFilterApp.Label.Alignment = text.Middle
+	buttonFilterApp.Style.Font.Weight = font.Bold
+
+	buttonFilterMethod := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      filterIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonFilterMethod.Label.Alignment = text.Middle
+	buttonFilterMethod.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_XSWD_LOGS)
+	return &PageXSWDLogs{
+		headerPageAnimation: headerPageAnimation,
+		buttonExport:        buttonExport,
+		buttonFilterApp:     buttonFilterApp,
+		buttonFilterMethod:  buttonFilterMethod,
+		list:                list,
+	}
+}
+
+func (p *PageXSWDLogs) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageXSWDLogs) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Request Logs")
+	}
+
+	page_instance.header.Subtitle = nil
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		p.buttonExport.Style.Colors = theme.Current.ButtonIconPrimaryColors
+		gtx.Constraints.Min.X = gtx.Dp(30)
+		gtx.Constraints.Min.Y = gtx.Dp(30)
+
+		if p.buttonExport.Clicked(gtx) {
+			go func() {
+				err := p.exportCSV()
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+				}
+			}()
+		}
+
+		return p.buttonExport.Layout(gtx, th)
+	}
+
+	p.Load()
+}
+
+func (p *PageXSWDLogs) Load() {
+	wallet := wallet_manager.OpenedWallet
+	logs, err := wallet.GetXSWDLogs(wallet_manager.GetXSWDLogsParams{
+		AppUrl: p.filterAppUrl,
+		Method: p.filterMethod,
+	})
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+		return
+	}
+
+	p.logs = logs
+	app_instance.Window.Invalidate()
+}
+
+func (p *PageXSWDLogs) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+// openFilter lets the user select a value of the column or all values (empty key)
+func (p *PageXSWDLogs) openFilter(column string, current sql.NullString) (sql.NullString, bool) {
+	wallet := wallet_manager.OpenedWallet
+	values, err := wallet.GetXSWDLogValues(column)
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+		return current, false
+	}
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("", func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("All"))
+			return lbl.Layout(gtx)
+		}),
+	}
+
+	for _, value := range values {
+		txt := value
+		items = append(items, listselect_modal.NewSelectListItem(value, func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), txt)
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	key := <-listselect_modal.Instance.Open(items, current.String)
+	return sql.NullString{String: key, Valid: key != ""}, true
+}
+
+func (p *PageXSWDLogs) exportCSV() error {
+	wallet := wallet_manager.OpenedWallet
+	p.buttonExport.SetLoading(true)
+	defer p.buttonExport.SetLoading(false)
+
+	logs, err := wallet.GetXSWDLogs(wallet_manager.GetXSWDLogsParams{
+		AppUrl: p.filterAppUrl,
+		Method: p.filterMethod,
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile("xswd_logs.csv")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{"Time", "App ID", "App Name", "App URL", "Method",
+		"Params", "Permission", "TXID", "Error"}
+	err = writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, log := range logs {
+		date := time.Unix(log.Timestamp, 0)
+		row := []string{date.Format(time.RFC3339), log.AppId, log.AppName, log.AppUrl, log.Method,
+			log.Params, log.Permission.String(), log.TxId.String, log.Error.String}
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	notification_modal.Open(notification_modal.Params{
+		Type:       notification_modal.SUCCESS,
+		Title:      lang.Translate("Success"),
+		Text:       lang.Translate("Logs exported."),
+		CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+	})
+	return nil
+}
+
+func (p *PageXSWDLogs) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonFilterApp.Clicked(gtx) {
+		go func() {
+			value, ok := p.openFilter("app_url", p.filterAppUrl)
+			if ok {
+				p.filterAppUrl = value
+				p.Load()
+			}
+		}()
+	}
+
+	if p.buttonFilterMethod.Clicked(gtx) {
+		go func() {
+			value, ok := p.openFilter("method", p.filterMethod)
+			if ok {
+				p.filterMethod = value
+				p.Load()
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("All apps")
+					if p.filterAppUrl.Valid {
+						txt = p.filterAppUrl.String
+					}
+
+					p.buttonFilterApp.Text = txt
+					p.buttonFilterApp.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonFilterApp.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					txt := lang.Translate("All methods")
+					if p.filterMethod.Valid {
+						txt = p.filterMethod.String
+					}
+
+					p.buttonFilterMethod.Text = txt
+					p.buttonFilterMethod.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonFilterMethod.Layout(gtx, th)
+				}),
+			)
+		},
+	}
+
+	if len(p.logs) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("No requests."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range p.logs {
+		log := p.logs[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return xswdLogLayout(gtx, th, log)
+		})
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(10),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func xswdLogLayout(gtx layout.Context, th *material.Theme, log wallet_manager.XSWDLog) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := layout.Inset{
+		Top: unit.Dp(13), Bottom: unit.Dp(13),
+		Left: unit.Dp(15), Right: unit.Dp(15),
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), log.Method)
+						lbl.Font.Weight = font.Bold
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						date := time.Unix(log.Timestamp, 0)
+						lbl := material.Label(th, unit.Sp(14), date.Format("2006-01-02 15:04"))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), fmt.Sprintf("%s (%s)", log.AppName, log.AppUrl))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if log.Params == "" {
+					return layout.Dimensions{}
+				}
+
+				lbl := material.Label(th, unit.Sp(14), log.Params)
+				lbl.Color = theme.Current.TextMuteColor
+				lbl.MaxLines = 2
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				txt := permissionText(log.Permission)
+				if log.TxId.Valid {
+					txt = fmt.Sprintf("%s - %s", txt, utils.ReduceTxId(log.TxId.String))
+				}
+
+				if log.Error.Valid {
+					txt = fmt.Sprintf("%s - %s", txt, log.Error.String)
+				}
+
+				lbl := material.Label(th, unit.Sp(14), txt)
+				if !log.Permission.IsPositive() || log.Error.Valid {
+					lbl.Color = theme.Current.TextMuteColor
+				}
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops),
+	)
+
+	c.Add(gtx.Ops)
+	return dims
+}
@@ -2,6 +2,7 @@ package page_wallet
 
 import (
 	"image"
+	"image/color"
 
 	"gioui.org/font"
 	"gioui.org/io/pointer"
@@ -9,6 +10,7 @@ import (
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
@@ -30,6 +32,7 @@ type PageXSWDManage struct {
 	headerPageAnimation *prefabs.PageHeaderAnimation
 	buttonStart         *components.Button
 	buttonStop          *components.Button
+	buttonLogs          *components.Button
 
 	list *widget.List
 	apps []*DAppItem
@@ -49,6 +52,23 @@ func NewPageXSWDManage() *PageXSWDManage {
 		Icon: stopIcon,
 	})
 
+	logsIcon, _ := widget.NewIcon(icons.ActionHistory)
+	buttonLogs := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      logsIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonLogs.Label.Alignment = text.Middle
+	buttonLogs.Style.Font.Weight = font.Bold
+
 	list := new(widget.List)
 	list.Axis = layout.Vertical
 
@@ -57,6 +77,7 @@ func NewPageXSWDManage() *PageXSWDManage {
 		headerPageAnimation: headerPageAnimation,
 		buttonStop:          buttonStop,
 		buttonStart:         buttonStart,
+		buttonLogs:          buttonLogs,
 		list:                list,
 		apps:                make([]*DAppItem, 0),
 	}
@@ -95,6 +116,10 @@ func (p *PageXSWDManage) Enter() {
 		if p.buttonStart.Clicked(gtx) {
 			go func() {
 				err := page_instance.OpenXSWD()
+				if err == nil {
+					err = p.Load()
+				}
+
 				if err != nil {
 					notification_modal.Open(notification_modal.Params{
 						Type:  notification_modal.ERROR,
@@ -102,27 +127,44 @@ func (p *PageXSWDManage) Enter() {
 						Text:  err.Error(),
 					})
 				}
-				p.Load()
 			}()
 		}
 
 		return p.buttonStart.Layout(gtx, th)
 	}
 
-	p.Load()
+	err := p.Load()
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+	}
 }
 
-func (p *PageXSWDManage) Load() {
-	xswd := wallet_manager.OpenedWallet.ServerXSWD
+func (p *PageXSWDManage) Load() error {
+	wallet := wallet_manager.OpenedWallet
+	storedApps, err := wallet.GetXSWDApps()
+	if err != nil {
+		return err
+	}
+
+	connectedApps := make(map[string]bool)
+	xswd := wallet.ServerXSWD
 	if xswd != nil && xswd.IsRunning() {
-		connectedApps := xswd.GetApplications()
-		p.apps = make([]*DAppItem, 0)
-		for _, app := range connectedApps {
-			p.apps = append(p.apps, NewDAppItem(app))
+		for _, app := range xswd.GetApplications() {
+			connectedApps[app.Id] = true
 		}
 	}
 
+	p.apps = make([]*DAppItem, 0)
+	for _, storedApp := range storedApps {
+		p.apps = append(p.apps, NewDAppItem(storedApp.ApplicationData(), connectedApps[storedApp.ID]))
+	}
+
 	app_instance.Window.Invalidate()
+	return nil
 }
 
 func (p *PageXSWDManage) Leave() {
@@ -132,30 +174,41 @@ func (p *PageXSWDManage) Leave() {
 func (p *PageXSWDManage) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
 
-	widgets := []layout.Widget{}
+	if p.buttonLogs.Clicked(gtx) {
+		page_instance.pageRouter.SetCurrent(PAGE_XSWD_LOGS)
+		page_instance.header.AddHistory(PAGE_XSWD_LOGS)
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonLogs.Text = lang.Translate("Request Logs")
+			p.buttonLogs.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonLogs.Layout(gtx, th)
+		},
+	}
 
 	xswd := wallet_manager.OpenedWallet.ServerXSWD
-	if xswd != nil && xswd.IsRunning() {
-		if len(p.apps) > 0 {
-			for i := range p.apps {
-				idx := i
-				widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-					if idx < len(p.apps) {
-						return p.apps[idx].Layout(gtx, th)
-					}
-					return layout.Dimensions{}
-				})
-			}
-		} else {
+	if xswd == nil || !xswd.IsRunning() {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, 16, lang.Translate("XSWD is not running."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	if len(p.apps) > 0 {
+		for i := range p.apps {
+			idx := i
 			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-				lbl := material.Label(th, 16, lang.Translate("There are currently no dApp connections."))
-				lbl.Color = theme.Current.TextMuteColor
-				return lbl.Layout(gtx)
+				if idx < len(p.apps) {
+					return p.apps[idx].Layout(gtx, th)
+				}
+				return layout.Dimensions{}
 			})
 		}
 	} else {
 		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-			lbl := material.Label(th, 16, lang.Translate("XSWD is not running."))
+			lbl := material.Label(th, 16, lang.Translate("There are currently no dApp connections."))
 			lbl.Color = theme.Current.TextMuteColor
 			return lbl.Layout(gtx)
 		})
@@ -174,12 +227,14 @@ func (p *PageXSWDManage) Layout(gtx layout.Context, th *material.Theme) layout.D
 
 type DAppItem struct {
 	app       xswd.ApplicationData
+	connected bool
 	clickable *widget.Clickable
 }
 
-func NewDAppItem(app xswd.ApplicationData) *DAppItem {
+func NewDAppItem(app xswd.ApplicationData, connected bool) *DAppItem {
 	return &DAppItem{
 		app:       app,
+		connected: connected,
 		clickable: new(widget.Clickable),
 	}
 }
@@ -220,6 +275,15 @@ func (item *DAppItem) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 						}),
 					)
 				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !item.connected {
+						return layout.Dimensions{}
+					}
+
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Connected"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
 			)
 		})
 	})
@@ -0,0 +1,322 @@
+package page_wallet
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/containers/password_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageXSWDPolicyForm struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+	txtSCID             *prefabs.TextField
+	txtEntrypoint       *prefabs.TextField
+	txtDeroLimit        *prefabs.TextField
+	tokenLimits         []*XSWDPolicyTokenLimit
+	buttonAddTokenLimit *components.Button
+	buttonSave          *components.Button
+
+	list *widget.List
+}
+
+var _ router.Page = &PageXSWDPolicyForm{}
+
+func NewPageXSWDPolicyForm() *PageXSWDPolicyForm {
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	buttonSave := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      saveIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSave.Label.Alignment = text.Middle
+	buttonSave.Style.Font.Weight = font.Bold
+
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	buttonAddTokenLimit := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonAddTokenLimit.Label.Alignment = text.Middle
+	buttonAddTokenLimit.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_XSWD_POLICY_FORM)
+	return &PageXSWDPolicyForm{
+		headerPageAnimation: headerPageAnimation,
+		txtSCID:             prefabs.NewTextField(),
+		txtEntrypoint:       prefabs.NewTextField(),
+		txtDeroLimit:        prefabs.NewNumberTextField(),
+		buttonAddTokenLimit: buttonAddTokenLimit,
+		buttonSave:          buttonSave,
+		list:                list,
+	}
+}
+
+func (p *PageXSWDPolicyForm) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageXSWDPolicyForm) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("New Spending Policy")
+	}
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(14), page_instance.pageXSWDApp.app.Name)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+	page_instance.header.LeftLayout = nil
+	page_instance.header.RightLayout = nil
+
+	p.txtSCID.SetValue("")
+	p.txtEntrypoint.SetValue("")
+	p.txtDeroLimit.SetValue("")
+	p.tokenLimits = nil
+}
+
+func (p *PageXSWDPolicyForm) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageXSWDPolicyForm) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonSave.Clicked(gtx) {
+		password_modal.Instance.SetVisible(true)
+	}
+
+	if p.buttonAddTokenLimit.Clicked(gtx) {
+		p.tokenLimits = append(p.tokenLimits, NewXSWDPolicyTokenLimit())
+	}
+
+	for i := range p.tokenLimits {
+		if p.tokenLimits[i].buttonRemove.Clicked(gtx) {
+			p.tokenLimits = append(p.tokenLimits[:i], p.tokenLimits[i+1:]...)
+			break
+		}
+	}
+
+	submitted, password := password_modal.Instance.Submitted()
+	if submitted {
+		go func() {
+			wallet := wallet_manager.OpenedWallet
+			password_modal.Instance.SetLoading(true)
+			validPassword := wallet.Memory.Check_Password(password)
+			password_modal.Instance.SetLoading(false)
+			password_modal.Instance.Input.UnlockSubmit()
+
+			if !validPassword {
+				password_modal.Instance.StartWrongPassAnimation()
+			} else {
+				password_modal.Instance.Input.SetValue("")
+				password_modal.Instance.SetVisible(false)
+
+				err := p.submitForm()
+				if err != nil {
+					notification_modal.Open(notification_modal.Params{
+						Type:  notification_modal.ERROR,
+						Title: lang.Translate("Error"),
+						Text:  err.Error(),
+					})
+				} else {
+					notification_modal.Open(notification_modal.Params{
+						Type:       notification_modal.SUCCESS,
+						Title:      lang.Translate("Success"),
+						Text:       lang.Translate("Spending policy added."),
+						CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+					})
+					page_instance.header.GoBack()
+				}
+
+				app_instance.Window.Invalidate()
+			}
+		}()
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Transfers matching this policy are sent without confirmation until the daily limits are reached. Leave the smart contract or entrypoint empty to match any value."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSCID.Layout(gtx, th, lang.Translate("Smart Contract ID"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtEntrypoint.Layout(gtx, th, lang.Translate("Entrypoint"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtDeroLimit.Layout(gtx, th, lang.Translate("DERO Daily Limit"), "0.00000")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("Transfers of a token without a daily limit always ask for confirmation."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+	}
+
+	for i := range p.tokenLimits {
+		tokenLimit := p.tokenLimits[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return tokenLimit.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonAddTokenLimit.Text = lang.Translate("ADD TOKEN LIMIT")
+			p.buttonAddTokenLimit.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonAddTokenLimit.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSave.Text = lang.Translate("SAVE")
+			p.buttonSave.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSave.Layout(gtx, th)
+		},
+	)
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageXSWDPolicyForm) submitForm() error {
+	scId := p.txtSCID.Value()
+	if scId != "" && len(scId) != len(crypto.ZEROHASH.String()) {
+		return fmt.Errorf(lang.Translate("Invalid smart contract ID."))
+	}
+
+	deroLimit := &utils.ShiftNumber{Decimals: 5}
+	if p.txtDeroLimit.Value() != "" {
+		err := deroLimit.Parse(p.txtDeroLimit.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	tokenLimits := make(map[string]uint64)
+	for _, tokenLimit := range p.tokenLimits {
+		tokenScId, limit, err := tokenLimit.value()
+		if err != nil {
+			return err
+		}
+
+		if limit > 0 {
+			tokenLimits[tokenScId] = limit
+		}
+	}
+
+	if deroLimit.Number == 0 && len(tokenLimits) == 0 {
+		return fmt.Errorf(lang.Translate("Set at least one daily limit."))
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	err := wallet.InsertXSWDPolicy(wallet_manager.XSWDPolicy{
+		AppId:       page_instance.pageXSWDApp.app.Id,
+		SCID:        scId,
+		Entrypoint:  p.txtEntrypoint.Value(),
+		DeroLimit:   deroLimit.Number,
+		TokenLimits: tokenLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	return page_instance.pageXSWDApp.Load()
+}
+
+// XSWDPolicyTokenLimit is the daily limit of a single token, the amount is entered with the decimals of the token
+type XSWDPolicyTokenLimit struct {
+	txtSCID      *prefabs.TextField
+	txtLimit     *prefabs.TextField
+	buttonRemove *components.Button
+}
+
+func NewXSWDPolicyTokenLimit() *XSWDPolicyTokenLimit {
+	removeIcon, _ := widget.NewIcon(icons.NavigationCancel)
+	buttonRemove := components.NewButton(components.ButtonStyle{
+		Icon: removeIcon,
+	})
+
+	return &XSWDPolicyTokenLimit{
+		txtSCID:      prefabs.NewTextField(),
+		txtLimit:     prefabs.NewNumberTextField(),
+		buttonRemove: buttonRemove,
+	}
+}
+
+func (t *XSWDPolicyTokenLimit) value() (scId string, limit uint64, err error) {
+	scId = t.txtSCID.Value()
+	if len(scId) != len(crypto.ZEROHASH.String()) || scId == crypto.ZEROHASH.String() {
+		err = fmt.Errorf(lang.Translate("Invalid smart contract ID."))
+		return
+	}
+
+	token, err := wallet_manager.GetTokenBySCID(scId)
+	if err != nil {
+		return
+	}
+
+	amount := &utils.ShiftNumber{Decimals: int(token.Decimals)}
+	err = amount.Parse(t.txtLimit.Value())
+	if err != nil {
+		return
+	}
+
+	limit = amount.Number
+	return
+}
+
+func (t *XSWDPolicyTokenLimit) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(2, func(gtx layout.Context) layout.Dimensions {
+			return t.txtSCID.Layout(gtx, th, lang.Translate("Token SCID"), "")
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return t.txtLimit.Layout(gtx, th, lang.Translate("Token Daily Limit"), "0")
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			t.buttonRemove.Style.Colors = theme.Current.ButtonIconPrimaryColors
+			gtx.Constraints.Min.X = gtx.Dp(25)
+			gtx.Constraints.Min.Y = gtx.Dp(25)
+			return t.buttonRemove.Layout(gtx, th)
+		}),
+	)
+}
@@ -0,0 +1,272 @@
+package page_wallet_select
+
+import (
+	"fmt"
+	"image/color"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/components"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/prefabs"
+	"github.com/g45t345rt/g45w/router"
+	"github.com/g45t345rt/g45w/theme"
+	"github.com/g45t345rt/g45w/utils"
+	"github.com/g45t345rt/g45w/wallet_manager"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageCreateWalletAccountForm struct {
+	isActive bool
+
+	headerPageAnimation *prefabs.PageHeaderAnimation
+
+	list *widget.List
+
+	buttonMaster       *components.Button
+	txtMasterPassword  *prefabs.TextField
+	txtWalletName      *prefabs.TextField
+	txtPassword        *prefabs.TextField
+	txtConfirmPassword *prefabs.TextField
+	buttonCreate       *components.Button
+
+	master *app_db.WalletInfo
+}
+
+var _ router.Page = &PageCreateWalletAccountForm{}
+
+func NewPageCreateWalletAccountForm() *PageCreateWalletAccountForm {
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	walletIcon, _ := widget.NewIcon(icons.ActionAccountBalanceWallet)
+	buttonMaster := components.NewButton(components.ButtonStyle{
+		Icon:      walletIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonMaster.Label.Alignment = text.Middle
+	buttonMaster.Style.Font.Weight = font.Bold
+
+	iconCreate, _ := widget.NewIcon(icons.ContentAddBox)
+	buttonCreate := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      iconCreate,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonCreate.Style.Font.Weight = font.Bold
+	headerPageAnimation := prefabs.NewPageHeaderAnimation(PAGE_CREATE_WALLET_ACCOUNT_FORM)
+
+	return &PageCreateWalletAccountForm{
+		list:                list,
+		headerPageAnimation: headerPageAnimation,
+
+		buttonMaster:       buttonMaster,
+		txtMasterPassword:  prefabs.NewPasswordTextField(),
+		txtWalletName:      prefabs.NewTextField(),
+		txtPassword:        prefabs.NewPasswordTextField(),
+		txtConfirmPassword: prefabs.NewPasswordTextField(),
+		buttonCreate:       buttonCreate,
+	}
+}
+
+func (p *PageCreateWalletAccountForm) Enter() {
+	p.isActive = p.headerPageAnimation.Enter(page_instance.header)
+
+	page_instance.header.Title = func() string { return lang.Translate("Add Account from Master") }
+}
+
+func (p *PageCreateWalletAccountForm) Leave() {
+	p.isActive = p.headerPageAnimation.Leave(page_instance.header)
+}
+
+func (p *PageCreateWalletAccountForm) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageCreateWalletAccountForm) selectMaster() {
+	wallets, err := app_db.GetWallets()
+	if err != nil {
+		notification_modal.Open(notification_modal.Params{
+			Type:  notification_modal.ERROR,
+			Title: lang.Translate("Error"),
+			Text:  err.Error(),
+		})
+		return
+	}
+
+	walletIcon, _ := widget.NewIcon(icons.ActionAccountBalanceWallet)
+	var items []*listselect_modal.SelectListItem
+	for _, walletInfo := range wallets {
+		// accounts are only derived from a master wallet
+		if walletInfo.ParentAddr != "" {
+			continue
+		}
+
+		items = append(items, listselect_modal.NewSelectListItem(walletInfo.Addr,
+			listselect_modal.NewItemText(walletIcon, walletInfo.Name).Layout,
+		))
+	}
+
+	activeKey := ""
+	if p.master != nil {
+		activeKey = p.master.Addr
+	}
+
+	for addr := range listselect_modal.Instance.Open(items, activeKey) {
+		for _, walletInfo := range wallets {
+			if walletInfo.Addr == addr {
+				master := walletInfo
+				p.master = &master
+			}
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageCreateWalletAccountForm) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
+
+	if p.buttonMaster.Clicked(gtx) {
+		go p.selectMaster()
+	}
+
+	if p.buttonCreate.Clicked(gtx) {
+		err := p.submitForm()
+		if err != nil {
+			notification_modal.Open(notification_modal.Params{
+				Type:  notification_modal.ERROR,
+				Title: lang.Translate("Error"),
+				Text:  err.Error(),
+			})
+		} else {
+			notification_modal.Open(notification_modal.Params{
+				Type:       notification_modal.SUCCESS,
+				Title:      lang.Translate("Success"),
+				Text:       lang.Translate("New wallet created."),
+				CloseAfter: notification_modal.CLOSE_AFTER_DEFAULT,
+			})
+		}
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("The account is derived from the master wallet. Its seed is enough to restore all the accounts."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			if p.master != nil {
+				p.buttonMaster.Text = fmt.Sprintf("%s (%s)", p.master.Name, utils.ReduceAddr(p.master.Addr))
+			} else {
+				p.buttonMaster.Text = lang.Translate("Select master wallet")
+			}
+
+			p.buttonMaster.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonMaster.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtMasterPassword.Layout(gtx, th, lang.Translate("Master Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtWalletName.Layout(gtx, th, lang.Translate("Wallet Name"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtPassword.Layout(gtx, th, lang.Translate("Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtConfirmPassword.Layout(gtx, th, lang.Translate("Confirm Password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonCreate.Text = lang.Translate("ADD ACCOUNT")
+			p.buttonCreate.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonCreate.Layout(gtx, th)
+		},
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	if p.txtMasterPassword.Input.Clickable.Clicked(gtx) {
+		p.list.ScrollTo(2)
+	}
+
+	if p.txtWalletName.Input.Clickable.Clicked(gtx) {
+		p.list.ScrollTo(3)
+	}
+
+	if p.txtPassword.Input.Clickable.Clicked(gtx) {
+		p.list.ScrollTo(4)
+	}
+
+	if p.txtConfirmPassword.Input.Clickable.Clicked(gtx) {
+		p.list.ScrollTo(5)
+	}
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: theme.PagePadding, Right: theme.PagePadding,
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageCreateWalletAccountForm) submitForm() error {
+	txtMasterPassword := p.txtMasterPassword.Editor()
+	txtName := p.txtWalletName.Editor()
+	txtPassword := p.txtPassword.Editor()
+	txtConfirmPassword := p.txtConfirmPassword.Editor()
+
+	if p.master == nil {
+		return fmt.Errorf("select master wallet")
+	}
+
+	if txtMasterPassword.Text() == "" {
+		return fmt.Errorf("enter master password")
+	}
+
+	if txtName.Text() == "" {
+		return fmt.Errorf("enter wallet name")
+	}
+
+	if txtPassword.Text() == "" {
+		return fmt.Errorf("enter password")
+	}
+
+	if txtPassword.Text() != txtConfirmPassword.Text() {
+		return fmt.Errorf("the confirm password does not match")
+	}
+
+	err := wallet_manager.CreateAccountFromMaster(txtName.Text(), txtPassword.Text(), p.master.Addr, txtMasterPassword.Text())
+	if err != nil {
+		return err
+	}
+
+	txtMasterPassword.SetText("")
+	txtName.SetText("")
+	txtPassword.SetText("")
+	txtConfirmPassword.SetText("")
+	p.master = nil
+
+	page_instance.header.GoBack()
+	return nil
+}
@@ -173,7 +173,7 @@ func (p *PageCreateWalletForm) submitForm() error {
 
 	if p.regResultContainer != nil {
 		hexSeed := p.regResultContainer.result.HexSeed
-		err := wallet_manager.CreateWalletFromHexSeed(txtName.Text(), txtPassword.Text(), hexSeed)
+		err := wallet_manager.CreateWalletFromHexSeed(txtName.Text(), txtPassword.Text(), hexSeed, 0)
 		if err != nil {
 			return err
 		}
@@ -2,6 +2,7 @@ package page_wallet_select
 
 import (
 	"fmt"
+	"strconv"
 
 	"gioui.org/font"
 	"gioui.org/layout"
@@ -29,6 +30,7 @@ type PageCreateWalletSeedForm struct {
 	txtWalletName      *prefabs.TextField
 	txtPassword        *prefabs.TextField
 	txtConfirmPassword *prefabs.TextField
+	txtAccounts        *prefabs.TextField
 	buttonCreate       *components.Button
 }
 
@@ -117,6 +119,19 @@ func (p *PageCreateWalletSeedForm) Layout(gtx layout.Context, th *material.Theme
 		func(gtx layout.Context) layout.Dimensions {
 			return p.txtConfirmPassword.Layout(gtx, th, lang.Translate("Confirm Password"), "")
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.txtAccounts.Layout(gtx, th, lang.Translate("Derived Accounts"), "0")
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Number of accounts derived from this seed to restore. They use the same password."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonCreate.Text = lang.Translate("RECOVER WALLET")
 			p.buttonCreate.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -173,7 +188,16 @@ func (p *PageCreateWalletSeedForm) submitForm() error {
 		return fmt.Errorf("the confirm password does not match")
 	}
 
-	err := wallet_manager.CreateWalletFromSeed(txtName.Text(), txtPassword.Text(), txtSeed.Text())
+	accounts := 0
+	if p.txtAccounts.Value() != "" {
+		var err error
+		accounts, err = strconv.Atoi(p.txtAccounts.Value())
+		if err != nil || accounts < 0 {
+			return fmt.Errorf("invalid number of accounts")
+		}
+	}
+
+	err := wallet_manager.CreateWalletFromSeed(txtName.Text(), txtPassword.Text(), txtSeed.Text(), accounts)
 	if err != nil {
 		return err
 	}
@@ -181,6 +205,7 @@ func (p *PageCreateWalletSeedForm) submitForm() error {
 	txtName.SetText("")
 	txtPassword.SetText("")
 	txtConfirmPassword.SetText("")
+	p.txtAccounts.SetValue("")
 	txtSeed.SetText("")
 
 	page_instance.header.GoBack()
@@ -40,6 +40,7 @@ const (
 	PAGE_CREATE_WALLET_FORM         = "page_create_wallet_form"
 	PAGE_CREATE_WALLET_FASTREG_FORM = "page_create_Wallet_fastreg_form"
 	PAGE_CREATE_WALLET_DISK_FORM    = "page_create_wallet_disk_form"
+	PAGE_CREATE_WALLET_ACCOUNT_FORM = "page_create_wallet_account_form"
 	PAGE_SELECT_WALLET              = "page_select_wallet"
 )
 
@@ -64,6 +65,9 @@ func New() *Page {
 	pageCreateWalletDiskForm := NewPageCreateWalletDiskForm()
 	pageRouter.Add(PAGE_CREATE_WALLET_DISK_FORM, pageCreateWalletDiskForm)
 
+	pageCreateWalletAccountForm := NewPageCreateWalletAccountForm()
+	pageRouter.Add(PAGE_CREATE_WALLET_ACCOUNT_FORM, pageCreateWalletAccountForm)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
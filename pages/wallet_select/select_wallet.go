@@ -1,6 +1,7 @@
 package page_wallet_select
 
 import (
+	"database/sql"
 	"fmt"
 	"image"
 	"strings"
@@ -40,9 +41,12 @@ type PageSelectWallet struct {
 	headerPageAnimation *prefabs.PageHeaderAnimation
 
 	buttonWalletCreate *components.Button
+	txtFilter          *prefabs.Input
 	walletList         *widget.List
 	dragItems          *components.DragItems
 	items              []walletItem
+	groups             []*groupHeader
+	rows               []walletRow
 
 	currentWallet app_db.WalletInfo
 }
@@ -75,6 +79,7 @@ func NewPageSelectWallet() *PageSelectWallet {
 		headerPageAnimation: headerPageAnimation,
 
 		buttonWalletCreate: buttonWalletCreate,
+		txtFilter:          prefabs.NewInput(),
 		walletList:         walletList,
 		dragItems:          dragItems,
 	}
@@ -101,22 +106,124 @@ func (p *PageSelectWallet) Leave() {
 }
 
 func (p *PageSelectWallet) Load() error {
-	p.items = make([]walletItem, 0)
 	wallets, err := app_db.GetWallets()
 	if err != nil {
 		return err
 	}
 
+	walletGroups, err := app_db.GetWalletGroups()
+	if err != nil {
+		return err
+	}
+
+	items := make([]walletItem, 0)
 	for _, walletInfo := range wallets {
-		p.items = append(p.items, walletItem{
+		items = append(items, walletItem{
 			clickable:  new(widget.Clickable),
 			walletInfo: walletInfo,
 		})
 	}
 
+	groups := make([]*groupHeader, 0)
+	for _, group := range walletGroups {
+		groups = append(groups, newGroupHeader(group))
+	}
+
+	p.items = items
+	p.groups = groups
 	return nil
 }
 
+// walletRow is a group header or a wallet of the list
+type walletRow struct {
+	header *groupHeader
+	item   *walletItem
+}
+
+func (p *PageSelectWallet) filterItem(item *walletItem, groupName string, filter string) bool {
+	if filter == "" {
+		return true
+	}
+
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(item.walletInfo.Name), filter) ||
+		strings.Contains(strings.ToLower(item.walletInfo.Addr), filter) ||
+		strings.Contains(strings.ToLower(groupName), filter)
+}
+
+// buildRows lists the ungrouped wallets first and then every group with its wallets
+func (p *PageSelectWallet) buildRows() {
+	filter := strings.TrimSpace(p.txtFilter.Editor.Text())
+	rows := make([]walletRow, 0)
+
+	for i := range p.items {
+		item := &p.items[i]
+		if !item.walletInfo.GroupId.Valid && p.filterItem(item, "", filter) {
+			rows = append(rows, walletRow{item: item})
+		}
+	}
+
+	for _, header := range p.groups {
+		var groupItems []walletRow
+		header.count = 0
+		for i := range p.items {
+			item := &p.items[i]
+			groupId := item.walletInfo.GroupId
+			if groupId.Valid && groupId.Int64 == header.group.ID {
+				header.count++
+				if p.filterItem(item, header.group.Name, filter) {
+					groupItems = append(groupItems, walletRow{item: item})
+				}
+			}
+		}
+
+		if filter != "" && len(groupItems) == 0 {
+			continue
+		}
+
+		rows = append(rows, walletRow{header: header})
+
+		// the filter shows the matching wallets of collapsed groups
+		if !header.group.Collapsed || filter != "" {
+			rows = append(rows, groupItems...)
+		}
+	}
+
+	p.rows = rows
+}
+
+// moveRow moves a wallet inside or to another group (the group of the target row) or reorders the groups
+func (p *PageSelectWallet) moveRow(fromIndex int, toIndex int) error {
+	if fromIndex < 0 || fromIndex >= len(p.rows) || toIndex < 0 || toIndex >= len(p.rows) {
+		return nil
+	}
+
+	from := p.rows[fromIndex]
+	to := p.rows[toIndex]
+
+	if from.header != nil {
+		if to.header == nil {
+			return nil
+		}
+
+		group := from.header.group
+		group.OrderNumber = to.header.group.OrderNumber
+		return app_db.UpdateWalletGroup(group)
+	}
+
+	walletInfo := from.item.walletInfo
+	if to.header != nil {
+		// dropped on a group header, place at the top of the group
+		walletInfo.GroupId = sql.NullInt64{Int64: to.header.group.ID, Valid: true}
+		walletInfo.OrderNumber = 0
+	} else {
+		walletInfo.GroupId = to.item.walletInfo.GroupId
+		walletInfo.OrderNumber = to.item.walletInfo.OrderNumber
+	}
+
+	return app_db.UpdateWalletInfo(walletInfo)
+}
+
 func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	defer p.headerPageAnimation.Update(gtx, func() { p.isActive = false }).Push(gtx.Ops).Pop()
 
@@ -125,9 +232,7 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 		if moved {
 			go func() {
 				updateIndex := func() error {
-					walletInfo := p.items[cIndex].walletInfo
-					walletInfo.OrderNumber = nIndex
-					err := app_db.UpdateWalletInfo(walletInfo)
+					err := p.moveRow(cIndex, nIndex)
 					if err != nil {
 						return err
 					}
@@ -148,6 +253,23 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 		}
 	}
 
+	for _, header := range p.groups {
+		if header.clickable.Clicked(gtx) {
+			group := header.group
+			group.Collapsed = !group.Collapsed
+			err := app_db.UpdateWalletGroup(group)
+			if err == nil {
+				header.group = group
+			}
+		}
+
+		if header.buttonEdit.Clicked(gtx) {
+			go p.editGroup(header.group)
+		}
+	}
+
+	p.buildRows()
+
 	layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			return layout.Inset{
@@ -155,6 +277,17 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 				Left: theme.PagePadding, Right: theme.PagePadding,
 			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if len(p.items) == 0 {
+							return layout.Dimensions{}
+						}
+
+						return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							p.txtFilter.TextSize = unit.Sp(16)
+							p.txtFilter.Colors = theme.Current.InputColors
+							return p.txtFilter.Layout(gtx, th, lang.Translate("Search wallet or group..."))
+						})
+					}),
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						if len(p.items) == 0 {
 							labelNoWallet := material.Label(th, unit.Sp(16), lang.Translate("You didn't add a wallet yet.\nClick 'New Wallet' button to continue."))
@@ -172,8 +305,28 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 
 							return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 								return p.dragItems.Layout(gtx, &p.walletList.Position, func(gtx layout.Context) layout.Dimensions {
-									return listStyle.Layout(gtx, len(p.items), func(gtx layout.Context, index int) layout.Dimensions {
-										item := p.items[index]
+									return listStyle.Layout(gtx, len(p.rows), func(gtx layout.Context, index int) layout.Dimensions {
+										row := p.rows[index]
+										if row.header != nil {
+											header := row.header
+											r := op.Record(gtx.Ops)
+											dims := header.Layout(gtx, th, false)
+											c := r.Stop()
+
+											p.dragItems.LayoutItem(gtx, index, func(gtx layout.Context) layout.Dimensions {
+												defer clip.UniformRRect(image.Rectangle{Max: dims.Size}, 12).Push(gtx.Ops).Pop()
+												return header.Layout(gtx, th, true)
+											})
+
+											return header.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+												return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+													c.Add(gtx.Ops)
+													return dims
+												})
+											})
+										}
+
+										item := row.item
 
 										if item.clickable.Clicked(gtx) {
 											p.currentWallet = item.walletInfo
@@ -208,6 +361,7 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 								newIcon, _ := widget.NewIcon(icons.ContentAddCircle)
 								diskIcon, _ := widget.NewIcon(icons.FileFolder)
 								seedIcon, _ := widget.NewIcon(icons.EditorShortText)
+								accountIcon, _ := widget.NewIcon(icons.SocialGroupAdd)
 
 								keyChan := listselect_modal.Instance.Open([]*listselect_modal.SelectListItem{
 									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_FASTREG_FORM,
@@ -216,6 +370,9 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_FORM,
 										listselect_modal.NewItemText(newIcon, lang.Translate("Create new wallet")).Layout,
 									),
+									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_ACCOUNT_FORM,
+										listselect_modal.NewItemText(accountIcon, lang.Translate("Add account from existing master")).Layout,
+									),
 									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_DISK_FORM,
 										listselect_modal.NewItemText(diskIcon, lang.Translate("Recover from disk")).Layout,
 									),
@@ -248,7 +405,15 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 		if submitted {
 			go func() {
 				password_modal.Instance.SetLoading(true)
-				err := wallet_manager.OpenWallet(p.currentWallet.Addr, text)
+				var err error
+				// a read-only wallet accepts the viewing PIN or the full password
+				if p.currentWallet.ReadOnly {
+					err = wallet_manager.OpenWalletWithPIN(p.currentWallet.Addr, text)
+				}
+
+				if !p.currentWallet.ReadOnly || err == wallet_manager.ErrInvalidPIN {
+					err = wallet_manager.OpenWallet(p.currentWallet.Addr, text)
+				}
 				password_modal.Instance.SetLoading(false)
 				password_modal.Instance.Input.UnlockSubmit()
 
@@ -301,6 +466,14 @@ func (item *walletItem) Layout(gtx layout.Context, th *material.Theme, fill bool
 					}),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						addr := utils.ReduceAddr(item.walletInfo.Addr)
+						if item.walletInfo.ParentAddr != "" {
+							addr = fmt.Sprintf("%s - %s #%d", addr, lang.Translate("Account"), item.walletInfo.DerivationIndex)
+						}
+
+						if item.walletInfo.ReadOnly {
+							addr = fmt.Sprintf("%s - %s", addr, lang.Translate("Read-only"))
+						}
+
 						lbl := material.Label(th, unit.Sp(15), addr)
 						lbl.Color = theme.Current.TextMuteColor
 						return lbl.Layout(gtx)
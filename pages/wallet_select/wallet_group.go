@@ -0,0 +1,164 @@
+package page_wallet_select
+
+import (
+	"fmt"
+	"image"
+
+	"gioui.org/font"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/app_instance"
+	"github.com/g45t345rt/g45w/containers/confirm_modal"
+	"github.com/g45t345rt/g45w/containers/listselect_modal"
+	"github.com/g45t345rt/g45w/containers/notification_modal"
+	"github.com/g45t345rt/g45w/containers/prompt_modal"
+	"github.com/g45t345rt/g45w/lang"
+	"github.com/g45t345rt/g45w/theme"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type groupHeader struct {
+	group      app_db.WalletGroup
+	count      int
+	clickable  *widget.Clickable
+	buttonEdit *widget.Clickable
+
+	expandIcon   *widget.Icon
+	collapseIcon *widget.Icon
+	editIcon     *widget.Icon
+}
+
+func newGroupHeader(group app_db.WalletGroup) *groupHeader {
+	expandIcon, _ := widget.NewIcon(icons.NavigationChevronRight)
+	collapseIcon, _ := widget.NewIcon(icons.NavigationExpandMore)
+	editIcon, _ := widget.NewIcon(icons.NavigationMoreVert)
+
+	return &groupHeader{
+		group:        group,
+		clickable:    new(widget.Clickable),
+		buttonEdit:   new(widget.Clickable),
+		expandIcon:   expandIcon,
+		collapseIcon: collapseIcon,
+		editIcon:     editIcon,
+	}
+}
+
+func (h *groupHeader) Layout(gtx layout.Context, th *material.Theme, fill bool) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				icon := h.collapseIcon
+				if h.group.Collapsed {
+					icon = h.expandIcon
+				}
+
+				gtx.Constraints.Min.X = gtx.Dp(24)
+				return icon.Layout(gtx, theme.Current.TextColor)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(18), h.group.Name)
+						lbl.Font.Weight = font.Bold
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("(%d)", h.count))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				layoutIcon := func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Dp(24)
+					return h.editIcon.Layout(gtx, theme.Current.TextMuteColor)
+				}
+
+				if fill {
+					return layoutIcon(gtx)
+				}
+
+				if h.buttonEdit.Hovered() {
+					pointer.CursorPointer.Add(gtx.Ops)
+				}
+
+				return h.buttonEdit.Layout(gtx, layoutIcon)
+			}),
+		)
+	})
+	c := r.Stop()
+
+	if h.clickable.Hovered() || fill {
+		pointer.CursorPointer.Add(gtx.Ops)
+		paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+			clip.UniformRRect(image.Rectangle{Max: dims.Size}, gtx.Dp(12)).Op(gtx.Ops),
+		)
+	}
+
+	c.Add(gtx.Ops)
+	return dims
+}
+
+func (p *PageSelectWallet) editGroup(group app_db.WalletGroup) {
+	renameIcon, _ := widget.NewIcon(icons.EditorModeEdit)
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	keyChan := listselect_modal.Instance.Open([]*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("rename",
+			listselect_modal.NewItemText(renameIcon, lang.Translate("Rename group")).Layout,
+		),
+		listselect_modal.NewSelectListItem("delete",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Delete group")).Layout,
+		),
+	}, "")
+
+	for selectedKey := range keyChan {
+		var err error
+
+		switch selectedKey {
+		case "rename":
+			name := <-prompt_modal.Instance.Open(group.Name, lang.Translate("Enter group name"), key.HintAny)
+			if name == "" || name == group.Name {
+				return
+			}
+
+			group.Name = name
+			err = app_db.UpdateWalletGroup(group)
+		case "delete":
+			yes := <-confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+				Prompt: lang.Translate("The wallets of the group will be moved out of the group."),
+			})
+			if !yes {
+				return
+			}
+
+			err = app_db.DelWalletGroup(group.ID)
+		}
+
+		if err == nil {
+			err = p.Load()
+		}
+
+		if err != nil {
+			notification_modal.Open(notification_modal.Params{
+				Type:  notification_modal.ERROR,
+				Title: lang.Translate("Error"),
+				Text:  err.Error(),
+			})
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
@@ -0,0 +1,139 @@
+package proxy_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/g45t345rt/g45w/settings"
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/proxy"
+)
+
+var ErrPrivacyModeNoProxy = errors.New("privacy mode is enabled but the proxy is not set")
+
+var directDialer = &net.Dialer{Timeout: 30 * time.Second}
+
+// Load routes the default websocket dialer (used by walletapi.Connect) through DialContext
+func Load() {
+	websocket.DefaultDialer.Proxy = nil
+	websocket.DefaultDialer.NetDialContext = DialContext
+}
+
+func ValidateSettings(config settings.ProxySettings) error {
+	if config.Enabled {
+		if config.Host == "" {
+			return fmt.Errorf("enter proxy host")
+		}
+
+		if config.Port <= 0 || config.Port > 65535 {
+			return fmt.Errorf("invalid proxy port")
+		}
+	}
+
+	if config.PrivacyMode && !config.Enabled {
+		return ErrPrivacyModeNoProxy
+	}
+
+	return nil
+}
+
+// isLocalAddr returns true for loopback and private network addresses.
+// They are not routed through the proxy since it can't reach them (local node, integrated node).
+func isLocalAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsLoopback() || ip.IsPrivate()
+}
+
+func socksDialer(config settings.ProxySettings) (proxy.ContextDialer, error) {
+	var auth *proxy.Auth
+	if config.Username != "" {
+		auth = &proxy.Auth{
+			User:     config.Username,
+			Password: config.Password,
+		}
+	}
+
+	// the hostname is resolved by the proxy so dns requests don't leak
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	dialer, err := proxy.SOCKS5("tcp", addr, auth, directDialer)
+	if err != nil {
+		return nil, err
+	}
+
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("proxy dialer does not support context")
+	}
+
+	return contextDialer, nil
+}
+
+// DialContext opens every outbound connection of the app.
+// Without privacy mode, a direct connection is used if the proxy can't be reached.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	config := settings.App.Proxy
+	if isLocalAddr(addr) {
+		return directDialer.DialContext(ctx, network, addr)
+	}
+
+	if !config.Enabled {
+		if config.PrivacyMode {
+			return nil, ErrPrivacyModeNoProxy
+		}
+
+		return directDialer.DialContext(ctx, network, addr)
+	}
+
+	dialer, err := socksDialer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		if config.PrivacyMode {
+			return nil, fmt.Errorf("proxy connection failed: %w", err)
+		}
+
+		return directDialer.DialContext(ctx, network, addr)
+	}
+
+	return conn, nil
+}
+
+// NewHttpClient returns an http client that dials with DialContext and ignores the environment proxy
+func NewHttpClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// NewWebsocketDialer returns a websocket dialer that dials with DialContext
+func NewWebsocketDialer(handshakeTimeout time.Duration) *websocket.Dialer {
+	return &websocket.Dialer{
+		NetDialContext:   DialContext,
+		HandshakeTimeout: handshakeTimeout,
+	}
+}
@@ -13,10 +13,13 @@ import (
 )
 
 var (
-	MainTabBarsToken = "tokens"
-	MainTabBarsTxs   = "txs"
-	FolderLayoutGrid = "grid"
-	FolderLayoutList = "list"
+	MainTabBarsToken  = "tokens"
+	MainTabBarsTxs    = "txs"
+	FolderLayoutGrid  = "grid"
+	FolderLayoutList  = "list"
+	ParanoidModeOff   = ""
+	ParanoidModeWarn  = "warn"
+	ParanoidModeBlock = "block"
 )
 
 type AppSettings struct {
@@ -29,6 +32,36 @@ type AppSettings struct {
 	NodeSelect              string `json:"node_select"`
 	Testnet                 bool   `json:"testnet"`
 	MobileBackgroundService bool   `json:"mobile_background_service"`
+	NodeFailover            bool   `json:"node_failover"`
+	NodeFailoverTrusted     bool   `json:"node_failover_trusted"`
+	ParanoidMode            string `json:"paranoid_mode"`
+	ParanoidNodes           int    `json:"paranoid_nodes"`
+
+	IntegratedNode IntegratedNodeSettings `json:"integrated_node"`
+	Proxy          ProxySettings          `json:"proxy"`
+}
+
+// IntegratedNodeSettings are applied the next time the integrated node starts
+type IntegratedNodeSettings struct {
+	PriorityNodes    []string `json:"priority_nodes"`
+	ExclusiveNodes   []string `json:"exclusive_nodes"`
+	MinPeers         int      `json:"min_peers"`     // 0 is the node default
+	MaxPeers         int      `json:"max_peers"`     // 0 is the node default
+	PruneHistory     int64    `json:"prune_history"` // number of blocks to keep, 0 disables pruning
+	PrunedTopoHeight int64    `json:"pruned_topo_height"`
+	SocksProxy       string   `json:"socks_proxy"`
+	P2PBind          string   `json:"p2p_bind"`
+	RPCLan           bool     `json:"rpc_lan"`
+}
+
+// ProxySettings is the SOCKS5 proxy used by every outbound connection of the app
+type ProxySettings struct {
+	Enabled     bool   `json:"enabled"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	PrivacyMode bool   `json:"privacy_mode"` // never fallback to a direct connection
 }
 
 var (
@@ -74,6 +107,10 @@ func Load() error {
 		FolderLayout:            FolderLayoutGrid,
 		Testnet:                 false,
 		MobileBackgroundService: false,
+		NodeFailover:            false,
+		NodeFailoverTrusted:     true,
+		ParanoidMode:            ParanoidModeOff,
+		ParanoidNodes:           3,
 	}
 
 	_, err = os.Stat(settingsPath)
@@ -0,0 +1,109 @@
+package wallet_manager
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/g45t345rt/g45w/app_db"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+// Sub-accounts are derived from the secret key of a master wallet with
+// keccak256(domain | master secret | index) reduced by the curve order.
+// The master seed (recovery words or hex seed) is enough to restore all the accounts.
+
+const ACCOUNT_DERIVATION_DOMAIN = "g45w_account"
+
+var ErrAccountExists = fmt.Errorf("the account already exists")
+
+func DeriveAccountSeed(masterSecret *crypto.BNRed, index int) (*crypto.BNRed, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("the account index must be greater than 0")
+	}
+
+	data := []byte(ACCOUNT_DERIVATION_DOMAIN)
+	data = append(data, crypto.ConvertBigIntToByte(masterSecret.BigInt())...)
+	data = binary.BigEndian.AppendUint32(data, uint32(index))
+
+	return crypto.GetBNRed(crypto.ReducedHash(data)), nil
+}
+
+func openMasterWallet(masterAddr string, masterPassword string) (*walletapi.Wallet_Memory, error) {
+	walletInfo, err := app_db.GetWalletInfo(masterAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if walletInfo.ParentAddr != "" {
+		return nil, fmt.Errorf("the wallet is an account of another master wallet")
+	}
+
+	walletPath := filepath.Join(settings.WalletsDir, masterAddr, "wallet.db")
+	data, err := os.ReadFile(walletPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return walletapi.Open_Encrypted_Wallet_Memory(masterPassword, data)
+}
+
+func createAccountWallet(master *walletapi.Wallet_Memory, index int, name string, password string) error {
+	seed, err := DeriveAccountSeed(master.Get_Keys().Secret, index)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := walletapi.Create_Encrypted_Wallet_Memory(password, seed)
+	if err != nil {
+		return err
+	}
+
+	wallet.SetSeedLanguage(master.GetSeedLanguage())
+	wallet.SetNetwork(globals.IsMainnet())
+
+	_, err = app_db.GetWalletInfo(wallet.GetAddress().String())
+	if err == nil {
+		return ErrAccountExists
+	}
+
+	masterAddr := master.GetAddress().String()
+	return insertWallet(wallet, app_db.WalletInfo{
+		Name:            name,
+		ParentAddr:      masterAddr,
+		DerivationIndex: index,
+	})
+}
+
+// CreateAccountFromMaster adds the next account of a master wallet
+func CreateAccountFromMaster(name string, password string, masterAddr string, masterPassword string) error {
+	master, err := openMasterWallet(masterAddr, masterPassword)
+	if err != nil {
+		return err
+	}
+
+	index, err := app_db.GetNextDerivationIndex(masterAddr)
+	if err != nil {
+		return err
+	}
+
+	return createAccountWallet(master, index, name, password)
+}
+
+// restoreAccounts creates the accounts 1 to count of a master wallet with the master password.
+// Accounts that were already added are skipped.
+func restoreAccounts(master *walletapi.Wallet_Memory, masterName string, masterPassword string, count int) error {
+	for index := 1; index <= count; index++ {
+		name := fmt.Sprintf("%s #%d", masterName, index)
+		err := createAccountWallet(master, index, name, masterPassword)
+		if err != nil && err != ErrAccountExists {
+			return err
+		}
+	}
+
+	return nil
+}
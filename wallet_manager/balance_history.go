@@ -0,0 +1,199 @@
+package wallet_manager
+
+import (
+	"sort"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+)
+
+type BalancePeriod string
+
+const (
+	BALANCE_PERIOD_DAY   BalancePeriod = "day"
+	BALANCE_PERIOD_WEEK  BalancePeriod = "week"
+	BALANCE_PERIOD_MONTH BalancePeriod = "month"
+	BALANCE_PERIOD_ALL   BalancePeriod = "all"
+)
+
+var BalancePeriods = []BalancePeriod{BALANCE_PERIOD_DAY, BALANCE_PERIOD_WEEK, BALANCE_PERIOD_MONTH, BALANCE_PERIOD_ALL}
+
+// number of points returned in the history whatever the period
+const BALANCE_HISTORY_POINTS = 100
+
+func (p BalancePeriod) from(to time.Time) time.Time {
+	switch p {
+	case BALANCE_PERIOD_DAY:
+		return to.AddDate(0, 0, -1)
+	case BALANCE_PERIOD_WEEK:
+		return to.AddDate(0, 0, -7)
+	case BALANCE_PERIOD_MONTH:
+		return to.AddDate(0, -1, 0)
+	}
+
+	return time.Time{}
+}
+
+type BalancePoint struct {
+	Time    time.Time
+	Balance uint64
+}
+
+type BalanceHistory struct {
+	From    time.Time
+	To      time.Time
+	Points  []BalancePoint // evenly spaced from From to To
+	Inflow  uint64         // received during the period
+	Outflow uint64         // sent, burned and fees (DERO only) during the period
+	TxCount int
+}
+
+type balanceChange struct {
+	timestamp int64
+	inflow    uint64
+	outflow   uint64
+}
+
+func (w *Wallet) getBalanceChanges(scId crypto.Hash) ([]balanceChange, error) {
+	rows, err := w.DB.Query(`
+		SELECT txid, timestamp, incoming, coinbase, amount, burn FROM entries
+		WHERE scid = ?
+		ORDER BY timestamp ASC, topo_height ASC, tx_pos ASC, pos ASC;
+	`, scId.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []balanceChange
+	// index of the first outgoing change of each tx
+	outgoingChanges := make(map[string]int)
+	for rows.Next() {
+		var change balanceChange
+		var txId string
+		var incoming, coinbase bool
+		var amount, burn int64
+		err = rows.Scan(&txId, &change.timestamp, &incoming, &coinbase, &amount, &burn)
+		if err != nil {
+			return nil, err
+		}
+
+		if incoming || coinbase {
+			change.inflow = uint64(amount)
+		} else {
+			change.outflow = uint64(amount) + uint64(burn)
+			if _, ok := outgoingChanges[txId]; !ok {
+				outgoingChanges[txId] = len(changes)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if scId != crypto.ZEROHASH {
+		return changes, nil
+	}
+
+	// the fees are paid in DERO once per tx, even for a tx that only transfers tokens
+	// they are added to the DERO change of the tx so it's not counted twice
+	feeRows, err := w.DB.Query(`
+		SELECT txid, MIN(timestamp), MAX(fees) FROM entries
+		WHERE incoming = false AND coinbase = false AND fees > 0
+		GROUP BY txid;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer feeRows.Close()
+
+	for feeRows.Next() {
+		var change balanceChange
+		var txId string
+		var fees int64
+		err = feeRows.Scan(&txId, &change.timestamp, &fees)
+		if err != nil {
+			return nil, err
+		}
+
+		if index, ok := outgoingChanges[txId]; ok {
+			changes[index].outflow += uint64(fees)
+			continue
+		}
+
+		change.outflow = uint64(fees)
+		changes = append(changes, change)
+	}
+
+	err = feeRows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].timestamp < changes[j].timestamp
+	})
+
+	return changes, nil
+}
+
+// GetBalanceHistory rebuilds the balance of the token over the period from the synced entries.
+// The history is anchored on the current balance so it stays correct if the wallet didn't sync from the start.
+func (w *Wallet) GetBalanceHistory(scId crypto.Hash, period BalancePeriod) (BalanceHistory, error) {
+	changes, err := w.getBalanceChanges(scId)
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+
+	balance, _ := w.Memory.Get_Balance_scid(scId)
+
+	// walk back to the balance before the first entry
+	startBalance := int64(balance)
+	for _, change := range changes {
+		startBalance -= int64(change.inflow) - int64(change.outflow)
+	}
+
+	history := BalanceHistory{To: time.Now()}
+	history.From = period.from(history.To)
+	if period == BALANCE_PERIOD_ALL {
+		history.From = history.To.AddDate(0, 0, -1)
+		if len(changes) > 0 && changes[0].timestamp < history.From.Unix() {
+			history.From = time.Unix(changes[0].timestamp, 0)
+		}
+	}
+
+	from := history.From.Unix()
+	to := history.To.Unix()
+	for _, change := range changes {
+		if change.timestamp >= from && change.timestamp <= to {
+			history.Inflow += change.inflow
+			history.Outflow += change.outflow
+			history.TxCount++
+		}
+	}
+
+	current := startBalance
+	index := 0
+	for i := 0; i <= BALANCE_HISTORY_POINTS; i++ {
+		timestamp := from + (to-from)*int64(i)/BALANCE_HISTORY_POINTS
+		for index < len(changes) && changes[index].timestamp <= timestamp {
+			current += int64(changes[index].inflow) - int64(changes[index].outflow)
+			index++
+		}
+
+		point := BalancePoint{Time: time.Unix(timestamp, 0)}
+		if current > 0 {
+			point.Balance = uint64(current)
+		}
+
+		history.Points = append(history.Points, point)
+	}
+
+	// the last point is always the current balance (entries can be slightly in the future of the local clock)
+	history.Points[len(history.Points)-1].Balance = balance
+	return history, nil
+}
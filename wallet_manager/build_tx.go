@@ -0,0 +1,160 @@
+package wallet_manager
+
+import (
+	"encoding/base64"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+)
+
+// max number of transfers in a single tx, every transfer adds a payload with its own ring to the tx
+const MAX_TRANSFERS_PER_TX = 8
+
+// SplitTransfers groups the transfers (in order) into as few txs as possible.
+// A tx without a DERO transfer gets an extra zero DERO transfer when built, so it holds one less transfer.
+func SplitTransfers(transfers []rpc.Transfer) [][]rpc.Transfer {
+	var batches [][]rpc.Transfer
+	var batch []rpc.Transfer
+	hasBase := false
+
+	for _, transfer := range transfers {
+		batchHasBase := hasBase || transfer.SCID.IsZero()
+		count := len(batch) + 1
+		if !batchHasBase {
+			count++
+		}
+
+		if len(batch) > 0 && count > MAX_TRANSFERS_PER_TX {
+			batches = append(batches, batch)
+			batch = nil
+			batchHasBase = transfer.SCID.IsZero()
+		}
+
+		batch = append(batch, transfer)
+		hasBase = batchHasBase
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func FormatSCInvoke(p rpc.SC_Invoke_Params, randomAddr string) (t rpc.Transfer_Params) {
+	if p.SC_DERO_Deposit > 0 {
+		t.Transfers = append(t.Transfers, rpc.Transfer{Destination: randomAddr, Amount: 0, Burn: p.SC_DERO_Deposit})
+	}
+	if p.SC_TOKEN_Deposit > 0 {
+		scid := crypto.HashHexToHash(p.SC_ID)
+		t.Transfers = append(t.Transfers, rpc.Transfer{SCID: scid, Amount: 0, Burn: p.SC_TOKEN_Deposit})
+	}
+	t.SC_RPC = p.SC_RPC
+	t.SC_ID = p.SC_ID
+	t.Ringsize = p.Ringsize
+	return
+}
+
+func FormatTransfer(p rpc.Transfer_Params) (rpc.Transfer_Params, error) {
+	for _, t := range p.Transfers {
+		_, err := t.Payload_RPC.CheckPack(transaction.PAYLOAD0_LIMIT)
+		if err != nil {
+			return p, err
+		}
+	}
+
+	if len(p.SC_Code) >= 1 {
+		sc, err := base64.StdEncoding.DecodeString(p.SC_Code)
+		if err != nil {
+			return p, err
+		}
+
+		p.SC_Code = string(sc)
+	}
+
+	if p.SC_Code != "" && p.SC_ID == "" {
+		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_INSTALL)})
+		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: p.SC_Code})
+	}
+
+	if p.SC_ID != "" {
+		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)})
+		p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCID, DataType: rpc.DataHash, Value: crypto.HashHexToHash(p.SC_ID)})
+		if p.SC_Code != "" {
+			p.SC_RPC = append(p.SC_RPC, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: p.SC_Code})
+		}
+	}
+
+	return p, nil
+}
+
+// EstimateFees expects an already formatted transfer (see FormatTransfer).
+// The signer defaults to the wallet address if the transfer is a smart contract call.
+func (w *Wallet) EstimateFees(p *rpc.Transfer_Params) (txFees uint64, gasFees uint64, err error) {
+	txType := transaction.NORMAL
+	if len(p.SC_RPC) > 0 {
+		txType = transaction.SC_TX
+		if p.Signer == "" {
+			p.Signer = w.Memory.GetAddress().String()
+		}
+
+		gasFees, err = w.Memory.EstimateGasFees(*p)
+		if err != nil {
+			return
+		}
+	}
+
+	txFees = w.Memory.EstimateTxFees(len(p.Transfers), int(p.Ringsize), p.SC_RPC, txType)
+	return
+}
+
+// LockTransfer must be held from PrepareTx to SendTx so two txs can't spend the same balance.
+// The walletapi transfer mutex doesn't apply because the txs are not built with its transfer functions.
+func (w *Wallet) LockTransfer() {
+	w.transferLock.Lock()
+}
+
+func (w *Wallet) UnlockTransfer() {
+	w.transferLock.Unlock()
+}
+
+// BuildAndSendTx prepares, signs and sends the tx while holding the transfer lock.
+// If the tx was broadcasted but could not be stored in the outgoing txs, it's returned with the error.
+func (w *Wallet) BuildAndSendTx(p rpc.Transfer_Params, txFees uint64, gasFees uint64, description string) (*transaction.Transaction, error) {
+	w.LockTransfer()
+	defer w.UnlockTransfer()
+
+	tx, err := w.BuildTx(p, txFees, gasFees)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Memory.SendTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, w.InsertOutgoingTx(tx, &p, description)
+}
+
+// BuildTx prepares and signs the tx in one go (see PrepareTx and SignTx).
+func (w *Wallet) BuildTx(p rpc.Transfer_Params, txFees uint64, gasFees uint64) (*transaction.Transaction, error) {
+	unsignedTx, err := w.PrepareTx(w.Memory.GetAddress().String(), p, txFees, gasFees)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.SignTx(unsignedTx)
+}
+
+// SendTx broadcasts the transaction and keeps track of it in the outgoing txs table.
+// Pass the formatted transfer params to allow rebuilding the tx if it becomes invalid.
+func (w *Wallet) SendTx(tx *transaction.Transaction, p *rpc.Transfer_Params, description string) error {
+	err := w.Memory.SendTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	return w.InsertOutgoingTx(tx, p, description)
+}
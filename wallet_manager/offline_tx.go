@@ -0,0 +1,534 @@
+package wallet_manager
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/deroproject/derohe/config"
+	"github.com/deroproject/derohe/cryptography/bn256"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/errormsg"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+)
+
+// UnsignedTx is everything needed to sign a tx without a node connection.
+// It is prepared online (ring members, balances, topoheight and fees) and signed by the wallet of the Signer address.
+type UnsignedTx struct {
+	Signer       string         `json:"signer"`
+	Transfers    []rpc.Transfer `json:"transfers"`
+	SCData       rpc.Arguments  `json:"sc_data"`
+	Ringsize     uint64         `json:"ringsize"`
+	Rings        [][]string     `json:"rings"`         // ring addresses of each transfer (sender, receiver, members...)
+	RingBalances [][]string     `json:"ring_balances"` // hex encrypted balances of the ring addresses
+	BlockHash    string         `json:"block_hash"`
+	Height       uint64         `json:"height"`
+	Topoheight   int64          `json:"topoheight"`
+	TreeHash     string         `json:"tree_hash"`
+	MaxBits      int            `json:"max_bits"`
+	TxFees       uint64         `json:"tx_fees"`
+	GasFees      uint64         `json:"gas_fees"`
+	Description  string         `json:"description"`
+}
+
+// SignedTx is the result of SignTx ready to be broadcasted by an online instance.
+type SignedTx struct {
+	TxId        string `json:"txid"`
+	HexData     string `json:"hex_data"`
+	Description string `json:"description"`
+}
+
+func (s SignedTx) Transaction() (*transaction.Transaction, error) {
+	data, err := hex.DecodeString(s.HexData)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx transaction.Transaction
+	err = tx.Deserialize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.TxId != "" && tx.GetHash().String() != s.TxId {
+		return nil, fmt.Errorf("txid mismatch")
+	}
+
+	return &tx, nil
+}
+
+// same as walletapi GetEncryptedBalanceAtTopoHeight but for any address (we don't need the keys of the signer)
+func getEncryptedBalance(scId crypto.Hash, topoheight int64, addr string) (result rpc.GetEncryptedBalance_Result, nonceHeight uint64, e *crypto.ElGamal, err error) {
+	err = RPCCall("DERO.GetEncryptedBalance", rpc.GetEncryptedBalance_Params{
+		SCID:       scId,
+		Address:    addr,
+		TopoHeight: topoheight,
+	}, &result)
+	return decodeEncryptedBalance(scId, addr, result, err)
+}
+
+// decodeEncryptedBalance decodes the nonce and balance of a GetEncryptedBalance call (err is the error of the call)
+func decodeEncryptedBalance(scId crypto.Hash, addr string, result rpc.GetEncryptedBalance_Result, err error) (rpc.GetEncryptedBalance_Result, uint64, *crypto.ElGamal, error) {
+	if err != nil {
+		// all SCID users are considered registered and their balance is assumed zero
+		unregistered := strings.Contains(strings.ToLower(err.Error()), strings.ToLower(errormsg.ErrAccountUnregistered.Error()))
+		if !scId.IsZero() && unregistered {
+			address, err := rpc.NewAddress(addr)
+			if err != nil {
+				return result, 0, nil, err
+			}
+
+			result.Bits = 0
+			return result, 0, crypto.ConstructElGamal(address.PublicKey.G1(), crypto.ElGamal_BASE_G), nil
+		}
+
+		return result, 0, nil, err
+	}
+
+	if scId.IsZero() && result.Status != "OK" {
+		return result, 0, nil, fmt.Errorf("%s", result.Status)
+	}
+
+	data, err := hex.DecodeString(result.Data)
+	if err != nil {
+		return result, 0, nil, err
+	}
+
+	var nb crypto.NonceBalance
+	nb.Unmarshal(data)
+	return result, nb.NonceHeight, nb.Balance, nil
+}
+
+func randomRingMembers(scId crypto.Hash, signer string) ([]string, error) {
+	var result rpc.GetRandomAddress_Result
+	err := RPCCall("DERO.GetRandomAddress", rpc.GetRandomAddress_Params{SCID: scId}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	for _, addr := range result.Address {
+		if addr != signer {
+			members = append(members, addr)
+		}
+	}
+
+	return members, nil
+}
+
+func randomRingMember(scId crypto.Hash, signer string) (string, error) {
+	members, err := randomRingMembers(scId, signer)
+	if err != nil {
+		return "", err
+	}
+
+	if len(members) == 0 {
+		return "", fmt.Errorf("could not obtain random ring member for scid %s", scId)
+	}
+
+	return members[0], nil
+}
+
+// PrepareTx does the online part of walletapi TransferFeesPrecomputed for the signer address.
+// The signer keys are not needed, the opened wallet is only used to reach the node.
+// Expects an already formatted transfer with estimated fees (see EstimateFees).
+func (w *Wallet) PrepareTx(signer string, p rpc.Transfer_Params, txFees uint64, gasFees uint64) (*UnsignedTx, error) {
+	signerAddr, err := rpc.NewAddress(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	signer = signerAddr.BaseAddress().String()
+
+	ringsize := p.Ringsize
+	if ringsize == 0 {
+		ringsize = uint64(w.Memory.GetRingSize())
+	}
+
+	if ringsize&(ringsize-1) != 0 {
+		return nil, fmt.Errorf("ringsize should be power of 2. value %d", ringsize)
+	}
+
+	if ringsize < config.MIN_RINGSIZE || ringsize > config.MAX_RINGSIZE {
+		return nil, fmt.Errorf("ringsize out of range value %d", ringsize)
+	}
+
+	transfers := make([]rpc.Transfer, len(p.Transfers))
+	copy(transfers, p.Transfers)
+
+	// SC call without transfer or without base (fees can't be detected), add a zero transfer to a random account
+	hasBase := false
+	for _, transfer := range transfers {
+		if transfer.SCID.IsZero() {
+			hasBase = true
+		}
+	}
+
+	if (len(p.SC_RPC) > 0 && len(transfers) == 0) || (len(transfers) > 0 && !hasBase) {
+		member, err := randomRingMember(crypto.ZEROHASH, signer)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, rpc.Transfer{Destination: member, Amount: 0})
+	}
+
+	for t := range transfers {
+		data, err := transfers[t].Payload_RPC.CheckPack(transaction.PAYLOAD0_LIMIT)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) != transaction.PAYLOAD0_LIMIT {
+			return nil, fmt.Errorf("expecting exactly %d bytes data but have %d bytes", transaction.PAYLOAD0_LIMIT, len(data))
+		}
+
+		if transfers[t].Destination == "" {
+			if transfers[t].SCID.IsZero() {
+				return nil, fmt.Errorf("main destination cannot be empty")
+			}
+
+			member, err := randomRingMember(transfers[t].SCID, signer)
+			if err != nil {
+				member, err = randomRingMember(crypto.ZEROHASH, signer)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			transfers[t].Destination = member
+		}
+
+		_, err = rpc.NewAddress(transfers[t].Destination)
+		if err != nil {
+			name := transfers[t].Destination
+			transfers[t].Destination, err = w.Memory.NameToAddress(name)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode name or address err '%s' name '%s'", err, name)
+			}
+		}
+	}
+
+	daemonResult, nonceTopo, _, err := getEncryptedBalance(crypto.ZEROHASH, -1, signer)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain encrypted balance for signer err %s", err)
+	}
+
+	// if wallet has not been recently used, increase probability of the tx being successfully mined
+	topoheight := daemonResult.DTopoheight
+	if daemonResult.DTopoheight >= int64(nonceTopo)+3 {
+		topoheight = daemonResult.DTopoheight - 3
+	}
+
+	// every send goes through here so all the balances of the tx (signer, receivers and ring members)
+	// are read at the same topoheight and verified in one place
+	balances := &balanceReader{}
+	er, _, _, err := balances.get(transfers[0].SCID, topoheight, signer)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain encrypted balance for signer err %s", err)
+	}
+
+	treeHash, err := hex.DecodeString(er.Merkle_Balance_TreeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(treeHash) != 32 {
+		return nil, fmt.Errorf("roothash is not of 32 bytes, probably daemon corruption '%s'", er.Merkle_Balance_TreeHash)
+	}
+
+	unsignedTx := &UnsignedTx{
+		Signer:     signer,
+		SCData:     p.SC_RPC,
+		Ringsize:   ringsize,
+		BlockHash:  er.BlockHash.String(),
+		Height:     uint64(er.Height),
+		Topoheight: er.Topoheight,
+		TreeHash:   er.Merkle_Balance_TreeHash,
+		TxFees:     txFees,
+		GasFees:    gasFees,
+	}
+
+	maxBits := 0
+	for t := range transfers {
+		bitsNeeded := make([]int, ringsize)
+		var ring []string
+		var ringBalances []string
+
+		selfResult, _, selfE, err := balances.get(transfers[t].SCID, topoheight, signer)
+		if err != nil {
+			return nil, err
+		}
+
+		bitsNeeded[0] = selfResult.Bits
+		ring = append(ring, signer)
+		ringBalances = append(ringBalances, hex.EncodeToString(selfE.Serialize()))
+
+		addr, err := rpc.NewAddress(transfers[t].Destination)
+		if err != nil {
+			return nil, err
+		}
+
+		if addr.IsIntegratedAddress() && addr.Arguments.Validate_Arguments() != nil {
+			return nil, fmt.Errorf("integrated address arguments could not be validated")
+		}
+
+		if addr.IsIntegratedAddress() && len(transfers[t].Payload_RPC) == 0 {
+			for _, arg := range addr.Arguments {
+				if arg.Name == rpc.RPC_DESTINATION_PORT && addr.Arguments.Has(rpc.RPC_DESTINATION_PORT, rpc.DataUint64) {
+					transfers[t].Payload_RPC = append(transfers[t].Payload_RPC, rpc.Argument{Name: rpc.RPC_DESTINATION_PORT, DataType: rpc.DataUint64, Value: addr.Arguments.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64)})
+					continue
+				}
+
+				return nil, fmt.Errorf("integrated address used, but don't know how to process %+v", addr.Arguments)
+			}
+		}
+
+		receiver := addr.BaseAddress().String()
+		if receiver == signer {
+			return nil, fmt.Errorf("sending to self is not supported")
+		}
+
+		destResult, _, destE, err := balances.get(transfers[t].SCID, topoheight, receiver)
+		if err != nil {
+			return nil, err
+		}
+
+		bitsNeeded[1] = destResult.Bits
+		ring = append(ring, receiver)
+		ringBalances = append(ringBalances, hex.EncodeToString(destE.Serialize()))
+
+		deduplicator := map[string]bool{receiver: true, signer: true}
+		for tries := 0; len(ring) < int(ringsize); tries++ {
+			if tries >= 100 {
+				return nil, fmt.Errorf("could not obtain enough ring members for scid %s", transfers[t].SCID)
+			}
+
+			members, err := randomRingMembers(transfers[t].SCID, signer)
+			if err != nil {
+				return nil, err
+			}
+
+			// we do not have enough ring members for sure, extract ring members from base
+			if len(members) <= 40 {
+				members, err = randomRingMembers(crypto.ZEROHASH, signer)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for _, member := range members {
+				if deduplicator[member] || len(ring) >= int(ringsize) {
+					continue
+				}
+
+				deduplicator[member] = true
+				memberResult, _, memberE, err := balances.get(transfers[t].SCID, topoheight, member)
+				if err != nil {
+					return nil, err
+				}
+
+				bitsNeeded[len(ring)] = memberResult.Bits
+				ring = append(ring, member)
+				ringBalances = append(ringBalances, hex.EncodeToString(memberE.Serialize()))
+			}
+		}
+
+		for _, bits := range bitsNeeded {
+			if bits > maxBits {
+				maxBits = bits
+			}
+		}
+
+		unsignedTx.Rings = append(unsignedTx.Rings, ring)
+		unsignedTx.RingBalances = append(unsignedTx.RingBalances, ringBalances)
+	}
+
+	err = ConfirmCrossCheck(balances.crossCheckErr)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTx.MaxBits = maxBits + 6 // extra 6 bits
+	unsignedTx.Transfers = transfers
+	return unsignedTx, nil
+}
+
+// validate checks the data of an unsigned tx that can come from an untrusted file.
+// BuildTransaction expects valid data and panics otherwise.
+func (u *UnsignedTx) validate() error {
+	if u.Ringsize&(u.Ringsize-1) != 0 || u.Ringsize < config.MIN_RINGSIZE || u.Ringsize > config.MAX_RINGSIZE {
+		return fmt.Errorf("invalid ringsize %d", u.Ringsize)
+	}
+
+	// BuildTransaction adds 3 bits before checking the 240 bits limit
+	if u.MaxBits < 0 || u.MaxBits+3 >= 240 {
+		return fmt.Errorf("invalid max bits %d", u.MaxBits)
+	}
+
+	if len(u.Transfers) == 0 || len(u.Rings) != len(u.Transfers) || len(u.RingBalances) != len(u.Transfers) {
+		return fmt.Errorf("invalid unsigned transaction")
+	}
+
+	for t, transfer := range u.Transfers {
+		addr, err := rpc.NewAddress(transfer.Destination)
+		if err != nil {
+			return fmt.Errorf("invalid destination for transfer %d: %s", t, err)
+		}
+
+		data, err := transfer.Payload_RPC.CheckPack(transaction.PAYLOAD0_LIMIT)
+		if err != nil {
+			return err
+		}
+
+		if len(data) != transaction.PAYLOAD0_LIMIT {
+			return fmt.Errorf("invalid payload for transfer %d", t)
+		}
+
+		if len(u.Rings[t]) != int(u.Ringsize) || len(u.RingBalances[t]) != int(u.Ringsize) {
+			return fmt.Errorf("invalid ring for transfer %d", t)
+		}
+
+		// the ring must start with the signer followed by the receiver
+		if u.Rings[t][0] != u.Signer || u.Rings[t][1] != addr.BaseAddress().String() {
+			return fmt.Errorf("invalid ring for transfer %d", t)
+		}
+
+		for i := range u.Rings[t] {
+			_, err := rpc.NewAddress(u.Rings[t][i])
+			if err != nil {
+				return err
+			}
+
+			balance, err := hex.DecodeString(u.RingBalances[t][i])
+			if err != nil {
+				return err
+			}
+
+			// serialized elgamal (two compressed points)
+			if len(balance) != 66 {
+				return fmt.Errorf("invalid encrypted balance for transfer %d", t)
+			}
+		}
+	}
+
+	blockHash, err := hex.DecodeString(u.BlockHash)
+	if err != nil || len(blockHash) != 32 {
+		return fmt.Errorf("invalid block hash")
+	}
+
+	treeHash, err := hex.DecodeString(u.TreeHash)
+	if err != nil || len(treeHash) != 32 {
+		return fmt.Errorf("invalid tree hash")
+	}
+
+	return nil
+}
+
+// SignTx builds and signs the prepared tx with walletapi BuildTransaction (the offline part of TransferFeesPrecomputed).
+// It does not need a node connection.
+func (w *Wallet) SignTx(u *UnsignedTx) (tx *transaction.Transaction, err error) {
+	if w.IsLocked() {
+		return nil, ErrReadOnly
+	}
+
+	if u.Signer != w.Memory.GetAddress().String() {
+		return nil, fmt.Errorf("the transaction must be signed by %s", u.Signer)
+	}
+
+	err = u.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// the unsigned tx can come from a file, never crash the app on data we didn't think of
+	defer func() {
+		if r := recover(); r != nil {
+			tx = nil
+			err = fmt.Errorf("could not build the transaction: %v", r)
+		}
+	}()
+
+	var rings [][]*bn256.G1
+	var ringsBalances [][][]byte
+	for t := range u.Transfers {
+		var ring []*bn256.G1
+		var ringBalances [][]byte
+		for i := range u.Rings[t] {
+			addr, err := rpc.NewAddress(u.Rings[t][i])
+			if err != nil {
+				return nil, err
+			}
+
+			balance, err := hex.DecodeString(u.RingBalances[t][i])
+			if err != nil {
+				return nil, err
+			}
+
+			ring = append(ring, addr.PublicKey.G1())
+			ringBalances = append(ringBalances, balance)
+		}
+
+		rings = append(rings, ring)
+		ringsBalances = append(ringsBalances, ringBalances)
+	}
+
+	// check funds with our own encrypted balance (first of each ring)
+	required := make(map[crypto.Hash]uint64)
+	balances := make(map[crypto.Hash]uint64)
+	for t, transfer := range u.Transfers {
+		required[transfer.SCID] += transfer.Amount + transfer.Burn
+		if _, ok := balances[transfer.SCID]; !ok {
+			e := new(crypto.ElGamal).Deserialize(ringsBalances[t][0])
+			balances[transfer.SCID] = w.Memory.DecodeEncryptedBalanceNow(e)
+		}
+	}
+	required[crypto.ZEROHASH] += u.TxFees + u.GasFees
+
+	for scId, amount := range required {
+		if amount > balances[scId] {
+			return nil, fmt.Errorf("insufficent funds for scid %s need %d actual %d", scId, amount, balances[scId])
+		}
+	}
+
+	blockHash := crypto.HashHexToHash(u.BlockHash)
+	treeHash, err := hex.DecodeString(u.TreeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// BuildTransaction modifies the transfers
+	transfers := make([]rpc.Transfer, len(u.Transfers))
+	copy(transfers, u.Transfers)
+
+	tx = w.Memory.BuildTransaction(transfers, ringsBalances, rings, blockHash, u.Height, u.SCData, treeHash, u.MaxBits, u.GasFees, u.TxFees)
+	if tx == nil {
+		return nil, fmt.Errorf("somehow the tx could not be built, please retry")
+	}
+
+	return tx, nil
+}
+
+func NewSignedTx(tx *transaction.Transaction, description string) SignedTx {
+	return SignedTx{
+		TxId:        tx.GetHash().String(),
+		HexData:     hex.EncodeToString(tx.Serialize()),
+		Description: description,
+	}
+}
+
+// SendSignedTx broadcasts a tx signed by another instance (see SignTx) and keeps track of it in the outgoing txs table.
+func (w *Wallet) SendSignedTx(s SignedTx) (*transaction.Transaction, error) {
+	tx, err := s.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.SendTx(tx, nil, s.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -1,43 +1,86 @@
 package wallet_manager
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/deroproject/derohe/block"
+	"github.com/deroproject/derohe/config"
+	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
 	"github.com/deroproject/derohe/transaction"
 	"github.com/deroproject/derohe/walletapi"
+	"github.com/g45t345rt/g45w/app_db/schema_version"
 )
 
 type OutgoingTx struct {
-	TxId        string
-	HeightBuilt sql.NullInt64
-	Timestamp   sql.NullInt64
-	Status      sql.NullString
-	TxType      sql.NullInt32
-	HexData     sql.NullString
-	BlockHeight sql.NullInt64
-	Description sql.NullString
+	TxId           string
+	HeightBuilt    sql.NullInt64
+	Timestamp      sql.NullInt64
+	Status         sql.NullString
+	TxType         sql.NullInt32
+	HexData        sql.NullString
+	BlockHeight    sql.NullInt64
+	Description    sql.NullString
+	PendingHeight  int64 // chain height when the tx was (re)broadcasted, used to expire it
+	TransferParams sql.NullString
 }
 
 func initTableOutgoingTxs(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS outgoing_txs (
-			tx_id VARCHAR PRIMARY KEY,
-			height_built BIGINT,
-			timestamp BIGINT,
-			status VARCHAR,
-			tx_type VARCHAR,
-			hex_data VARCHAR,
-			block_height BIGINT,
-			description VARCHAR
-		);
-	`)
-	return err
+	version, err := schema_version.GetVersion(db, "outgoing_txs")
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS outgoing_txs (
+				tx_id VARCHAR PRIMARY KEY,
+				height_built BIGINT,
+				timestamp BIGINT,
+				status VARCHAR,
+				tx_type VARCHAR,
+				hex_data VARCHAR,
+				block_height BIGINT,
+				description VARCHAR
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 1
+		err = schema_version.StoreVersion(db, "outgoing_txs", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if version == 1 {
+		// pending txs expire with the chain height (since the last broadcast) instead of the number of checks
+		// transfer_params is kept to rebuild the tx with a fresh ring if it becomes invalid
+		_, err = db.Exec(`
+			ALTER TABLE outgoing_txs ADD COLUMN pending_height BIGINT NOT NULL DEFAULT 0;
+			ALTER TABLE outgoing_txs ADD COLUMN transfer_params VARCHAR;
+			UPDATE outgoing_txs SET pending_height = height_built WHERE height_built IS NOT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 2
+		err = schema_version.StoreVersion(db, "outgoing_txs", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func rowsScanOutgoingTxs(rows *sql.Rows) ([]OutgoingTx, error) {
@@ -55,6 +98,8 @@ func rowsScanOutgoingTxs(rows *sql.Rows) ([]OutgoingTx, error) {
 			&outgoingTx.HexData,
 			&outgoingTx.BlockHeight,
 			&outgoingTx.Description,
+			&outgoingTx.PendingHeight,
+			&outgoingTx.TransferParams,
 		)
 		if err != nil {
 			return nil, err
@@ -72,19 +117,48 @@ func rowsScanOutgoingTxs(rows *sql.Rows) ([]OutgoingTx, error) {
 }
 
 type GetOutgoingTxsParams struct {
-	Descending bool
-	OrderBy    string
-	Limit      uint64
-	TxType     *transaction.TransactionType
+	Descending    bool
+	OrderBy       string
+	Limit         uint64
+	Offset        uint64
+	TxType        *transaction.TransactionType
+	Status        sql.NullString
+	Search        sql.NullString // txid or description
+	FromTimestamp sql.NullInt64
+	ToTimestamp   sql.NullInt64
 }
 
-func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, error) {
-	query := sq.Select("*").From("outgoing_txs")
-
+func filterOutgoingTxs(query sq.SelectBuilder, params GetOutgoingTxsParams) sq.SelectBuilder {
 	if params.TxType != nil {
 		query = query.Where(sq.Eq{"tx_type": params.TxType})
 	}
 
+	if params.Status.Valid {
+		query = query.Where(sq.Eq{"status": params.Status.String})
+	}
+
+	if params.Search.Valid {
+		search := fmt.Sprintf("%%%s%%", params.Search.String)
+		query = query.Where(sq.Or{
+			sq.Like{"tx_id": search},
+			sq.Like{"description": search},
+		})
+	}
+
+	if params.FromTimestamp.Valid {
+		query = query.Where(sq.GtOrEq{"timestamp": params.FromTimestamp.Int64})
+	}
+
+	if params.ToTimestamp.Valid {
+		query = query.Where(sq.Lt{"timestamp": params.ToTimestamp.Int64})
+	}
+
+	return query
+}
+
+func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, error) {
+	query := filterOutgoingTxs(sq.Select("*").From("outgoing_txs"), params)
+
 	if len(params.OrderBy) > 0 {
 		direction := "ASC"
 		if params.Descending {
@@ -98,6 +172,10 @@ func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, erro
 		query = query.Limit(params.Limit)
 	}
 
+	if params.Offset > 0 {
+		query = query.Offset(params.Offset)
+	}
+
 	rows, err := query.RunWith(w.DB).Query()
 	if err != nil {
 		return nil, err
@@ -106,7 +184,51 @@ func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, erro
 	return rowsScanOutgoingTxs(rows)
 }
 
-var pendingTries map[string]int
+// CountOutgoingTxs returns the number of txs matching the filters (limit and offset are ignored).
+func (w *Wallet) CountOutgoingTxs(params GetOutgoingTxsParams) (count uint64, err error) {
+	query := filterOutgoingTxs(sq.Select("COUNT(*)").From("outgoing_txs"), params)
+	err = query.RunWith(w.DB).QueryRow().Scan(&count)
+	return
+}
+
+func (w *Wallet) GetOutgoingTx(txId string) (*OutgoingTx, error) {
+	query := sq.Select("*").From("outgoing_txs").Where(sq.Eq{"tx_id": txId})
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+
+	outgoingTxs, err := rowsScanOutgoingTxs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outgoingTxs) == 0 {
+		return nil, nil
+	}
+
+	return &outgoingTxs[0], nil
+}
+
+// Transaction decodes the stored hex data.
+func (o OutgoingTx) Transaction() (*transaction.Transaction, error) {
+	data, err := hex.DecodeString(o.HexData.String)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx transaction.Transaction
+	err = tx.Deserialize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+// number of blocks (around 18s each) after the broadcast before a pending tx that is not in a valid block is set invalid
+const MAX_PENDING_BLOCKS = 25
 
 func (w *Wallet) CheckRegistrationTx(tx transaction.Transaction) (rpc.GetEncryptedBalance_Result, bool, error) {
 	// registration does not give a valid block even if successful
@@ -152,10 +274,6 @@ func (w *Wallet) UpdatePendingOutgoingTxs() (int, error) {
 		return 0, nil
 	}
 
-	if pendingTries == nil {
-		pendingTries = make(map[string]int)
-	}
-
 	rows, err := w.DB.Query(`
 		SELECT *
 		FROM outgoing_txs
@@ -188,18 +306,20 @@ func (w *Wallet) UpdatePendingOutgoingTxs() (int, error) {
 	}
 
 	updated := 0
+	daemonHeight := walletapi.Get_Daemon_Height()
 
 	for i, info := range txResult.Txs {
-		txHex := txResult.Txs_as_hex[i]
+		outgoingTx := outgoingTxs[i]
+		txId := outgoingTx.TxId
 
+		// use the stored hex, the node returns an empty hex if the tx is unknown
 		var tx transaction.Transaction
-		data, _ := hex.DecodeString(txHex)
+		data, _ := hex.DecodeString(outgoingTx.HexData.String)
 		err := tx.Deserialize(data)
 		if err != nil {
 			return updated, err
 		}
 
-		txId := tx.GetHash().String()
 		valid := false
 		var blockHeight int64
 
@@ -225,23 +345,29 @@ func (w *Wallet) UpdatePendingOutgoingTxs() (int, error) {
 			}
 
 			updated += 1
-		} else {
-			// if after 30 tries the transaction is still not in a valid block we set invalid status
-			tries, ok := pendingTries[txId]
-			if !ok {
-				pendingTries[txId] = 1
+			continue
+		}
+
+		// the transaction is still not in a valid block after MAX_PENDING_BLOCKS, we set invalid status
+		// this doesn't depend on how often the pending txs are checked
+		if daemonHeight > 0 && daemonHeight >= outgoingTx.PendingHeight+MAX_PENDING_BLOCKS {
+			err = w.UpdateOugoingTx(txId, "invalid", 0)
+			if err != nil {
+				return updated, err
 			}
 
-			if tries >= 30 {
-				err = w.UpdateOugoingTx(txId, "invalid", 0)
-				if err != nil {
-					return updated, err
-				}
+			updated += 1
+			continue
+		}
 
-				updated += 1
-				delete(pendingTries, txId)
-			} else {
-				pendingTries[txId] = tries + 1
+		// the node dropped the tx or never received it (node restart, switched node...)
+		// the pending height is not reset so a tx that keeps getting rejected ends up invalid
+		// stop rebroadcasting in the second half so the tx is less likely to be mined after it's marked invalid
+		rebroadcastUntil := outgoingTx.PendingHeight + MAX_PENDING_BLOCKS/2
+		if !info.In_pool && tx.TransactionType != transaction.REGISTRATION && daemonHeight < rebroadcastUntil {
+			err = rebroadcastTx(outgoingTx.HexData.String)
+			if err != nil {
+				fmt.Println(err)
 			}
 		}
 	}
@@ -249,6 +375,50 @@ func (w *Wallet) UpdatePendingOutgoingTxs() (int, error) {
 	return updated, nil
 }
 
+// WaitOutgoingTx blocks until the pending tx is in a valid block or marked as invalid.
+// A tx spends the encrypted balance so the next one can only be built once the previous one is mined.
+// It stops when the context is canceled, the wallet is closed or after about MAX_PENDING_BLOCKS blocks.
+func (w *Wallet) WaitOutgoingTx(ctx context.Context, txId string) (*OutgoingTx, error) {
+	// a few more blocks than the pending limit to let UpdatePendingOutgoingTxs set the tx invalid
+	timeout := time.After(time.Duration(MAX_PENDING_BLOCKS+5) * time.Duration(config.BLOCK_TIME) * time.Second)
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-w.Memory.Quit:
+			return nil, fmt.Errorf("wallet closed")
+		case <-timeout:
+			if lastErr != nil {
+				return nil, lastErr
+			}
+
+			return nil, fmt.Errorf("outgoing tx [%s] is still pending", txId)
+		case <-time.After(5 * time.Second):
+		}
+
+		// the node can be unreachable for a while, keep trying until the timeout
+		_, lastErr = w.UpdatePendingOutgoingTxs()
+		if lastErr != nil {
+			continue
+		}
+
+		outgoingTx, err := w.GetOutgoingTx(txId)
+		if err != nil {
+			return nil, err
+		}
+
+		if outgoingTx == nil {
+			return nil, fmt.Errorf("outgoing tx [%s] not found", txId)
+		}
+
+		if outgoingTx.Status.String != "pending" {
+			return outgoingTx, nil
+		}
+	}
+}
+
 func (w *Wallet) UpdateOugoingTx(txId string, status string, blockHeight int64) error {
 	_, err := w.DB.Exec(`
 		UPDATE outgoing_txs
@@ -258,20 +428,193 @@ func (w *Wallet) UpdateOugoingTx(txId string, status string, blockHeight int64)
 	return err
 }
 
-func (w *Wallet) InsertOutgoingTx(tx *transaction.Transaction, description string) error {
+// InsertOutgoingTx keeps track of a sent tx.
+// The transfer params are optional and only used to rebuild the tx if it becomes invalid (see RebuildOutgoingTx).
+func (w *Wallet) InsertOutgoingTx(tx *transaction.Transaction, p *rpc.Transfer_Params, description string) error {
 	txId := tx.GetHash().String()
 	height := tx.Height
 	txType := tx.TransactionType
 	hexData := hex.EncodeToString(tx.Serialize())
 
+	var transferParams sql.NullString
+	if p != nil {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		transferParams = sql.NullString{String: string(data), Valid: true}
+	}
+
 	_, err := w.DB.Exec(`
-		INSERT INTO outgoing_txs (tx_id,height_built,tx_type,timestamp,status,hex_data,description)
-		VALUES (?,?,?,?,?,?,?)
+		INSERT INTO outgoing_txs (tx_id,height_built,tx_type,timestamp,status,hex_data,description,pending_height,transfer_params)
+		VALUES (?,?,?,?,?,?,?,?,?)
 		ON CONFLICT DO NOTHING;
-	`, txId, height, txType, time.Now().Unix(), "pending", hexData, description)
+	`, txId, height, txType, time.Now().Unix(), "pending", hexData, description, height, transferParams)
 	return err
 }
 
+func rebroadcastTx(hexData string) error {
+	var result rpc.SendRawTransaction_Result
+	err := RPCCall("DERO.SendRawTransaction", rpc.SendRawTransaction_Params{
+		Tx_as_hex: hexData,
+	}, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Status != "OK" {
+		return fmt.Errorf("Err %s", result.Status)
+	}
+
+	return nil
+}
+
+// RebroadcastOutgoingTx sends the stored tx to the node again and gives it another MAX_PENDING_BLOCKS to be mined.
+func (w *Wallet) RebroadcastOutgoingTx(txId string) error {
+	outgoingTx, err := w.GetOutgoingTx(txId)
+	if err != nil {
+		return err
+	}
+
+	if outgoingTx == nil {
+		return fmt.Errorf("outgoing tx [%s] not found", txId)
+	}
+
+	err = rebroadcastTx(outgoingTx.HexData.String)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.DB.Exec(`
+		UPDATE outgoing_txs
+		SET status = ?, pending_height = ?
+		WHERE tx_id = ?;
+	`, "pending", walletapi.Get_Daemon_Height(), txId)
+	return err
+}
+
+// checkOutgoingTxUnspent makes sure an invalid tx can't be mined anymore before it's sent again.
+// Another node can still have the old tx in its pool and mine it after it was marked invalid.
+func (w *Wallet) checkOutgoingTxUnspent(tx *transaction.Transaction) error {
+	var txResult rpc.GetTransaction_Result
+	err := RPCCall("DERO.GetTransaction", rpc.GetTransaction_Params{
+		Tx_Hashes: []string{tx.GetHash().String()},
+	}, &txResult)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range txResult.Txs {
+		if info.In_pool {
+			return fmt.Errorf("the tx is still in the mempool, wait until it's mined or dropped")
+		}
+
+		if info.ValidBlock != "" {
+			return fmt.Errorf("the tx was mined in block %s", info.ValidBlock)
+		}
+	}
+
+	// the tx spends the balances it was built from, if they changed the tx might have been mined elsewhere
+	var blockResult rpc.GetBlockHeaderByHash_Result
+	err = RPCCall("DERO.GetBlockHeaderByHash", rpc.GetBlockHeaderByHash_Params{
+		Hash: crypto.Hash(tx.BLID).String(),
+	}, &blockResult)
+	if err != nil {
+		return err
+	}
+
+	checked := make(map[crypto.Hash]bool)
+	for _, payload := range tx.Payloads {
+		if checked[payload.SCID] {
+			continue
+		}
+
+		checked[payload.SCID] = true
+
+		var builtBalance, balance rpc.GetEncryptedBalance_Result
+		err = RPCCall("DERO.GetEncryptedBalance", rpc.GetEncryptedBalance_Params{
+			Address:    w.Info.Addr,
+			SCID:       payload.SCID,
+			TopoHeight: blockResult.Block_Header.TopoHeight,
+		}, &builtBalance)
+		if err != nil {
+			return err
+		}
+
+		err = RPCCall("DERO.GetEncryptedBalance", rpc.GetEncryptedBalance_Params{
+			Address:    w.Info.Addr,
+			SCID:       payload.SCID,
+			TopoHeight: -1,
+		}, &balance)
+		if err != nil {
+			return err
+		}
+
+		if builtBalance.Data != balance.Data {
+			return fmt.Errorf("the balance changed since the tx was built and the tx might have been mined, check the wallet history before sending again")
+		}
+	}
+
+	return nil
+}
+
+// RebuildOutgoingTx builds and sends a new tx (fresh ring and fees) from the stored transfer params of an invalid tx.
+// It's refused if the node still knows the old tx or if the balances it spends changed.
+// The old tx is kept with the replaced status.
+func (w *Wallet) RebuildOutgoingTx(txId string) (*transaction.Transaction, error) {
+	outgoingTx, err := w.GetOutgoingTx(txId)
+	if err != nil {
+		return nil, err
+	}
+
+	if outgoingTx == nil {
+		return nil, fmt.Errorf("outgoing tx [%s] not found", txId)
+	}
+
+	if outgoingTx.Status.String != "invalid" {
+		return nil, fmt.Errorf("only an invalid tx can be rebuilt")
+	}
+
+	if !outgoingTx.TransferParams.Valid {
+		return nil, fmt.Errorf("the transfer details of this tx were not saved")
+	}
+
+	var p rpc.Transfer_Params
+	err = json.Unmarshal([]byte(outgoingTx.TransferParams.String), &p)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTx, err := outgoingTx.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.checkOutgoingTxUnspent(oldTx)
+	if err != nil {
+		return nil, err
+	}
+
+	txFees, gasFees, err := w.EstimateFees(&p)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := w.BuildAndSendTx(p, txFees, gasFees, outgoingTx.Description.String)
+	if tx == nil {
+		return nil, err
+	}
+
+	// the new tx is broadcasted, the old one must not be rebuilt again
+	updateErr := w.UpdateOugoingTx(txId, "replaced", 0)
+	if err == nil {
+		err = updateErr
+	}
+
+	return tx, err
+}
+
 func (w *Wallet) DelOutgoingTx(txId string) error {
 	_, err := w.DB.Exec(`
 		DELETE FROM outgoing_txs
@@ -0,0 +1,85 @@
+package wallet_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/node_manager"
+)
+
+// CrossCheckSC returns the SC variables of the current node after comparing them with the other nodes.
+// It's a simple GetSC call if the paranoid mode is off.
+func CrossCheckSC(scId string) (result rpc.GetSC_Result, err error) {
+	if !node_manager.IsParanoid() {
+		err = RPCCall("DERO.GetSC", rpc.GetSC_Params{
+			SCID:      scId,
+			Code:      false,
+			Variables: true,
+		}, &result)
+		return
+	}
+
+	return node_manager.CrossCheck("DERO.GetSC",
+		func(topoHeight int64) interface{} {
+			return rpc.GetSC_Params{
+				SCID:       scId,
+				Code:       false,
+				Variables:  true,
+				TopoHeight: topoHeight,
+			}
+		},
+		func(result rpc.GetSC_Result) (string, error) {
+			// json sorts map keys so the output can be compared
+			data, err := json.Marshal([]interface{}{
+				result.VariableStringKeys,
+				result.VariableUint64Keys,
+				result.Balances,
+			})
+			return string(data), err
+		},
+	)
+}
+
+// ConfirmCrossCheck handles the error of the balances cross-check done by PrepareTx.
+// The error is returned as is by default, the ui replaces it to let the user continue in warn mode.
+var ConfirmCrossCheck = func(err error) error {
+	return err
+}
+
+// balanceReader reads the encrypted balances used by PrepareTx.
+// In paranoid mode every balance is compared with the other nodes at the topoheight of the tx.
+// The first failed cross-check is kept so the user only confirms once, the next balances are read from the current node.
+type balanceReader struct {
+	crossCheckErr error
+}
+
+func (r *balanceReader) get(scId crypto.Hash, topoheight int64, addr string) (result rpc.GetEncryptedBalance_Result, nonceHeight uint64, e *crypto.ElGamal, err error) {
+	if !node_manager.IsParanoid() || r.crossCheckErr != nil {
+		return getEncryptedBalance(scId, topoheight, addr)
+	}
+
+	result, err = node_manager.CrossCheckAt("DERO.GetEncryptedBalance", topoheight,
+		rpc.GetEncryptedBalance_Params{
+			Address:    addr,
+			SCID:       scId,
+			TopoHeight: topoheight,
+		},
+		func(result rpc.GetEncryptedBalance_Result) (string, error) {
+			return fmt.Sprintf("%s:%d:%s", result.Data, result.Registration, result.BlockHash), nil
+		},
+	)
+
+	var divergenceErr *node_manager.DivergenceError
+	if errors.As(err, &divergenceErr) {
+		r.crossCheckErr = err
+		err = nil
+	} else if errors.Is(err, node_manager.ErrNotEnoughNodes) {
+		r.crossCheckErr = err
+		return getEncryptedBalance(scId, topoheight, addr)
+	}
+
+	return decodeEncryptedBalance(scId, addr, result, err)
+}
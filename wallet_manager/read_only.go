@@ -0,0 +1,157 @@
+package wallet_manager
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/g45t345rt/g45w/app_db"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The balances can't be decrypted without the wallet secret so the viewing PIN is only an access control of the app.
+// The wallet password is stored encrypted with the PIN and the spend actions ask for the full password.
+// Anyone with a copy of the app data can guess the PIN offline to recover the password so the PIN must be strong.
+
+const VIEWING_PIN_MIN_LENGTH = 8
+const VIEWING_PIN_MIN_UNIQUE_CHARS = 4
+const VIEWING_SALT_SIZE = 16
+const VIEWING_KEY_ITERATIONS = 200000
+
+var ErrInvalidPIN = fmt.Errorf("invalid PIN")
+var ErrReadOnly = fmt.Errorf("the wallet is opened in read-only mode, open it with the full password")
+
+func deriveViewingKey(pin string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(pin), salt, VIEWING_KEY_ITERATIONS, 32, sha256.New)
+}
+
+// viewing data layout: salt | encrypted password (hex encoded)
+func encryptViewingData(pin string, password string) (string, error) {
+	salt := make([]byte, VIEWING_SALT_SIZE)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := walletapi.EncryptWithKey(deriveViewingKey(pin, salt), []byte(password))
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(append(salt, encrypted...)), nil
+}
+
+func decryptViewingData(pin string, viewingData string) (string, error) {
+	data, err := hex.DecodeString(viewingData)
+	if err != nil || len(data) <= VIEWING_SALT_SIZE {
+		return "", ErrInvalidPIN
+	}
+
+	salt := data[:VIEWING_SALT_SIZE]
+	password, err := walletapi.DecryptWithKey(deriveViewingKey(pin, salt), data[VIEWING_SALT_SIZE:])
+	if err != nil {
+		return "", ErrInvalidPIN
+	}
+
+	return string(password), nil
+}
+
+// OpenWalletWithPIN opens a read-only wallet. The spend actions stay locked until the wallet is opened with the password.
+func OpenWalletWithPIN(addr string, pin string) error {
+	walletInfo, err := app_db.GetWalletInfo(addr)
+	if err != nil {
+		return err
+	}
+
+	if !walletInfo.ReadOnly {
+		return ErrInvalidPIN
+	}
+
+	password, err := decryptViewingData(pin, walletInfo.ViewingData)
+	if err != nil {
+		return err
+	}
+
+	err = OpenWallet(addr, password)
+	if err != nil {
+		return err
+	}
+
+	OpenedWallet.locked = true
+	return nil
+}
+
+// IsLocked returns true if the wallet was opened with the viewing PIN
+func (w *Wallet) IsLocked() bool {
+	return w.locked
+}
+
+// reject short PINs and the easy ones like 11111111 or 12345678
+func checkViewingPIN(pin string) error {
+	chars := []rune(pin)
+	if len(chars) < VIEWING_PIN_MIN_LENGTH {
+		return fmt.Errorf("the PIN must have at least %d characters", VIEWING_PIN_MIN_LENGTH)
+	}
+
+	uniqueChars := make(map[rune]bool)
+	ascending, descending := true, true
+	for i, c := range chars {
+		uniqueChars[c] = true
+		if i > 0 {
+			ascending = ascending && c == chars[i-1]+1
+			descending = descending && c == chars[i-1]-1
+		}
+	}
+
+	if len(uniqueChars) < VIEWING_PIN_MIN_UNIQUE_CHARS || ascending || descending {
+		return fmt.Errorf("the PIN is too easy to guess, use at least %d different characters and avoid sequences", VIEWING_PIN_MIN_UNIQUE_CHARS)
+	}
+
+	return nil
+}
+
+func (w *Wallet) EnableReadOnly(password string, pin string) error {
+	err := checkViewingPIN(pin)
+	if err != nil {
+		return err
+	}
+
+	if pin == password {
+		return fmt.Errorf("the PIN can't be the wallet password")
+	}
+
+	if !w.Memory.Check_Password(password) {
+		return fmt.Errorf("invalid password")
+	}
+
+	viewingData, err := encryptViewingData(pin, password)
+	if err != nil {
+		return err
+	}
+
+	walletInfo := w.Info
+	walletInfo.ReadOnly = true
+	walletInfo.ViewingData = viewingData
+	err = app_db.UpdateWalletInfo(walletInfo)
+	if err != nil {
+		return err
+	}
+
+	w.Info = walletInfo
+	return nil
+}
+
+func (w *Wallet) DisableReadOnly() error {
+	walletInfo := w.Info
+	walletInfo.ReadOnly = false
+	walletInfo.ViewingData = ""
+	err := app_db.UpdateWalletInfo(walletInfo)
+	if err != nil {
+		return err
+	}
+
+	w.Info = walletInfo
+	return nil
+}
@@ -0,0 +1,607 @@
+package wallet_manager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/creachadair/jrpc2/jhttp"
+	"github.com/deroproject/derohe/config"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/g45t345rt/g45w/settings"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// RPCServer is a classic wallet JSON-RPC endpoint (like the derohe wallet --rpc-server)
+// protected with basic auth. Unlike XSWD, calls are not confirmed by the user.
+type RPCServer struct {
+	Addr string
+
+	wallet       *Wallet
+	username     string
+	passwordHash string
+	srv          *http.Server
+	bridge       jhttp.Bridge
+}
+
+func DefaultRPCServerAddr() string {
+	port := config.Mainnet.Wallet_RPC_Default_Port
+	if !globals.IsMainnet() {
+		port = config.Testnet.Wallet_RPC_Default_Port
+	}
+
+	return fmt.Sprintf("127.0.0.1:%d", port)
+}
+
+const RPC_PASSWORD_SALT_SIZE = 16
+
+// low enough to check the password on every request
+const RPC_PASSWORD_ITERATIONS = 10000
+
+// password hash layout: salt | pbkdf2 key (hex encoded)
+func hashRPCServerPassword(password string, salt []byte) string {
+	key := pbkdf2.Key([]byte(password), salt, RPC_PASSWORD_ITERATIONS, 32, sha256.New)
+	return hex.EncodeToString(append(salt, key...))
+}
+
+func checkRPCServerPassword(password string, passwordHash string) bool {
+	data, err := hex.DecodeString(passwordHash)
+	if err != nil || len(data) <= RPC_PASSWORD_SALT_SIZE {
+		return false
+	}
+
+	hash := hashRPCServerPassword(password, data[:RPC_PASSWORD_SALT_SIZE])
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(passwordHash)) == 1
+}
+
+// SetRPCServerPassword stores a salted hash of the password in the wallet settings (the settings must be saved afterwards).
+func (w *Wallet) SetRPCServerPassword(password string) error {
+	salt := make([]byte, RPC_PASSWORD_SALT_SIZE)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return err
+	}
+
+	w.Settings.RPCServerPasswordHash = hashRPCServerPassword(password, salt)
+	return nil
+}
+
+func (w *Wallet) OpenRPCServer() error {
+	if w.ServerRPC != nil {
+		return fmt.Errorf("rpc server is already running")
+	}
+
+	addr := w.Settings.RPCServerAddr
+	if addr == "" {
+		addr = DefaultRPCServerAddr()
+	}
+
+	// the server can send funds without confirmation so we never start it without credentials
+	if w.Settings.RPCServerUsername == "" || w.Settings.RPCServerPasswordHash == "" {
+		return fmt.Errorf("rpc server username and password are required")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &RPCServer{
+		Addr:         listener.Addr().String(),
+		wallet:       w,
+		username:     w.Settings.RPCServerUsername,
+		passwordHash: w.Settings.RPCServerPasswordHash,
+	}
+
+	server.bridge = jhttp.NewBridge(server.handlers(), nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json_rpc", server.handleJSONRPC)
+	server.srv = &http.Server{Handler: mux}
+
+	go server.srv.Serve(listener)
+	w.ServerRPC = server
+	return nil
+}
+
+func (w *Wallet) CloseRPCServer() {
+	if w.ServerRPC != nil {
+		w.ServerRPC.srv.Shutdown(context.Background())
+		w.ServerRPC.bridge.Close()
+		w.ServerRPC = nil
+	}
+}
+
+func (s *RPCServer) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(username), []byte(s.username)) != 1 ||
+		!checkRPCServerPassword(password, s.passwordHash) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="G45W"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, "Authorization Required")
+		return
+	}
+
+	s.bridge.ServeHTTP(w, r)
+}
+
+func (s *RPCServer) handlers() handler.Map {
+	return handler.Map{
+		"GetAddress":                    handler.New(s.getAddress),
+		"getaddress":                    handler.New(s.getAddress),
+		"GetHeight":                     handler.New(s.getHeight),
+		"getheight":                     handler.New(s.getHeight),
+		"GetBalance":                    handler.New(s.getBalance),
+		"getbalance":                    handler.New(s.getBalance),
+		"GetTransfers":                  handler.New(s.getTransfers),
+		"get_transfers":                 handler.New(s.getTransfers),
+		"Transfer":                      handler.New(s.transfer),
+		"transfer":                      handler.New(s.transfer),
+		"scinvoke":                      handler.New(s.scInvoke),
+		"G45W.GetTokenFolders":          handler.New(s.getTokenFolders),
+		"G45W.GetTokens":                handler.New(s.getTokens),
+		"G45W.GetContacts":              handler.New(s.getContacts),
+		"G45W.StoreContact":             handler.New(s.storeContact),
+		"G45W.DelContact":               handler.New(s.delContact),
+		"G45W.GetOutgoingTxs":           handler.New(s.getOutgoingTxs),
+		"G45W.GetOutgoingTx":            handler.New(s.getOutgoingTx),
+		"G45W.DelOutgoingTx":            handler.New(s.delOutgoingTx),
+		"G45W.UpdatePendingOutgoingTxs": handler.New(s.updatePendingOutgoingTxs),
+	}
+}
+
+func (s *RPCServer) getAddress(ctx context.Context) (rpc.GetAddress_Result, error) {
+	return rpc.GetAddress_Result{
+		Address: s.wallet.Memory.GetAddress().String(),
+	}, nil
+}
+
+func (s *RPCServer) getHeight(ctx context.Context) (rpc.GetHeight_Result, error) {
+	return rpc.GetHeight_Result{
+		Height: s.wallet.Memory.Get_Height(),
+	}, nil
+}
+
+func (s *RPCServer) getBalance(ctx context.Context, p rpc.GetBalance_Params) (result rpc.GetBalance_Result, err error) {
+	err = s.wallet.Memory.Sync_Wallet_Token(p.SCID)
+	if err != nil {
+		return
+	}
+
+	mature, locked := s.wallet.Memory.Get_Balance_scid(p.SCID)
+	result.Balance = mature + locked
+	result.Unlocked_Balance = mature
+	return
+}
+
+type RPCGetTransfersParams struct {
+	SCID       string  `json:"scid"`
+	In         *bool   `json:"in"`
+	Out        *bool   `json:"out"`
+	Coinbase   *bool   `json:"coinbase"`
+	Sender     string  `json:"sender"`
+	Receiver   string  `json:"receiver"`
+	MinAmount  *uint64 `json:"min_amount"`
+	MinBurn    *uint64 `json:"min_burn"`
+	TXID       string  `json:"txid"`
+	BlockHash  string  `json:"blockhash"`
+	SCCallSCID string  `json:"sc_call_scid"`
+	Entrypoint string  `json:"entrypoint"`
+	From       *int64  `json:"from"` // unix timestamp, inclusive
+	To         *int64  `json:"to"`   // unix timestamp, exclusive
+	Offset     *int64  `json:"offset"`
+	Limit      *int64  `json:"limit"`
+}
+
+type RPCGetTransfersResult struct {
+	Entries []Entry `json:"entries"`
+	Total   int     `json:"total"`
+}
+
+func (s *RPCServer) getTransfers(ctx context.Context, p RPCGetTransfersParams) (result RPCGetTransfersResult, err error) {
+	var params GetEntriesParams
+
+	if p.In != nil {
+		params.In = sql.NullBool{Bool: *p.In, Valid: true}
+	}
+
+	if p.Out != nil {
+		params.Out = sql.NullBool{Bool: *p.Out, Valid: true}
+	}
+
+	if p.Coinbase != nil {
+		params.Coinbase = sql.NullBool{Bool: *p.Coinbase, Valid: true}
+	}
+
+	if p.Sender != "" {
+		params.Sender = sql.NullString{String: p.Sender, Valid: true}
+	}
+
+	if p.Receiver != "" {
+		params.Receiver = sql.NullString{String: p.Receiver, Valid: true}
+	}
+
+	if p.MinAmount != nil {
+		params.AmountGreaterOrEqualThan = sql.NullInt64{Int64: int64(*p.MinAmount), Valid: true}
+	}
+
+	if p.MinBurn != nil {
+		params.BurnGreaterOrEqualThan = sql.NullInt64{Int64: int64(*p.MinBurn), Valid: true}
+	}
+
+	if p.TXID != "" {
+		params.TXID = sql.NullString{String: p.TXID, Valid: true}
+	}
+
+	if p.BlockHash != "" {
+		params.BlockHash = sql.NullString{String: p.BlockHash, Valid: true}
+	}
+
+	if p.SCCallSCID != "" || p.Entrypoint != "" {
+		params.SC_CALL = &SCCallParams{
+			SCID:       sql.NullString{String: p.SCCallSCID, Valid: p.SCCallSCID != ""},
+			Entrypoint: sql.NullString{String: p.Entrypoint, Valid: p.Entrypoint != ""},
+		}
+	}
+
+	if p.From != nil {
+		params.TimeFrom = sql.NullTime{Time: time.Unix(*p.From, 0), Valid: true}
+	}
+
+	if p.To != nil {
+		params.TimeTo = sql.NullTime{Time: time.Unix(*p.To, 0), Valid: true}
+	}
+
+	if p.Offset != nil {
+		params.Offset = sql.NullInt64{Int64: *p.Offset, Valid: true}
+	}
+
+	if p.Limit != nil {
+		params.Limit = sql.NullInt64{Int64: *p.Limit, Valid: true}
+	}
+
+	var scId *crypto.Hash
+	if p.SCID != "" {
+		hash := crypto.HashHexToHash(p.SCID)
+		scId = &hash
+	}
+
+	result.Entries, err = s.wallet.GetEntries(scId, params)
+	if err != nil {
+		return
+	}
+
+	result.Total, err = s.wallet.GetEntriesCount(scId, params)
+	return
+}
+
+func (s *RPCServer) sendTransfer(p rpc.Transfer_Params) (result rpc.Transfer_Result, err error) {
+	// the ui is not involved so the wallet restrictions are checked here
+	if s.wallet.IsLocked() {
+		err = ErrReadOnly
+		return
+	}
+
+	p, err = FormatTransfer(p)
+	if err != nil {
+		return
+	}
+
+	if p.Ringsize == 0 {
+		p.Ringsize = uint64(settings.App.SendRingSize)
+	}
+
+	txFees, gasFees, err := s.wallet.EstimateFees(&p)
+	if err != nil {
+		return
+	}
+
+	// a broadcasted tx is never reported as failed or the client could send it again
+	tx, err := s.wallet.BuildAndSendTx(p, txFees, gasFees, "")
+	if tx == nil {
+		return
+	}
+
+	err = nil
+	result.TXID = tx.GetHash().String()
+	return
+}
+
+func (s *RPCServer) transfer(ctx context.Context, p rpc.Transfer_Params) (rpc.Transfer_Result, error) {
+	return s.sendTransfer(p)
+}
+
+func (s *RPCServer) scInvoke(ctx context.Context, p rpc.SC_Invoke_Params) (result rpc.Transfer_Result, err error) {
+	if p.SC_ID == "" {
+		err = fmt.Errorf("SCID cannot be empty")
+		return
+	}
+
+	randomAddr := ""
+	if p.SC_DERO_Deposit > 0 {
+		randomAddr, err = s.wallet.GetRandomAddress(crypto.ZEROHASH)
+		if err != nil {
+			return
+		}
+	}
+
+	return s.sendTransfer(FormatSCInvoke(p, randomAddr))
+}
+
+type RPCTokenFolder struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentId *int64 `json:"parent_id"`
+}
+
+type RPCToken struct {
+	ID           int64  `json:"id"`
+	SCID         string `json:"scid"`
+	Name         string `json:"name"`
+	Symbol       string `json:"symbol"`
+	Decimals     int64  `json:"decimals"`
+	StandardType string `json:"standard_type"`
+	MaxSupply    *int64 `json:"max_supply"`
+	FolderId     *int64 `json:"folder_id"`
+	IsFavorite   bool   `json:"is_favorite"`
+	ImageUrl     string `json:"image_url"`
+}
+
+func nullInt64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Int64
+}
+
+func rpcTokens(tokens []Token) []RPCToken {
+	rpcTokens := make([]RPCToken, 0)
+	for _, token := range tokens {
+		rpcTokens = append(rpcTokens, RPCToken{
+			ID:           token.ID,
+			SCID:         token.SCID,
+			Name:         token.Name,
+			Symbol:       token.Symbol.String,
+			Decimals:     token.Decimals,
+			StandardType: string(token.StandardType),
+			MaxSupply:    nullInt64Ptr(token.MaxSupply),
+			FolderId:     nullInt64Ptr(token.FolderId),
+			IsFavorite:   token.IsFavorite,
+			ImageUrl:     token.ImageUrl.String,
+		})
+	}
+
+	return rpcTokens
+}
+
+type RPCGetTokenFoldersParams struct {
+	FolderId *int64 `json:"folder_id"` // nil is the root folder
+}
+
+type RPCGetTokenFoldersResult struct {
+	Folders []RPCTokenFolder `json:"folders"`
+	Tokens  []RPCToken       `json:"tokens"`
+}
+
+func (s *RPCServer) getTokenFolders(ctx context.Context, p RPCGetTokenFoldersParams) (result RPCGetTokenFoldersResult, err error) {
+	var folderId sql.NullInt64
+	if p.FolderId != nil {
+		folderId = sql.NullInt64{Int64: *p.FolderId, Valid: true}
+	}
+
+	folders, err := s.wallet.GetTokenFolderFolders(folderId)
+	if err != nil {
+		return
+	}
+
+	tokens, err := s.wallet.GetTokens(GetTokensParams{FolderId: &folderId})
+	if err != nil {
+		return
+	}
+
+	result.Folders = make([]RPCTokenFolder, 0)
+	for _, folder := range folders {
+		result.Folders = append(result.Folders, RPCTokenFolder{
+			ID:       folder.ID,
+			Name:     folder.Name,
+			ParentId: nullInt64Ptr(folder.ParentId),
+		})
+	}
+
+	result.Tokens = rpcTokens(tokens)
+	return
+}
+
+type RPCGetTokensParams struct {
+	IsFavorite *bool `json:"is_favorite"`
+	IsNFT      *bool `json:"is_nft"`
+}
+
+type RPCGetTokensResult struct {
+	Tokens []RPCToken `json:"tokens"`
+}
+
+func (s *RPCServer) getTokens(ctx context.Context, p RPCGetTokensParams) (result RPCGetTokensResult, err error) {
+	var params GetTokensParams
+
+	if p.IsFavorite != nil {
+		params.IsFavorite = sql.NullBool{Bool: *p.IsFavorite, Valid: true}
+	}
+
+	if p.IsNFT != nil {
+		params.IsNFT = sql.NullBool{Bool: *p.IsNFT, Valid: true}
+	}
+
+	tokens, err := s.wallet.GetTokens(params)
+	if err != nil {
+		return
+	}
+
+	result.Tokens = rpcTokens(tokens)
+	return
+}
+
+type RPCContact struct {
+	Name      string `json:"name"`
+	Addr      string `json:"addr"`
+	Note      string `json:"note"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type RPCGetContactsResult struct {
+	Contacts []RPCContact `json:"contacts"`
+}
+
+func (s *RPCServer) getContacts(ctx context.Context) (result RPCGetContactsResult, err error) {
+	contacts, err := s.wallet.GetContacts(GetContactsParams{})
+	if err != nil {
+		return
+	}
+
+	result.Contacts = make([]RPCContact, 0)
+	for _, contact := range contacts {
+		result.Contacts = append(result.Contacts, RPCContact{
+			Name:      contact.Name,
+			Addr:      contact.Addr,
+			Note:      contact.Note,
+			Timestamp: contact.Timestamp,
+		})
+	}
+
+	return
+}
+
+func (s *RPCServer) storeContact(ctx context.Context, p RPCContact) (bool, error) {
+	if p.Name == "" {
+		return false, fmt.Errorf("enter name")
+	}
+
+	_, err := rpc.NewAddress(p.Addr)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.wallet.StoreContact(Contact{
+		Name: p.Name,
+		Addr: p.Addr,
+		Note: p.Note,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type RPCAddrParams struct {
+	Addr string `json:"addr"`
+}
+
+func (s *RPCServer) delContact(ctx context.Context, p RPCAddrParams) (bool, error) {
+	err := s.wallet.DelContact(p.Addr)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type RPCOutgoingTx struct {
+	TXID        string `json:"txid"`
+	Status      string `json:"status"`
+	TxType      string `json:"tx_type"`
+	HeightBuilt int64  `json:"height_built"`
+	BlockHeight int64  `json:"block_height"`
+	Timestamp   int64  `json:"timestamp"`
+	Description string `json:"description"`
+}
+
+func rpcOutgoingTx(outgoingTx OutgoingTx) RPCOutgoingTx {
+	return RPCOutgoingTx{
+		TXID:        outgoingTx.TxId,
+		Status:      outgoingTx.Status.String,
+		TxType:      transaction.TransactionType(outgoingTx.TxType.Int32).String(),
+		HeightBuilt: outgoingTx.HeightBuilt.Int64,
+		BlockHeight: outgoingTx.BlockHeight.Int64,
+		Timestamp:   outgoingTx.Timestamp.Int64,
+		Description: outgoingTx.Description.String,
+	}
+}
+
+type RPCGetOutgoingTxsParams struct {
+	Limit uint64 `json:"limit"`
+}
+
+type RPCGetOutgoingTxsResult struct {
+	OutgoingTxs []RPCOutgoingTx `json:"outgoing_txs"`
+}
+
+func (s *RPCServer) getOutgoingTxs(ctx context.Context, p RPCGetOutgoingTxsParams) (result RPCGetOutgoingTxsResult, err error) {
+	outgoingTxs, err := s.wallet.GetOutgoingTxs(GetOutgoingTxsParams{
+		Descending: true,
+		OrderBy:    "timestamp",
+		Limit:      p.Limit,
+	})
+	if err != nil {
+		return
+	}
+
+	result.OutgoingTxs = make([]RPCOutgoingTx, 0)
+	for _, outgoingTx := range outgoingTxs {
+		result.OutgoingTxs = append(result.OutgoingTxs, rpcOutgoingTx(outgoingTx))
+	}
+
+	return
+}
+
+type RPCTxIdParams struct {
+	TXID string `json:"txid"`
+}
+
+func (s *RPCServer) getOutgoingTx(ctx context.Context, p RPCTxIdParams) (result RPCOutgoingTx, err error) {
+	outgoingTx, err := s.wallet.GetOutgoingTx(p.TXID)
+	if err != nil {
+		return
+	}
+
+	if outgoingTx == nil {
+		err = fmt.Errorf("outgoing tx not found")
+		return
+	}
+
+	result = rpcOutgoingTx(*outgoingTx)
+	return
+}
+
+func (s *RPCServer) delOutgoingTx(ctx context.Context, p RPCTxIdParams) (bool, error) {
+	err := s.wallet.DelOutgoingTx(p.TXID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type RPCUpdatePendingOutgoingTxsResult struct {
+	Updated int `json:"updated"`
+}
+
+// check pending txs now instead of waiting for the next update loop
+func (s *RPCServer) updatePendingOutgoingTxs(ctx context.Context) (result RPCUpdatePendingOutgoingTxsResult, err error) {
+	result.Updated, err = s.wallet.UpdatePendingOutgoingTxs()
+	return
+}
@@ -7,8 +7,14 @@ import (
 )
 
 type Settings struct {
-	AskToStoreDEXTokens               bool `json:"ask_to_store_dex_tokens"`
-	NotifyXSWDMobileBackgroundService bool `json:"notify_xswd_mobile_background_service"`
+	AskToStoreDEXTokens               bool   `json:"ask_to_store_dex_tokens"`
+	NotifyXSWDMobileBackgroundService bool   `json:"notify_xswd_mobile_background_service"`
+	RPCServerAddr                     string `json:"rpc_server_addr"`
+	RPCServerUsername                 string `json:"rpc_server_username"`
+	RPCServerPasswordHash             string `json:"rpc_server_password_hash"`
+
+	// plaintext password of older versions, replaced by the hash when loading the settings
+	LegacyRPCServerPassword string `json:"rpc_server_password,omitempty"`
 }
 
 func (w *Wallet) settingsPath() string {
@@ -21,6 +27,7 @@ func (w *Wallet) LoadSettings() error {
 	w.Settings = Settings{
 		AskToStoreDEXTokens:               true,
 		NotifyXSWDMobileBackgroundService: true,
+		RPCServerAddr:                     DefaultRPCServerAddr(),
 	}
 
 	_, err := os.Stat(settingsPath)
@@ -36,6 +43,16 @@ func (w *Wallet) LoadSettings() error {
 		}
 	}
 
+	if w.Settings.LegacyRPCServerPassword != "" {
+		err = w.SetRPCServerPassword(w.Settings.LegacyRPCServerPassword)
+		if err != nil {
+			return err
+		}
+
+		w.Settings.LegacyRPCServerPassword = ""
+		return w.SaveSettings()
+	}
+
 	return nil
 }
 
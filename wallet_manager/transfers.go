@@ -2,17 +2,18 @@ package wallet_manager
 
 import (
 	"database/sql"
-	"math"
-	"sort"
-	"sync"
+	"encoding/json"
+	"fmt"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
+	"github.com/g45t345rt/g45w/app_db/schema_version"
 )
 
 type Entry struct {
 	rpc.Entry
-	SCID crypto.Hash
+	SCID crypto.Hash `json:"scid"`
 }
 
 type SCCallParams struct {
@@ -20,6 +21,7 @@ type SCCallParams struct {
 	Entrypoint sql.NullString
 }
 
+// All the valid params must match (AND)
 type GetEntriesParams struct {
 	In                       sql.NullBool
 	Out                      sql.NullBool
@@ -31,138 +33,400 @@ type GetEntriesParams struct {
 	TXID                     sql.NullString
 	BlockHash                sql.NullString
 	SC_CALL                  *SCCallParams
+	TimeFrom                 sql.NullTime   // inclusive
+	TimeTo                   sql.NullTime   // exclusive
+	Search                   sql.NullString // txid or annotation note, category and tags
 	Offset                   sql.NullInt64
 	Limit                    sql.NullInt64
 }
 
-func filterEntries(allEntries []rpc.Entry, start, end int, entrySCID crypto.Hash, params GetEntriesParams, entryChan chan<- Entry, wg *sync.WaitGroup) {
-	defer wg.Done()
+// The entries of the wallet (EntriesNative) are mirrored in the entries table after each sync.
+// The wallet entries of a scid are sorted by topoheight and a rescan/reorg truncates the slice
+// so we only insert the new entries unless the last indexed entry changed.
 
-	for i := start; i < end; i++ {
-		e := allEntries[i]
-		add := true
+type entryKey struct {
+	TopoHeight     int64
+	TransactionPos int
+	Pos            int
+}
 
-		if params.Coinbase.Valid {
-			add = e.Coinbase == params.Coinbase.Bool
-		}
+type entriesIndexState struct {
+	count int
+	last  entryKey
+}
 
-		if params.In.Valid {
-			add = e.Incoming == params.In.Bool
-		}
+func getEntryKey(e rpc.Entry) entryKey {
+	return entryKey{TopoHeight: e.TopoHeight, TransactionPos: e.TransactionPos, Pos: e.Pos}
+}
+
+func initTableEntries(db *sql.DB) error {
+	version, err := schema_version.GetVersion(db, "entries")
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS entries (
+				id INTEGER PRIMARY KEY,
+				scid VARCHAR NOT NULL,
+				topo_height BIGINT NOT NULL,
+				height BIGINT NOT NULL,
+				tx_pos INT NOT NULL,
+				pos INT NOT NULL,
+				block_hash VARCHAR NOT NULL,
+				txid VARCHAR NOT NULL,
+				coinbase BOOL NOT NULL,
+				incoming BOOL NOT NULL,
+				sender VARCHAR NOT NULL,
+				destination VARCHAR NOT NULL,
+				amount BIGINT NOT NULL,
+				burn BIGINT NOT NULL,
+				fees BIGINT NOT NULL,
+				timestamp BIGINT NOT NULL,
+				sc_call_scid VARCHAR NOT NULL,
+				sc_call_entrypoint VARCHAR NOT NULL,
+				data VARCHAR NOT NULL
+			);
 
-		if params.Out.Valid {
-			add = (!e.Incoming && !e.Coinbase) == params.Out.Bool
+			CREATE UNIQUE INDEX IF NOT EXISTS entries_key ON entries (scid, topo_height, tx_pos, pos);
+			CREATE INDEX IF NOT EXISTS entries_scid_timestamp ON entries (scid, timestamp);
+			CREATE INDEX IF NOT EXISTS entries_txid ON entries (txid);
+		`)
+		if err != nil {
+			return err
 		}
 
-		if params.Sender.Valid {
-			add = e.Sender == params.Sender.String
+		version = 1
+		err = schema_version.StoreVersion(db, "entries", version)
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+func (w *Wallet) loadEntriesIndex() error {
+	rows, err := w.DB.Query(`
+		SELECT scid, COUNT(*) FROM entries
+		GROUP BY scid;
+	`)
+	if err != nil {
+		return err
+	}
 
-		if params.Receiver.Valid {
-			add = e.Destination == params.Receiver.String
+	index := make(map[crypto.Hash]entriesIndexState)
+	for rows.Next() {
+		var scId string
+		var state entriesIndexState
+		err = rows.Scan(&scId, &state.count)
+		if err != nil {
+			rows.Close()
+			return err
 		}
 
-		if params.AmountGreaterOrEqualThan.Valid {
-			add = e.Amount >= uint64(params.AmountGreaterOrEqualThan.Int64)
+		index[crypto.HashHexToHash(scId)] = state
+	}
+	rows.Close()
+
+	for scId, state := range index {
+		row := w.DB.QueryRow(`
+			SELECT topo_height, tx_pos, pos FROM entries
+			WHERE scid = ?
+			ORDER BY topo_height DESC, tx_pos DESC, pos DESC
+			LIMIT 1;
+		`, scId.String())
+		err = row.Scan(&state.last.TopoHeight, &state.last.TransactionPos, &state.last.Pos)
+		if err != nil {
+			return err
 		}
 
-		if params.BurnGreaterOrEqualThan.Valid {
-			add = e.Burn >= uint64(params.BurnGreaterOrEqualThan.Int64)
+		index[scId] = state
+	}
+
+	w.entriesIndex = index
+	return nil
+}
+
+type entriesChange struct {
+	scId    crypto.Hash
+	reset   bool
+	entries []rpc.Entry
+}
+
+func (w *Wallet) setEntriesSyncError(err error) {
+	w.entriesSyncErrLock.Lock()
+	defer w.entriesSyncErrLock.Unlock()
+	w.entriesSyncErr = err
+}
+
+// EntriesSyncError returns the error of the last entries sync, the transaction history is stale until it succeeds
+func (w *Wallet) EntriesSyncError() error {
+	w.entriesSyncErrLock.RLock()
+	defer w.entriesSyncErrLock.RUnlock()
+	return w.entriesSyncErr
+}
+
+// SyncEntries inserts the new wallet entries in the entries table.
+// It runs after every wallet sync (see sync_dero_loop) so the read functions only query the db.
+func (w *Wallet) SyncEntries() error {
+	w.entriesLock.Lock()
+	defer w.entriesLock.Unlock()
+
+	if w.entriesIndex == nil {
+		err := w.loadEntriesIndex()
+		if err != nil {
+			return err
 		}
+	}
+
+	var changes []entriesChange
+
+	w.Memory.Lock()
+	account := w.Memory.GetAccount()
+	for scId, entries := range account.EntriesNative {
+		state := w.entriesIndex[scId]
+		appendOnly := state.count <= len(entries) &&
+			(state.count == 0 || getEntryKey(entries[state.count-1]) == state.last)
 
-		if params.TXID.Valid {
-			add = e.TXID == params.TXID.String
+		// copy the entries, the wallet can overwrite the underlying array while syncing
+		if appendOnly {
+			if state.count < len(entries) {
+				newEntries := make([]rpc.Entry, len(entries)-state.count)
+				copy(newEntries, entries[state.count:])
+				changes = append(changes, entriesChange{scId: scId, entries: newEntries})
+			}
+		} else {
+			newEntries := make([]rpc.Entry, len(entries))
+			copy(newEntries, entries)
+			changes = append(changes, entriesChange{scId: scId, reset: true, entries: newEntries})
 		}
+	}
 
-		if params.BlockHash.Valid {
-			add = e.BlockHash == params.BlockHash.String
+	// the wallet was cleaned
+	for scId := range w.entriesIndex {
+		if _, ok := account.EntriesNative[scId]; !ok {
+			changes = append(changes, entriesChange{scId: scId, reset: true})
 		}
+	}
+	w.Memory.Unlock()
+
+	if len(changes) == 0 {
+		return nil
+	}
 
-		if params.SC_CALL != nil {
-			add = false
-			for _, arg := range e.SCDATA {
-				if params.SC_CALL.SCID.Valid && arg.Name == "SC_ID" {
-					scId, ok := arg.Value.(string)
-					if ok && scId == params.SC_CALL.SCID.String {
-						add = true
-					}
-				}
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	newIndex := make(map[crypto.Hash]entriesIndexState)
+	for scId, state := range w.entriesIndex {
+		newIndex[scId] = state
+	}
+
+	for _, change := range changes {
+		state := newIndex[change.scId]
+		if change.reset {
+			_, err = tx.Exec(`DELETE FROM entries WHERE scid = ?;`, change.scId.String())
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			state = entriesIndexState{}
+		}
 
-				if params.SC_CALL.Entrypoint.Valid && arg.Name == "entrypoint" {
-					entrypoint, ok := arg.Value.(string)
-					if ok && entrypoint == params.SC_CALL.Entrypoint.String {
-						add = true
-						break
-					}
-				}
+		for _, e := range change.entries {
+			err = insertEntry(tx, change.scId, e)
+			if err != nil {
+				tx.Rollback()
+				return err
 			}
+
+			state.count++
+			state.last = getEntryKey(e)
 		}
 
-		if add {
-			entry := Entry{Entry: e, SCID: entrySCID}
-			entryChan <- entry
+		if state.count == 0 {
+			delete(newIndex, change.scId)
+		} else {
+			newIndex[change.scId] = state
 		}
 	}
-}
 
-func (w *Wallet) GetEntries(SCID *crypto.Hash, params GetEntriesParams) []Entry {
-	w.Memory.Lock()
-	defer w.Memory.Unlock()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
 
-	account := w.Memory.GetAccount()
-	var wg sync.WaitGroup
-	entryChan := make(chan Entry)
+	w.entriesIndex = newIndex
+	return nil
+}
+
+func insertEntry(tx *sql.Tx, scId crypto.Hash, e rpc.Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
 
-	var filteredEntries []Entry
-	done := make(chan bool)
-	go func() {
-		for e := range entryChan {
-			filteredEntries = append(filteredEntries, e)
+	scCallSCID := ""
+	scCallEntrypoint := ""
+	for _, arg := range e.SCDATA {
+		switch arg.Name {
+		case "SC_ID":
+			if value, ok := arg.Value.(string); ok {
+				scCallSCID = value
+			}
+		case "entrypoint":
+			if value, ok := arg.Value.(string); ok {
+				scCallEntrypoint = value
+			}
 		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO entries (scid,topo_height,height,tx_pos,pos,block_hash,txid,coinbase,incoming,
+			sender,destination,amount,burn,fees,timestamp,sc_call_scid,sc_call_entrypoint,data)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+	`, scId.String(), e.TopoHeight, e.Height, e.TransactionPos, e.Pos, e.BlockHash, e.TXID, e.Coinbase, e.Incoming,
+		e.Sender, e.Destination, int64(e.Amount), int64(e.Burn), int64(e.Fees), e.Time.Unix(),
+		scCallSCID, scCallEntrypoint, string(data))
+	return err
+}
+
+func filterEntriesQuery(query sq.SelectBuilder, SCID *crypto.Hash, params GetEntriesParams) sq.SelectBuilder {
+	if SCID != nil {
+		query = query.Where(sq.Eq{"scid": SCID.String()})
+	}
+
+	if params.Coinbase.Valid {
+		query = query.Where(sq.Eq{"coinbase": params.Coinbase.Bool})
+	}
 
-		done <- true
-	}()
+	if params.In.Valid {
+		query = query.Where(sq.Eq{"incoming": params.In.Bool})
+	}
 
-	workSize := 100
-	for entrySCID, entries := range account.EntriesNative {
-		if SCID != nil && entrySCID != *SCID {
-			continue
+	if params.Out.Valid {
+		if params.Out.Bool {
+			query = query.Where(sq.Eq{"incoming": false, "coinbase": false})
+		} else {
+			query = query.Where(sq.Or{sq.Eq{"incoming": true}, sq.Eq{"coinbase": true}})
 		}
+	}
 
-		workers := int(math.Max(1, float64(len(entries)/workSize)))
-		for i := 0; i < workers; i++ {
-			start := i * workSize
-			end := (i + 1) * workSize
-			if i == workers-1 {
-				end = len(entries)
-			}
+	if params.Sender.Valid {
+		query = query.Where(sq.Eq{"sender": params.Sender.String})
+	}
+
+	if params.Receiver.Valid {
+		query = query.Where(sq.Eq{"destination": params.Receiver.String})
+	}
+
+	if params.AmountGreaterOrEqualThan.Valid {
+		query = query.Where(sq.GtOrEq{"amount": params.AmountGreaterOrEqualThan.Int64})
+	}
+
+	if params.BurnGreaterOrEqualThan.Valid {
+		query = query.Where(sq.GtOrEq{"burn": params.BurnGreaterOrEqualThan.Int64})
+	}
+
+	if params.TXID.Valid {
+		query = query.Where(sq.Eq{"txid": params.TXID.String})
+	}
 
-			wg.Add(1)
-			go filterEntries(entries, start, end, entrySCID, params, entryChan, &wg)
+	if params.BlockHash.Valid {
+		query = query.Where(sq.Eq{"block_hash": params.BlockHash.String})
+	}
+
+	if params.SC_CALL != nil {
+		if params.SC_CALL.SCID.Valid {
+			query = query.Where(sq.Eq{"sc_call_scid": params.SC_CALL.SCID.String})
+		}
+
+		if params.SC_CALL.Entrypoint.Valid {
+			query = query.Where(sq.Eq{"sc_call_entrypoint": params.SC_CALL.Entrypoint.String})
 		}
 	}
 
-	wg.Wait()
-	close(entryChan)
-	<-done
+	if params.TimeFrom.Valid {
+		query = query.Where(sq.GtOrEq{"timestamp": params.TimeFrom.Time.Unix()})
+	}
+
+	if params.TimeTo.Valid {
+		query = query.Where(sq.Lt{"timestamp": params.TimeTo.Time.Unix()})
+	}
+
+	if params.Search.Valid {
+		search := fmt.Sprintf("%%%s%%", params.Search.String)
+		query = query.Where(sq.Or{
+			sq.Like{"txid": search},
+			sq.Expr(`EXISTS (
+				SELECT 1 FROM tx_annotations a
+				WHERE a.txid = entries.txid AND a.scid = entries.scid
+				AND (a.note LIKE ? OR a.category LIKE ? OR a.tags LIKE ?)
+			)`, search, search, search),
+		})
+	}
+
+	return query
+}
+
+// GetEntries returns the entries of the wallet from the most recent (all tokens if SCID is nil)
+func (w *Wallet) GetEntries(SCID *crypto.Hash, params GetEntriesParams) ([]Entry, error) {
+	query := sq.Select("scid", "data").From("entries").
+		OrderBy("timestamp DESC", "topo_height DESC", "tx_pos DESC", "pos DESC")
+	query = filterEntriesQuery(query, SCID, params)
 
-	sort.Slice(filteredEntries, func(a, b int) bool {
-		return filteredEntries[a].Time.Unix() > filteredEntries[b].Time.Unix()
-	})
+	if params.Limit.Valid {
+		query = query.Limit(uint64(params.Limit.Int64))
+	}
 
 	if params.Offset.Valid {
-		offset := params.Offset.Int64
-		if len(filteredEntries) > int(offset) {
-			filteredEntries = filteredEntries[offset:]
+		// sqlite requires a limit with an offset
+		if !params.Limit.Valid {
+			query = query.Limit(uint64(1<<63 - 1))
 		}
+
+		query = query.Offset(uint64(params.Offset.Int64))
 	}
 
-	if params.Limit.Valid {
-		limit := params.Limit.Int64
-		if len(filteredEntries) > int(limit) {
-			filteredEntries = filteredEntries[:limit]
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var scId string
+		var data string
+		err = rows.Scan(&scId, &data)
+		if err != nil {
+			return nil, err
 		}
+
+		var entry Entry
+		err = json.Unmarshal([]byte(data), &entry.Entry)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.SCID = crypto.HashHexToHash(scId)
+		entries = append(entries, entry)
 	}
 
-	return filteredEntries
+	return entries, rows.Err()
+}
+
+// GetEntriesCount returns the number of entries matching the params (Offset and Limit are ignored)
+func (w *Wallet) GetEntriesCount(SCID *crypto.Hash, params GetEntriesParams) (int, error) {
+	query := sq.Select("COUNT(*)").From("entries")
+	query = filterEntriesQuery(query, SCID, params)
+
+	var count int
+	err := query.RunWith(w.DB).QueryRow().Scan(&count)
+	return count, err
 }
@@ -0,0 +1,161 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/g45t345rt/g45w/app_db/schema_version"
+)
+
+// Notes, category and tags the user can attach to any entry (incoming or outgoing) of a tx.
+// A tx can transfer multiple tokens so the annotation is keyed by txid and scid.
+type TxAnnotation struct {
+	TXID      string
+	SCID      string
+	Note      string
+	Category  string
+	Tags      string // comma separated
+	Timestamp int64
+}
+
+func (a TxAnnotation) IsEmpty() bool {
+	return a.Note == "" && a.Category == "" && a.Tags == ""
+}
+
+func (a TxAnnotation) GetTags() []string {
+	tags := []string{}
+	for _, tag := range strings.Split(a.Tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func initTableTxAnnotations(db *sql.DB) error {
+	version, err := schema_version.GetVersion(db, "tx_annotations")
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS tx_annotations (
+				txid VARCHAR NOT NULL,
+				scid VARCHAR NOT NULL,
+				note VARCHAR NOT NULL,
+				category VARCHAR NOT NULL,
+				tags VARCHAR NOT NULL,
+				timestamp BIGINT NOT NULL,
+				PRIMARY KEY (txid, scid)
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 1
+		err = schema_version.StoreVersion(db, "tx_annotations", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TxAnnotationKey is the key of the map returned by GetTxAnnotations
+func TxAnnotationKey(txId string, scId string) string {
+	return fmt.Sprintf("%s:%s", txId, scId)
+}
+
+func (w *Wallet) GetTxAnnotations() (map[string]TxAnnotation, error) {
+	query := sq.Select("*").From("tx_annotations")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := make(map[string]TxAnnotation)
+	for rows.Next() {
+		var annotation TxAnnotation
+		err = rows.Scan(
+			&annotation.TXID,
+			&annotation.SCID,
+			&annotation.Note,
+			&annotation.Category,
+			&annotation.Tags,
+			&annotation.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		annotations[TxAnnotationKey(annotation.TXID, annotation.SCID)] = annotation
+	}
+
+	return annotations, rows.Err()
+}
+
+// GetTxAnnotation returns an empty annotation if the entry was never annotated
+func (w *Wallet) GetTxAnnotation(txId string, scId string) (TxAnnotation, error) {
+	query := sq.Select("*").From("tx_annotations").
+		Where(sq.Eq{"txid": txId, "scid": scId})
+
+	annotation := TxAnnotation{TXID: txId, SCID: scId}
+	row := query.RunWith(w.DB).QueryRow()
+	err := row.Scan(
+		&annotation.TXID,
+		&annotation.SCID,
+		&annotation.Note,
+		&annotation.Category,
+		&annotation.Tags,
+		&annotation.Timestamp,
+	)
+	if err == sql.ErrNoRows {
+		return annotation, nil
+	}
+
+	return annotation, err
+}
+
+// StoreTxAnnotation inserts or updates the annotation and removes it if all the fields are empty
+func (w *Wallet) StoreTxAnnotation(annotation TxAnnotation) error {
+	if annotation.TXID == "" {
+		return fmt.Errorf("can't annotate an entry without txid")
+	}
+
+	annotation.Note = strings.TrimSpace(annotation.Note)
+	annotation.Category = strings.TrimSpace(annotation.Category)
+	annotation.Tags = strings.Join(annotation.GetTags(), ",")
+
+	if annotation.IsEmpty() {
+		return w.DelTxAnnotation(annotation.TXID, annotation.SCID)
+	}
+
+	_, err := w.DB.Exec(`
+		INSERT INTO tx_annotations (txid,scid,note,category,tags,timestamp)
+		VALUES (?,?,?,?,?,?)
+		ON CONFLICT (txid,scid) DO UPDATE SET
+		note = excluded.note,
+		category = excluded.category,
+		tags = excluded.tags,
+		timestamp = excluded.timestamp;
+	`, annotation.TXID, annotation.SCID, annotation.Note, annotation.Category, annotation.Tags, time.Now().Unix())
+	return err
+}
+
+func (w *Wallet) DelTxAnnotation(txId string, scId string) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM tx_annotations
+		WHERE txid = ? AND scid = ?;
+	`, txId, scId)
+	return err
+}
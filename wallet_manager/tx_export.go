@@ -0,0 +1,359 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+type ExportFormat string
+
+const (
+	EXPORT_FORMAT_CSV    ExportFormat = "csv"
+	EXPORT_FORMAT_KOINLY ExportFormat = "koinly"
+	EXPORT_FORMAT_JSON   ExportFormat = "json"
+)
+
+var ExportFormats = []ExportFormat{EXPORT_FORMAT_CSV, EXPORT_FORMAT_KOINLY, EXPORT_FORMAT_JSON}
+
+func (f ExportFormat) String() string {
+	switch f {
+	case EXPORT_FORMAT_CSV:
+		return "CSV"
+	case EXPORT_FORMAT_KOINLY:
+		return "CSV (Koinly Universal)"
+	case EXPORT_FORMAT_JSON:
+		return "JSON"
+	}
+
+	return string(f)
+}
+
+func (f ExportFormat) FileName() string {
+	switch f {
+	case EXPORT_FORMAT_KOINLY:
+		return "transactions_koinly.csv"
+	case EXPORT_FORMAT_JSON:
+		return "transactions.json"
+	}
+
+	return "transactions.csv"
+}
+
+const (
+	EXPORT_TX_IN       = "in"
+	EXPORT_TX_OUT      = "out"
+	EXPORT_TX_COINBASE = "coinbase"
+	EXPORT_TX_FEE      = "fee"
+)
+
+type ExportTxsParams struct {
+	Format   ExportFormat
+	SCID     *crypto.Hash // nil for all tokens
+	TimeFrom sql.NullTime // inclusive
+	TimeTo   sql.NullTime // exclusive
+	FeeRows  bool         // fees are exported as separate rows instead of a column of the out row
+}
+
+type ExportTx struct {
+	Date             time.Time `json:"date"`
+	Type             string    `json:"type"`
+	TXID             string    `json:"txid"`
+	BlockHeight      uint64    `json:"block_height"`
+	SCID             string    `json:"scid"`
+	Symbol           string    `json:"symbol"`
+	Amount           string    `json:"amount"`
+	AtomicAmount     uint64    `json:"atomic_amount"`
+	Burn             string    `json:"burn"`
+	AtomicBurn       uint64    `json:"atomic_burn"`
+	Fee              string    `json:"fee"`
+	AtomicFee        uint64    `json:"atomic_fee"`
+	FeeSymbol        string    `json:"fee_symbol"`
+	Counterparty     string    `json:"counterparty"`
+	CounterpartyName string    `json:"counterparty_name"`
+	DestinationPort  uint64    `json:"destination_port"`
+	SourcePort       uint64    `json:"source_port"`
+	Comment          string    `json:"comment"`
+	Note             string    `json:"note"`
+	Category         string    `json:"category"`
+	Tags             []string  `json:"tags"`
+
+	decimals int
+}
+
+// formatAtomicAmount shifts the amount without going through a float to keep all the digits
+func formatAtomicAmount(amount uint64, decimals int) string {
+	value := strconv.FormatUint(amount, 10)
+	if decimals <= 0 {
+		return value
+	}
+
+	if len(value) <= decimals {
+		value = strings.Repeat("0", decimals-len(value)+1) + value
+	}
+
+	i := len(value) - decimals
+	return fmt.Sprintf("%s.%s", value[:i], value[i:])
+}
+
+type exportToken struct {
+	symbol   string
+	decimals int
+}
+
+// the tokens of the wallet are used for the decimals and symbols, unknown tokens are exported as atomic units with their scid
+func (w *Wallet) getExportTokens() (map[string]exportToken, error) {
+	tokens, err := w.GetTokens(GetTokensParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	exportTokens := make(map[string]exportToken)
+	dero := DeroToken()
+	exportTokens[dero.SCID] = exportToken{symbol: dero.Symbol.String, decimals: int(dero.Decimals)}
+
+	for _, token := range tokens {
+		symbol := token.Symbol.String
+		if symbol == "" {
+			symbol = token.Name
+		}
+
+		if symbol == "" {
+			symbol = token.SCID
+		}
+
+		exportTokens[token.SCID] = exportToken{symbol: symbol, decimals: int(token.Decimals)}
+	}
+
+	return exportTokens, nil
+}
+
+// GetExportTxs returns the entries of the wallet from the oldest with human amounts, counterparties and annotations
+func (w *Wallet) GetExportTxs(params ExportTxsParams) ([]ExportTx, error) {
+	entries, err := w.GetEntries(params.SCID, GetEntriesParams{
+		TimeFrom: params.TimeFrom,
+		TimeTo:   params.TimeTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := w.getExportTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := w.GetContacts(GetContactsParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	contactNames := make(map[string]string)
+	for _, contact := range contacts {
+		contactNames[contact.Addr] = contact.Name
+	}
+
+	annotations, err := w.GetTxAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	dero := tokens[crypto.ZEROHASH.String()]
+	feesAdded := make(map[string]bool)
+
+	var txs []ExportTx
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		scId := entry.SCID.String()
+
+		token, ok := tokens[scId]
+		if !ok {
+			token = exportToken{symbol: scId}
+		}
+
+		tx := ExportTx{
+			Date:            entry.Time.UTC(),
+			TXID:            entry.TXID,
+			BlockHeight:     entry.Height,
+			SCID:            scId,
+			Symbol:          token.symbol,
+			Amount:          formatAtomicAmount(entry.Amount, token.decimals),
+			AtomicAmount:    entry.Amount,
+			Burn:            formatAtomicAmount(entry.Burn, token.decimals),
+			AtomicBurn:      entry.Burn,
+			Fee:             formatAtomicAmount(0, dero.decimals),
+			FeeSymbol:       dero.symbol,
+			DestinationPort: entry.DestinationPort,
+			SourcePort:      entry.SourcePort,
+			Tags:            []string{},
+
+			decimals: token.decimals,
+		}
+
+		switch {
+		case entry.Coinbase:
+			tx.Type = EXPORT_TX_COINBASE
+		case entry.Incoming:
+			tx.Type = EXPORT_TX_IN
+			tx.Counterparty = w.GetTxSender(entry)
+		default:
+			tx.Type = EXPORT_TX_OUT
+			tx.Counterparty = w.GetTxDestination(entry)
+		}
+
+		tx.CounterpartyName = contactNames[tx.Counterparty]
+
+		if entry.Payload_RPC.HasValue(rpc.RPC_COMMENT, rpc.DataString) {
+			tx.Comment = entry.Payload_RPC.Value(rpc.RPC_COMMENT, rpc.DataString).(string)
+		}
+
+		annotation, ok := annotations[TxAnnotationKey(entry.TXID, scId)]
+		if ok {
+			tx.Note = annotation.Note
+			tx.Category = annotation.Category
+			tx.Tags = annotation.GetTags()
+		}
+
+		// the fees are paid once in DERO for the whole tx even if it has multiple transfers
+		var feeTx *ExportTx
+		if tx.Type == EXPORT_TX_OUT && entry.Fees > 0 && !feesAdded[entry.TXID] {
+			feesAdded[entry.TXID] = true
+			fee := formatAtomicAmount(entry.Fees, dero.decimals)
+
+			if params.FeeRows {
+				feeTx = &ExportTx{
+					Date:             tx.Date,
+					Type:             EXPORT_TX_FEE,
+					TXID:             tx.TXID,
+					BlockHeight:      tx.BlockHeight,
+					SCID:             crypto.ZEROHASH.String(),
+					Symbol:           dero.symbol,
+					Amount:           fee,
+					AtomicAmount:     entry.Fees,
+					Burn:             formatAtomicAmount(0, dero.decimals),
+					Fee:              formatAtomicAmount(0, dero.decimals),
+					FeeSymbol:        dero.symbol,
+					Counterparty:     tx.Counterparty,
+					CounterpartyName: tx.CounterpartyName,
+					Note:             tx.Note,
+					Category:         tx.Category,
+					Tags:             tx.Tags,
+
+					decimals: dero.decimals,
+				}
+			} else {
+				tx.Fee = fee
+				tx.AtomicFee = entry.Fees
+			}
+		}
+
+		txs = append(txs, tx)
+		if feeTx != nil {
+			txs = append(txs, *feeTx)
+		}
+	}
+
+	return txs, nil
+}
+
+// WriteExportTxs writes the txs in the given format
+func WriteExportTxs(writer io.Writer, format ExportFormat, txs []ExportTx) error {
+	switch format {
+	case EXPORT_FORMAT_CSV:
+		return writeExportTxsCSV(writer, txs)
+	case EXPORT_FORMAT_KOINLY:
+		return writeExportTxsKoinly(writer, txs)
+	case EXPORT_FORMAT_JSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(txs)
+	}
+
+	return fmt.Errorf("unknown export format [%s]", format)
+}
+
+func writeExportTxsCSV(writer io.Writer, txs []ExportTx) error {
+	csvWriter := csv.NewWriter(writer)
+
+	header := []string{"Date", "Type", "TXID", "Block Height", "SCID", "Symbol",
+		"Amount", "Burn", "Fee", "Fee Symbol", "Counterparty", "Counterparty Name",
+		"Destination Port", "Source Port", "Comment", "Note", "Category", "Tags"}
+	err := csvWriter.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		row := []string{tx.Date.Format(time.RFC3339), tx.Type, tx.TXID, fmt.Sprint(tx.BlockHeight), tx.SCID, tx.Symbol,
+			tx.Amount, tx.Burn, tx.Fee, tx.FeeSymbol, tx.Counterparty, tx.CounterpartyName,
+			fmt.Sprint(tx.DestinationPort), fmt.Sprint(tx.SourcePort), tx.Comment, tx.Note, tx.Category, strings.Join(tx.Tags, ",")}
+		err = csvWriter.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// https://support.koinly.io/en/articles/9489976-how-to-create-a-custom-csv-file-with-your-data
+func writeExportTxsKoinly(writer io.Writer, txs []ExportTx) error {
+	csvWriter := csv.NewWriter(writer)
+
+	header := []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"}
+	err := csvWriter.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		var sentAmount, sentCurrency, receivedAmount, receivedCurrency, feeAmount, feeCurrency, label string
+
+		switch tx.Type {
+		case EXPORT_TX_IN:
+			receivedAmount = tx.Amount
+			receivedCurrency = tx.Symbol
+		case EXPORT_TX_COINBASE:
+			receivedAmount = tx.Amount
+			receivedCurrency = tx.Symbol
+			label = "mining"
+		case EXPORT_TX_OUT:
+			// burned tokens are leaving the wallet as well
+			sentAmount = formatAtomicAmount(tx.AtomicAmount+tx.AtomicBurn, tx.decimals)
+			sentCurrency = tx.Symbol
+			if tx.AtomicFee > 0 {
+				feeAmount = tx.Fee
+				feeCurrency = tx.FeeSymbol
+			}
+		case EXPORT_TX_FEE:
+			feeAmount = tx.Amount
+			feeCurrency = tx.Symbol
+			label = "cost"
+		}
+
+		description := tx.Note
+		if description == "" {
+			description = tx.Comment
+		}
+
+		row := []string{tx.Date.Format("2006-01-02 15:04:05 UTC"), sentAmount, sentCurrency, receivedAmount, receivedCurrency,
+			feeAmount, feeCurrency, "", "", label, description, tx.TXID}
+		err = csvWriter.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
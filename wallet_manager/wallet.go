@@ -1,6 +1,7 @@
 package wallet_manager
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
@@ -9,8 +10,10 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/creachadair/jrpc2"
 	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/globals"
 	"github.com/deroproject/derohe/rpc"
@@ -33,8 +36,19 @@ type Wallet struct {
 	Memory     *walletapi.Wallet_Disk
 	DB         *sql.DB
 	ServerXSWD *xswd.XSWD
+	ServerRPC  *RPCServer
 	FolderPath string
 	Settings   Settings
+
+	locked bool
+
+	transferLock sync.Mutex
+
+	entriesLock  sync.Mutex
+	entriesIndex map[crypto.Hash]entriesIndexState
+
+	entriesSyncErrLock sync.RWMutex
+	entriesSyncErr     error
 }
 
 var OpenedWallet *Wallet
@@ -47,6 +61,7 @@ func CloseOpenedWallet() {
 			wallet.Memory.Close_Encrypted_Wallet()
 		}()
 		wallet.CloseXSWD()
+		wallet.CloseRPCServer()
 		wallet.DB.Close()
 		OpenedWallet = nil
 	}
@@ -106,7 +121,40 @@ open_wallet:
 		return err
 	}
 
-	err = schema_version.Init(db)
+	err = initDataTables(db)
+	if err != nil {
+		return err
+	}
+
+	account := memory.GetAccount()
+	// fix: looks like EntriesNative is not instantiated on startup but only in InsertReplace func???
+	if account.EntriesNative == nil {
+		account.EntriesNative = make(map[crypto.Hash][]rpc.Entry)
+	}
+
+	wallet := &Wallet{
+		Info:       walletInfo,
+		Memory:     memory,
+		DB:         db,
+		FolderPath: folderPath,
+	}
+
+	err = wallet.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	// the entries are read from the db, index the ones stored in the wallet file before anything reads them
+	wallet.setEntriesSyncError(wallet.SyncEntries())
+
+	go wallet.sync_dero_loop()
+	OpenedWallet = wallet
+	return nil
+}
+
+// create or migrate all the tables of the wallet data.db
+func initDataTables(db *sql.DB) error {
+	err := schema_version.Init(db)
 	if err != nil {
 		return err
 	}
@@ -126,27 +174,47 @@ open_wallet:
 		return err
 	}
 
-	account := memory.GetAccount()
-	// fix: looks like EntriesNative is not instantiated on startup but only in InsertReplace func???
-	if account.EntriesNative == nil {
-		account.EntriesNative = make(map[crypto.Hash][]rpc.Entry)
+	err = initTableXSWDApps(db)
+	if err != nil {
+		return err
 	}
 
-	wallet := &Wallet{
-		Info:       walletInfo,
-		Memory:     memory,
-		DB:         db,
-		FolderPath: folderPath,
+	err = initTableXSWDLogs(db)
+	if err != nil {
+		return err
 	}
 
-	err = wallet.LoadSettings()
+	err = initTableEntries(db)
 	if err != nil {
 		return err
 	}
 
-	go wallet.sync_dero_loop()
-	OpenedWallet = wallet
-	return nil
+	err = initTableTxAnnotations(db)
+	if err != nil {
+		return err
+	}
+
+	return initTableXSWDPolicies(db)
+}
+
+// DataSchemaVersions returns the schema versions of data.db supported by this app.
+// It runs the migrations on an in-memory database and reads the resulting versions.
+func DataSchemaVersions() (map[string]int, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// keep a single connection or each one would get its own in-memory database
+	db.SetMaxOpenConns(1)
+
+	err = initDataTables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema_version.GetVersions(db)
 }
 
 func (w *Wallet) OpenXSWD(appHandler func(appData *xswd.ApplicationData) bool, reqHandler xswd.RequestHandlerFunc) error {
@@ -154,6 +222,11 @@ func (w *Wallet) OpenXSWD(appHandler func(appData *xswd.ApplicationData) bool, r
 	// XSWD is a secure communication protocol that offers easy interaction between the user wallet and a dApp
 	// it was create by Slixe
 
+	// apps can ask to sign data without the password prompt
+	if w.IsLocked() {
+		return ErrReadOnly
+	}
+
 	// check if not already in use from another software
 	addr := fmt.Sprintf(":%d", xswd.XSWD_PORT)
 	_, err := utils.IsTcpAddrInUse(addr)
@@ -161,7 +234,61 @@ func (w *Wallet) OpenXSWD(appHandler func(appData *xswd.ApplicationData) bool, r
 		return err
 	}
 
-	w.ServerXSWD = xswd.NewXSWDServer(w.Memory, appHandler, reqHandler)
+	// apps previously authorized are restored from the database and every dispatched request is recorded in the xswd logs.
+	// The stored permissions are never given to the xswd server or it would skip the request handler,
+	// so they are checked here on each request and a permission change applies right away.
+	storedAppHandler := func(appData *xswd.ApplicationData) bool {
+		// the id, name and url can be sent by anyone (the origin header is optional)
+		// so the app must also present the signature it was stored with
+		storedApp, err := w.GetXSWDApp(appData.Id)
+		if err == nil && storedApp != nil &&
+			storedApp.Name == appData.Name && storedApp.Url == appData.Url &&
+			len(storedApp.Signature) > 0 && bytes.Equal(storedApp.Signature, appData.Signature) {
+			return true
+		}
+
+		accepted := appHandler(appData)
+		if accepted {
+			// replaces the permissions of a previous app with the same id
+			w.StoreXSWDApp(*appData)
+		}
+
+		return accepted
+	}
+
+	storedReqHandler := func(appData *xswd.ApplicationData, req *jrpc2.Request) (xswd.Permission, interface{}, error) {
+		var result interface{}
+		permissions, err := w.getXSWDAppPermissions(appData.Id)
+		if err != nil {
+			return xswd.Deny, nil, err
+		}
+
+		perm, found := permissions[req.Method()]
+		if !found {
+			perm, result, err = reqHandler(appData, req)
+			if perm == xswd.AlwaysAllow || perm == xswd.AlwaysDeny {
+				w.StoreXSWDAppPermission(appData.Id, req.Method(), perm)
+			}
+		}
+
+		logErr := w.InsertXSWDLog(NewXSWDLog(appData, req, perm, result, err))
+		if logErr != nil && perm.IsPositive() && result == nil {
+			// don't run a request that can't be audited
+			return xswd.Deny, nil, logErr
+		}
+
+		// the xswd server keeps the "Always" permissions in memory and would not call this handler anymore
+		switch perm {
+		case xswd.AlwaysAllow:
+			perm = xswd.Allow
+		case xswd.AlwaysDeny:
+			perm = xswd.Deny
+		}
+
+		return perm, result, err
+	}
+
+	w.ServerXSWD = xswd.NewXSWDServer(w.Memory, storedAppHandler, storedReqHandler)
 	return nil
 }
 
@@ -226,16 +353,22 @@ func CreateWalletFromData(name string, password string, data []byte) error {
 	return createWallet(walletMemory, name)
 }
 
-func CreateWalletFromSeed(name string, password string, seed string) error {
+// accounts is the number of derived accounts to restore with the wallet
+func CreateWalletFromSeed(name string, password string, seed string, accounts int) error {
 	wallet, err := walletapi.Create_Encrypted_Wallet_From_Recovery_Words_Memory(password, seed)
 	if err != nil {
 		return err
 	}
 
-	return createWallet(wallet, name)
+	err = createWallet(wallet, name)
+	if err != nil {
+		return err
+	}
+
+	return restoreAccounts(wallet, name, password, accounts)
 }
 
-func CreateWalletFromHexSeed(name string, password, hexSeed string) error {
+func CreateWalletFromHexSeed(name string, password, hexSeed string, accounts int) error {
 	seed, err := hex.DecodeString(hexSeed)
 	if err != nil {
 		return err
@@ -251,7 +384,12 @@ func CreateWalletFromHexSeed(name string, password, hexSeed string) error {
 		return err
 	}
 
-	return createWallet(wallet, name)
+	err = createWallet(wallet, name)
+	if err != nil {
+		return err
+	}
+
+	return restoreAccounts(wallet, name, password, accounts)
 }
 
 func CreateRandomWallet(name string, password string) error {
@@ -275,6 +413,20 @@ func (w *Wallet) Rename(newName string) error {
 	return nil
 }
 
+// SetGroup moves the wallet at the end of the group (no group if groupId is not valid)
+func (w *Wallet) SetGroup(groupId sql.NullInt64) error {
+	walletInfo := w.Info
+	walletInfo.GroupId = groupId
+	walletInfo.OrderNumber = -1
+	err := app_db.UpdateWalletInfo(walletInfo)
+	if err != nil {
+		return err
+	}
+
+	w.Info, err = app_db.GetWalletInfo(walletInfo.Addr)
+	return err
+}
+
 func (w *Wallet) ResetBalanceResult(scId string) {
 	account := w.Memory.GetAccount()
 	hash := crypto.HashHexToHash(scId)
@@ -304,7 +456,17 @@ func (w *Wallet) ChangePassword(password string, newPassword string) error {
 		return err
 	}
 
-	return saveWalletData(newMemory)
+	err = saveWalletData(newMemory)
+	if err != nil {
+		return err
+	}
+
+	// the viewing PIN unlocks the old password and must be set again
+	if w.Info.ReadOnly {
+		return w.DisableReadOnly()
+	}
+
+	return nil
 }
 
 func StoreRegistrationTx(addr string, tx *transaction.Transaction) error {
@@ -475,15 +637,15 @@ func (w *Wallet) InsertDexTokensFolder() error {
 }
 
 func createWallet(wallet *walletapi.Wallet_Memory, name string) error {
+	return insertWallet(wallet, app_db.WalletInfo{Name: name})
+}
+
+func insertWallet(wallet *walletapi.Wallet_Memory, walletInfo app_db.WalletInfo) error {
 	wallet.SetNetwork(globals.IsMainnet())
 
-	addr := wallet.GetAddress().String()
-	walletInfo := app_db.WalletInfo{
-		Addr:        addr,
-		Name:        name,
-		Timestamp:   time.Now().Unix(),
-		OrderNumber: -1,
-	}
+	walletInfo.Addr = wallet.GetAddress().String()
+	walletInfo.Timestamp = time.Now().Unix()
+	walletInfo.OrderNumber = -1
 
 	err := app_db.InsertWalletInfo(walletInfo)
 	if err != nil {
@@ -609,7 +771,11 @@ func (w *Wallet) sync_dero_loop() {
 		case <-w.Memory.Quit:
 			return
 		default:
-			w.Memory.Sync_Wallet_Dero()
+			err := w.Memory.Sync_Wallet_Dero()
+			if err == nil {
+				w.setEntriesSyncError(w.SyncEntries())
+			}
+
 			time.Sleep(5 * time.Second)
 		}
 	}
@@ -0,0 +1,314 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/walletapi/xswd"
+)
+
+// XSWDApp is a dApp that was authorized by the user.
+// It is stored so the app and the "Always allow/deny" permissions persist after closing the wallet.
+type XSWDApp struct {
+	ID          string
+	Name        string
+	Description string
+	Url         string
+	Signature   []byte
+	Timestamp   int64
+	Permissions map[string]xswd.Permission
+}
+
+func (app XSWDApp) ApplicationData() xswd.ApplicationData {
+	return xswd.ApplicationData{
+		Id:          app.ID,
+		Name:        app.Name,
+		Description: app.Description,
+		Url:         app.Url,
+		Signature:   app.Signature,
+		Permissions: app.Permissions,
+	}
+}
+
+func initTableXSWDApps(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS xswd_apps (
+			id VARCHAR PRIMARY KEY,
+			name VARCHAR,
+			description VARCHAR,
+			url VARCHAR,
+			signature VARCHAR,
+			timestamp BIGINT
+		);
+
+		CREATE TABLE IF NOT EXISTS xswd_app_permissions (
+			app_id VARCHAR,
+			method VARCHAR,
+			permission INTEGER,
+			PRIMARY KEY (app_id, method)
+		);
+	`)
+	return err
+}
+
+func (w *Wallet) getXSWDAppPermissions(appId string) (map[string]xswd.Permission, error) {
+	query := sq.Select("method", "permission").From("xswd_app_permissions").Where(sq.Eq{"app_id": appId})
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make(map[string]xswd.Permission)
+	for rows.Next() {
+		var method string
+		var perm xswd.Permission
+		err = rows.Scan(&method, &perm)
+		if err != nil {
+			return nil, err
+		}
+
+		permissions[method] = perm
+	}
+
+	return permissions, rows.Err()
+}
+
+func (w *Wallet) GetXSWDApps() ([]XSWDApp, error) {
+	query := sq.Select("*").From("xswd_apps").OrderBy("timestamp ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := rowsScanXSWDApps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range apps {
+		apps[i].Permissions, err = w.getXSWDAppPermissions(apps[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return apps, nil
+}
+
+func (w *Wallet) GetXSWDApp(id string) (*XSWDApp, error) {
+	query := sq.Select("*").From("xswd_apps").Where(sq.Eq{"id": id})
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := rowsScanXSWDApps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(apps) == 0 {
+		return nil, nil
+	}
+
+	app := apps[0]
+	app.Permissions, err = w.getXSWDAppPermissions(app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app, nil
+}
+
+// Match returns true if the connected app is the one that was stored (same url and signature).
+func (app XSWDApp) Match(appData xswd.ApplicationData) bool {
+	return app.Url == appData.Url && bytes.Equal(app.Signature, appData.Signature)
+}
+
+func rowsScanXSWDApps(rows *sql.Rows) ([]XSWDApp, error) {
+	defer rows.Close()
+
+	var apps []XSWDApp
+	for rows.Next() {
+		var app XSWDApp
+		var signature string
+		err := rows.Scan(
+			&app.ID,
+			&app.Name,
+			&app.Description,
+			&app.Url,
+			&signature,
+			&app.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		app.Signature, err = hex.DecodeString(signature)
+		if err != nil {
+			return nil, err
+		}
+
+		apps = append(apps, app)
+	}
+
+	return apps, rows.Err()
+}
+
+// StoreXSWDApp stores the app and replaces its permissions with the ones of the app data.
+// The spending policies are deleted if the url or the signature changed, another site can reuse a known app id.
+func (w *Wallet) StoreXSWDApp(app xswd.ApplicationData) error {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var storedUrl, storedSignature string
+	err = tx.QueryRow(`
+		SELECT url, signature FROM xswd_apps
+		WHERE id = ?;
+	`, app.Id).Scan(&storedUrl, &storedSignature)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	if err == nil && (storedUrl != app.Url || storedSignature != hex.EncodeToString(app.Signature)) {
+		err = delXSWDAppPolicies(tx, app.Id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO xswd_apps (id,name,description,url,signature,timestamp)
+		VALUES (?,?,?,?,?,?)
+		ON CONFLICT (id) DO UPDATE SET
+		name = excluded.name,
+		description = excluded.description,
+		url = excluded.url,
+		signature = excluded.signature;
+	`, app.Id, app.Name, app.Description, app.Url, hex.EncodeToString(app.Signature), time.Now().Unix())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_app_permissions
+		WHERE app_id = ?;
+	`, app.Id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for method, perm := range app.Permissions {
+		err = storeXSWDAppPermission(tx, app.Id, method, perm)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func storeXSWDAppPermission(tx *sql.Tx, appId string, method string, perm xswd.Permission) error {
+	// Allow and Deny are only valid for a single request, Ask means no stored permission
+	if perm != xswd.AlwaysAllow && perm != xswd.AlwaysDeny {
+		_, err := tx.Exec(`
+			DELETE FROM xswd_app_permissions
+			WHERE app_id = ? AND method = ?;
+		`, appId, method)
+		return err
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO xswd_app_permissions (app_id,method,permission)
+		VALUES (?,?,?)
+		ON CONFLICT (app_id, method) DO UPDATE SET
+		permission = excluded.permission;
+	`, appId, method, perm)
+	return err
+}
+
+func (w *Wallet) StoreXSWDAppPermission(appId string, method string, perm xswd.Permission) error {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = storeXSWDAppPermission(tx, appId, method, perm)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (w *Wallet) DelXSWDApp(id string) error {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_app_permissions
+		WHERE app_id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = delXSWDAppPolicies(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_apps
+		WHERE id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateXSWDAppPermission stores the permission. It applies right away to a connected app
+// because the stored permissions are read on every request (see OpenXSWD).
+func (w *Wallet) UpdateXSWDAppPermission(appId string, method string, perm xswd.Permission) error {
+	return w.StoreXSWDAppPermission(appId, method, perm)
+}
+
+// RevokeXSWDApp deletes the stored app and terminates its connection.
+func (w *Wallet) RevokeXSWDApp(id string) error {
+	err := w.DelXSWDApp(id)
+	if err != nil {
+		return err
+	}
+
+	if w.ServerXSWD != nil && w.ServerXSWD.IsRunning() {
+		for _, app := range w.ServerXSWD.GetApplications() {
+			if app.Id == id {
+				w.ServerXSWD.RemoveApplication(&app)
+			}
+		}
+	}
+
+	return nil
+}
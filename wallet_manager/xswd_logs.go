@@ -0,0 +1,166 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/creachadair/jrpc2"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi/xswd"
+)
+
+// max length of the params stored in the log
+const XSWD_LOG_PARAMS_MAX_LEN = 500
+
+type XSWDLog struct {
+	ID         int64
+	AppId      string
+	AppName    string
+	AppUrl     string
+	Method     string
+	Params     string
+	Permission xswd.Permission
+	TxId       sql.NullString
+	Error      sql.NullString
+	Timestamp  int64
+}
+
+func initTableXSWDLogs(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS xswd_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			app_id VARCHAR,
+			app_name VARCHAR,
+			app_url VARCHAR,
+			method VARCHAR,
+			params VARCHAR,
+			permission INTEGER,
+			tx_id VARCHAR,
+			error VARCHAR,
+			timestamp BIGINT
+		);
+	`)
+	return err
+}
+
+func NewXSWDLog(appData *xswd.ApplicationData, req *jrpc2.Request, perm xswd.Permission, result interface{}, err error) XSWDLog {
+	params := req.ParamString()
+	if len(params) > XSWD_LOG_PARAMS_MAX_LEN {
+		params = params[:XSWD_LOG_PARAMS_MAX_LEN] + "..."
+	}
+
+	log := XSWDLog{
+		AppId:      appData.Id,
+		AppName:    appData.Name,
+		AppUrl:     appData.Url,
+		Method:     req.Method(),
+		Params:     params,
+		Permission: perm,
+		Timestamp:  time.Now().Unix(),
+	}
+
+	if err != nil {
+		log.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+
+	// transfer and scinvoke are handled by the build tx modal and return the txid
+	transferResult, ok := result.(rpc.Transfer_Result)
+	if ok && transferResult.TXID != "" {
+		log.TxId = sql.NullString{String: transferResult.TXID, Valid: true}
+	}
+
+	return log
+}
+
+func (w *Wallet) InsertXSWDLog(log XSWDLog) error {
+	_, err := w.DB.Exec(`
+		INSERT INTO xswd_logs (app_id,app_name,app_url,method,params,permission,tx_id,error,timestamp)
+		VALUES (?,?,?,?,?,?,?,?,?);
+	`, log.AppId, log.AppName, log.AppUrl, log.Method, log.Params, log.Permission, log.TxId, log.Error, log.Timestamp)
+	return err
+}
+
+type GetXSWDLogsParams struct {
+	AppUrl sql.NullString
+	Method sql.NullString
+	Limit  uint64
+}
+
+func (w *Wallet) GetXSWDLogs(params GetXSWDLogsParams) ([]XSWDLog, error) {
+	query := sq.Select("*").From("xswd_logs").OrderBy("timestamp DESC", "id DESC")
+
+	if params.AppUrl.Valid {
+		query = query.Where(sq.Eq{"app_url": params.AppUrl.String})
+	}
+
+	if params.Method.Valid {
+		query = query.Where(sq.Eq{"method": params.Method.String})
+	}
+
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []XSWDLog
+	for rows.Next() {
+		var log XSWDLog
+		err = rows.Scan(
+			&log.ID,
+			&log.AppId,
+			&log.AppName,
+			&log.AppUrl,
+			&log.Method,
+			&log.Params,
+			&log.Permission,
+			&log.TxId,
+			&log.Error,
+			&log.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
+// GetXSWDLogValues returns the distinct values of a column (used for filters).
+func (w *Wallet) GetXSWDLogValues(column string) ([]string, error) {
+	query := sq.Select(column).Distinct().From("xswd_logs").OrderBy(fmt.Sprintf("%s ASC", column))
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+func (w *Wallet) ClearXSWDLogs() error {
+	_, err := w.DB.Exec(`
+		DELETE FROM xswd_logs;
+	`)
+	return err
+}
@@ -0,0 +1,439 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi/xswd"
+	"github.com/g45t345rt/g45w/settings"
+)
+
+// spendings older than this window are not counted in the daily limits
+const XSWD_POLICY_WINDOW = 24 * time.Hour
+
+// XSWDPolicy lets an app send transfers without a confirmation prompt.
+// An empty SCID or Entrypoint matches any value. Anything above the
+// daily limits falls back to the regular build tx prompt.
+type XSWDPolicy struct {
+	ID          int64
+	AppId       string
+	SCID        string
+	Entrypoint  string
+	DeroLimit   uint64            // atomic units per 24 hours
+	TokenLimits map[string]uint64 // atomic units per 24 hours by token scid, a token without a limit is never auto approved
+	Timestamp   int64
+}
+
+// make sure two requests can't spend over the limits at the same time
+var xswdPolicyLock sync.Mutex
+
+func initTableXSWDPolicies(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS xswd_policies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			app_id VARCHAR,
+			scid VARCHAR,
+			entrypoint VARCHAR,
+			dero_limit BIGINT,
+			timestamp BIGINT
+		);
+
+		CREATE TABLE IF NOT EXISTS xswd_policy_token_limits (
+			policy_id INTEGER,
+			scid VARCHAR,
+			token_limit BIGINT,
+			PRIMARY KEY (policy_id, scid)
+		);
+
+		CREATE TABLE IF NOT EXISTS xswd_policy_spendings (
+			policy_id INTEGER,
+			scid VARCHAR,
+			amount BIGINT,
+			tx_id VARCHAR,
+			timestamp BIGINT
+		);
+	`)
+	return err
+}
+
+func (w *Wallet) GetXSWDPolicies(appId string) ([]XSWDPolicy, error) {
+	query := sq.Select("*").From("xswd_policies").
+		Where(sq.Eq{"app_id": appId}).
+		OrderBy("timestamp ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []XSWDPolicy
+	for rows.Next() {
+		var policy XSWDPolicy
+		err = rows.Scan(
+			&policy.ID,
+			&policy.AppId,
+			&policy.SCID,
+			&policy.Entrypoint,
+			&policy.DeroLimit,
+			&policy.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range policies {
+		policies[i].TokenLimits, err = w.getXSWDPolicyTokenLimits(policies[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return policies, nil
+}
+
+func (w *Wallet) getXSWDPolicyTokenLimits(policyId int64) (map[string]uint64, error) {
+	query := sq.Select("scid", "token_limit").From("xswd_policy_token_limits").Where(sq.Eq{"policy_id": policyId})
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokenLimits := make(map[string]uint64)
+	for rows.Next() {
+		var scId string
+		var limit uint64
+		err = rows.Scan(&scId, &limit)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenLimits[scId] = limit
+	}
+
+	return tokenLimits, rows.Err()
+}
+
+func (w *Wallet) InsertXSWDPolicy(policy XSWDPolicy) error {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO xswd_policies (app_id,scid,entrypoint,dero_limit,timestamp)
+		VALUES (?,?,?,?,?);
+	`, policy.AppId, policy.SCID, policy.Entrypoint, policy.DeroLimit, time.Now().Unix())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	policyId, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for scId, limit := range policy.TokenLimits {
+		_, err = tx.Exec(`
+			INSERT INTO xswd_policy_token_limits (policy_id,scid,token_limit)
+			VALUES (?,?,?);
+		`, policyId, scId, limit)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (w *Wallet) DelXSWDPolicy(id int64) error {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_policy_spendings
+		WHERE policy_id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_policy_token_limits
+		WHERE policy_id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_policies
+		WHERE id = ?;
+	`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func delXSWDAppPolicies(tx *sql.Tx, appId string) error {
+	_, err := tx.Exec(`
+		DELETE FROM xswd_policy_spendings
+		WHERE policy_id IN (SELECT id FROM xswd_policies WHERE app_id = ?);
+	`, appId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_policy_token_limits
+		WHERE policy_id IN (SELECT id FROM xswd_policies WHERE app_id = ?);
+	`, appId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_policies
+		WHERE app_id = ?;
+	`, appId)
+	return err
+}
+
+// GetXSWDPolicySpent returns the amount of the asset (zero hash for DERO) spent with the policy in the last 24 hours.
+func (w *Wallet) GetXSWDPolicySpent(policyId int64, scId crypto.Hash) (uint64, error) {
+	since := time.Now().Add(-XSWD_POLICY_WINDOW).Unix()
+	query := sq.Select("COALESCE(SUM(amount), 0)").From("xswd_policy_spendings").
+		Where(sq.Eq{"policy_id": policyId, "scid": scId.String()}).
+		Where(sq.GtOrEq{"timestamp": since})
+
+	row := query.RunWith(w.DB).QueryRow()
+
+	var spent uint64
+	err := row.Scan(&spent)
+	return spent, err
+}
+
+// reserveXSWDPolicySpendings records the amounts before the tx is sent so a concurrent or retried request can't spend them again.
+// It returns the rowids of the reserved spendings to set the txid or delete them if the tx is not sent.
+func (w *Wallet) reserveXSWDPolicySpendings(policyId int64, deroAmount uint64, tokensAmount map[crypto.Hash]uint64) ([]int64, error) {
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[crypto.Hash]uint64)
+	for scId, amount := range tokensAmount {
+		amounts[scId] = amount
+	}
+	amounts[crypto.ZEROHASH] = deroAmount
+
+	var rowIds []int64
+	timestamp := time.Now().Unix()
+	for scId, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO xswd_policy_spendings (policy_id,scid,amount,tx_id,timestamp)
+			VALUES (?,?,?,?,?);
+		`, policyId, scId.String(), amount, "", timestamp)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowId, err := result.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowIds = append(rowIds, rowId)
+	}
+
+	return rowIds, tx.Commit()
+}
+
+func (w *Wallet) setXSWDPolicySpendingsTxId(rowIds []int64, txId string) error {
+	query := sq.Update("xswd_policy_spendings").Set("tx_id", txId).Where(sq.Eq{"rowid": rowIds})
+	_, err := query.RunWith(w.DB).Exec()
+	return err
+}
+
+func (w *Wallet) delXSWDPolicySpendings(rowIds []int64) error {
+	query := sq.Delete("xswd_policy_spendings").Where(sq.Eq{"rowid": rowIds})
+	_, err := query.RunWith(w.DB).Exec()
+	return err
+}
+
+func transferEntrypoint(p rpc.Transfer_Params) string {
+	for _, arg := range p.SC_RPC {
+		if strings.EqualFold(arg.Name, "entrypoint") {
+			return fmt.Sprint(arg.Value)
+		}
+	}
+
+	return ""
+}
+
+func (policy XSWDPolicy) match(p rpc.Transfer_Params) bool {
+	if policy.SCID != "" && !strings.EqualFold(policy.SCID, p.SC_ID) {
+		return false
+	}
+
+	if policy.Entrypoint != "" && policy.Entrypoint != transferEntrypoint(p) {
+		return false
+	}
+
+	return true
+}
+
+func (w *Wallet) withinXSWDPolicyLimits(policy XSWDPolicy, deroAmount uint64, tokensAmount map[crypto.Hash]uint64) (bool, error) {
+	spent, err := w.GetXSWDPolicySpent(policy.ID, crypto.ZEROHASH)
+	if err != nil {
+		return false, err
+	}
+
+	if spent+deroAmount > policy.DeroLimit {
+		return false, nil
+	}
+
+	for scId, amount := range tokensAmount {
+		// the decimals differ by token so each token needs its own limit
+		limit, ok := policy.TokenLimits[scId.String()]
+		if !ok {
+			return false, nil
+		}
+
+		spent, err := w.GetXSWDPolicySpent(policy.ID, scId)
+		if err != nil {
+			return false, err
+		}
+
+		if spent+amount > limit {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SendXSWDPolicyTransfer builds and sends the transfer without prompting the user if one of the app policies covers it.
+// The amounts are the totals of the transfer (burn included), the fees are added to the DERO amount and everything is reserved against the policy before sending.
+// If no policy applies, ok is false and the transfer must be confirmed by the user.
+func (w *Wallet) SendXSWDPolicyTransfer(appData xswd.ApplicationData, p rpc.Transfer_Params, deroAmount uint64, tokensAmount map[crypto.Hash]uint64) (ok bool, result rpc.Transfer_Result, err error) {
+	// never auto approve the installation of a smart contract
+	if p.SC_Code != "" && p.SC_ID == "" {
+		return
+	}
+
+	// the transfer must be confirmed with the full password
+	if w.IsLocked() {
+		return
+	}
+
+	xswdPolicyLock.Lock()
+	defer xswdPolicyLock.Unlock()
+
+	// the policies belong to the stored app, a site reusing its id doesn't get them
+	app, err := w.GetXSWDApp(appData.Id)
+	if err != nil || app == nil || !app.Match(appData) {
+		return
+	}
+
+	policies, err := w.GetXSWDPolicies(app.ID)
+	if err != nil {
+		return
+	}
+
+	var matchingPolicies []XSWDPolicy
+	for _, policy := range policies {
+		if policy.match(p) {
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+	}
+
+	if len(matchingPolicies) == 0 {
+		return
+	}
+
+	p, err = FormatTransfer(p)
+	if err != nil {
+		return
+	}
+
+	if p.Ringsize == 0 {
+		p.Ringsize = uint64(settings.App.SendRingSize)
+	}
+
+	// the fees are paid in DERO and count against the limit
+	txFees, gasFees, err := w.EstimateFees(&p)
+	if err != nil {
+		return
+	}
+
+	deroAmount += txFees + gasFees
+
+	var policy *XSWDPolicy
+	for i := range matchingPolicies {
+		within, err := w.withinXSWDPolicyLimits(matchingPolicies[i], deroAmount, tokensAmount)
+		if err != nil {
+			return false, result, err
+		}
+
+		if within {
+			policy = &matchingPolicies[i]
+			break
+		}
+	}
+
+	if policy == nil {
+		return
+	}
+
+	rowIds, err := w.reserveXSWDPolicySpendings(policy.ID, deroAmount, tokensAmount)
+	if err != nil {
+		return
+	}
+
+	ok = true
+	tx, err := w.BuildAndSendTx(p, txFees, gasFees, "")
+	if tx == nil {
+		// nothing was sent, release the reserved amounts
+		w.delXSWDPolicySpendings(rowIds)
+		return
+	}
+
+	// the tx is broadcasted and the amounts are already counted, never report it as failed or the app could retry
+	err = nil
+	result.TXID = tx.GetHash().String()
+	w.setXSWDPolicySpendingsTxId(rowIds, result.TXID)
+	return
+}